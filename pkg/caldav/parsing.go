@@ -70,6 +70,20 @@ func GetCaldavTodosForTasks(project *models.ProjectWithTasksAndBuckets, projectT
 			RepeatMode:  t.RepeatMode,
 			Alarms:      alarms,
 		})
+
+		// Checklist items are exported as subtasks of their task
+		for _, item := range t.ChecklistItems {
+			caldavtodos = append(caldavtodos, &Todo{
+				Timestamp:    item.Updated,
+				UID:          getChecklistItemUID(t.UID, item.ID),
+				Summary:      item.Title,
+				Completed:    item.DoneAt,
+				RelatedToUID: t.UID,
+				DueDate:      item.DueDate,
+				Created:      item.Created,
+				Updated:      item.Updated,
+			})
+		}
 	}
 
 	caldavConfig := &Config{
@@ -80,6 +94,23 @@ func GetCaldavTodosForTasks(project *models.ProjectWithTasksAndBuckets, projectT
 	return ParseTodos(caldavConfig, caldavtodos)
 }
 
+const checklistItemUIDInfix = "-checklist-"
+
+func getChecklistItemUID(taskUID string, itemID int64) string {
+	return taskUID + checklistItemUIDInfix + strconv.FormatInt(itemID, 10)
+}
+
+// IsChecklistItemUID checks if a uid belongs to an exported checklist item instead of a task.
+// Checklist items are read-only via CalDAV.
+func IsChecklistItemUID(uid string) bool {
+	_, itemID, found := strings.Cut(uid, checklistItemUIDInfix)
+	if !found {
+		return false
+	}
+	_, err := strconv.ParseInt(itemID, 10, 64)
+	return err == nil
+}
+
 func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 	parsed, err := ics.ParseCalendar(strings.NewReader(content))
 	if err != nil {
@@ -392,6 +392,76 @@ ACTION:DISPLAY
 DESCRIPTION:Task 1
 END:VALARM
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "Format Task with checklist as CalDAV",
+			args: args{
+				list: &models.ProjectWithTasksAndBuckets{
+					Project: models.Project{
+						Title: "List title",
+					},
+				},
+				tasks: []*models.TaskWithComments{
+					{
+						Task: models.Task{
+							Title:   "Task 1",
+							UID:     "randomuid",
+							Created: time.Unix(1543626721, 0).In(config.GetTimeZone()),
+							Updated: time.Unix(1543626725, 0).In(config.GetTimeZone()),
+							ChecklistItems: []*models.TaskChecklistItem{
+								{
+									ID:      1,
+									Title:   "Item 1",
+									Done:    true,
+									DoneAt:  time.Unix(1543626726, 0).In(config.GetTimeZone()),
+									Created: time.Unix(1543626722, 0).In(config.GetTimeZone()),
+									Updated: time.Unix(1543626726, 0).In(config.GetTimeZone()),
+								},
+								{
+									ID:      2,
+									Title:   "Item 2",
+									DueDate: time.Unix(1543626730, 0).In(config.GetTimeZone()),
+									Created: time.Unix(1543626723, 0).In(config.GetTimeZone()),
+									Updated: time.Unix(1543626724, 0).In(config.GetTimeZone()),
+								},
+							},
+						},
+					},
+				},
+			},
+			wantCaldav: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:List title
+PRODID:-//Vikunja Todo App//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011205Z
+SUMMARY:Task 1
+CREATED:20181201T011201Z
+LAST-MODIFIED:20181201T011205Z
+END:VTODO
+BEGIN:VTODO
+UID:randomuid-checklist-1
+DTSTAMP:20181201T011206Z
+SUMMARY:Item 1
+COMPLETED:20181201T011206Z
+STATUS:COMPLETED
+RELATED-TO:randomuid
+CREATED:20181201T011202Z
+LAST-MODIFIED:20181201T011206Z
+END:VTODO
+BEGIN:VTODO
+UID:randomuid-checklist-2
+DTSTAMP:20181201T011204Z
+SUMMARY:Item 2
+RELATED-TO:randomuid
+DUE:20181201T011210Z
+CREATED:20181201T011203Z
+LAST-MODIFIED:20181201T011204Z
+END:VTODO
 END:VCALENDAR`,
 		},
 	}
@@ -404,3 +474,17 @@ END:VCALENDAR`,
 		})
 	}
 }
+
+func TestIsChecklistItemUID(t *testing.T) {
+	tests := map[string]bool{
+		"randomuid-checklist-1":   true,
+		"randomuid":               false,
+		"randomuid-checklist-":    false,
+		"randomuid-checklist-abc": false,
+	}
+	for uid, want := range tests {
+		if got := IsChecklistItemUID(uid); got != want {
+			t.Errorf("IsChecklistItemUID(%q) = %v, want %v", uid, got, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/initialize"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/mail"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mailFlagStatus string
+	mailFlagLimit  int
+)
+
+func init() {
+	mailListCmd.Flags().StringVarP(&mailFlagStatus, "status", "s", "", "Only show mails with this status. Can be pending, sending, sent or failed.")
+	mailListCmd.Flags().IntVarP(&mailFlagLimit, "limit", "l", 50, "How many mails to show.")
+	mailCmd.AddCommand(mailListCmd, mailRetryCmd)
+	rootCmd.AddCommand(mailCmd)
+}
+
+var mailCmd = &cobra.Command{
+	Use:   "mail",
+	Short: "Inspect the outgoing mail queue and delivery log.",
+}
+
+var mailListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Shows the newest mails in the queue and delivery log.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInitWithoutAsync()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var status *mail.QueuedMailStatus
+		if mailFlagStatus != "" {
+			parsed, err := mail.ParseQueuedMailStatus(mailFlagStatus)
+			if err != nil {
+				log.Fatalf("Invalid status: %s", err)
+			}
+			status = &parsed
+		}
+
+		s := db.NewSession()
+		defer s.Close()
+
+		mails, err := mail.ListQueuedMails(s, status, mailFlagLimit)
+		if err != nil {
+			log.Fatalf("Error getting mails: %s", err)
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{
+			"ID",
+			"To",
+			"Subject",
+			"Status",
+			"Attempts",
+			"Last error",
+			"Next attempt",
+			"Created",
+		})
+
+		for _, m := range mails {
+			nextAttempt := ""
+			if m.Status == mail.QueuedMailStatusPending {
+				nextAttempt = m.NextAttempt.Format(time.RFC3339)
+			}
+			table.Append([]string{
+				strconv.FormatInt(m.ID, 10),
+				m.To,
+				m.Subject,
+				m.Status.String(),
+				strconv.Itoa(m.Attempts),
+				m.LastError,
+				nextAttempt,
+				m.Created.Format(time.RFC3339),
+			})
+		}
+
+		table.Render()
+	},
+}
+
+var mailRetryCmd = &cobra.Command{
+	Use:   "retry [mail id]",
+	Short: "Queues a failed mail for delivery again.",
+	Args:  cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInitWithoutAsync()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid mail id: %s", err)
+		}
+
+		s := db.NewSession()
+		defer s.Close()
+
+		err = mail.RetryQueuedMail(s, id)
+		if err != nil {
+			_ = s.Rollback()
+			log.Fatalf("Could not retry mail: %s", err)
+		}
+
+		if err := s.Commit(); err != nil {
+			log.Fatalf("Could not retry mail: %s", err)
+		}
+
+		log.Infof("Mail %d will be sent again.", id)
+	},
+}
@@ -0,0 +1,50 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	pushCmd.AddCommand(pushGenerateVAPIDKeysCmd)
+	rootCmd.AddCommand(pushCmd)
+}
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Manage push notifications",
+}
+
+var pushGenerateVAPIDKeysCmd = &cobra.Command{
+	Use:   "generate-vapid-keys",
+	Short: "Generate a key pair to use for web push notifications",
+	Run: func(cmd *cobra.Command, args []string) {
+		publicKey, privateKey, err := notifications.GenerateVAPIDKeys()
+		if err != nil {
+			log.Fatalf("Could not generate keys: %s", err)
+		}
+
+		fmt.Println("Add these keys to your config under push.webpush:")
+		fmt.Printf("publickey: \"%s\"\n", publicKey)
+		fmt.Printf("privatekey: \"%s\"\n", privateKey)
+	},
+}
@@ -17,47 +17,160 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"sort"
+
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/mail"
+	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
 	"github.com/spf13/cobra"
 )
 
+var (
+	testmailFlagNotification string
+	testmailFlagLanguage     string
+	testmailFlagOutput       string
+	testmailFlagList         bool
+)
+
 func init() {
+	testmailCmd.Flags().StringVarP(&testmailFlagNotification, "notification", "n", "", "Send a preview of a notification instead of the generic test mail. Use \"all\" to send a preview of every notification type.")
+	testmailCmd.Flags().StringVarP(&testmailFlagLanguage, "language", "l", i18n.DefaultLanguage, "The language to render the mails in.")
+	testmailCmd.Flags().StringVarP(&testmailFlagOutput, "output", "o", "", "Write the rendered mails as .html and .txt files to this directory instead of sending them.")
+	testmailCmd.Flags().BoolVar(&testmailFlagList, "list", false, "List all notification types which can be previewed.")
 	rootCmd.AddCommand(testmailCmd)
 }
 
+func getTestmailPreviews() map[string]notifications.Notification {
+	previews := models.GetNotificationPreviews()
+	for name, n := range user.GetNotificationPreviews() {
+		previews[name] = n
+	}
+	return previews
+}
+
+func getTestmailMails() (mails map[string]*notifications.Mail) {
+	mails = make(map[string]*notifications.Mail)
+
+	if testmailFlagNotification == "" {
+		mails["testmail"] = notifications.NewMail().
+			Subject("Test from Vikunja").
+			Line("This is a test mail!").
+			Line("If you received this, Vikunja is correctly set up to send emails.").
+			Action("Go to your instance", config.ServiceFrontendurl.GetString())
+		return
+	}
+
+	previews := getTestmailPreviews()
+	if testmailFlagNotification != "all" {
+		n, exists := previews[testmailFlagNotification]
+		if !exists {
+			log.Fatalf("Notification %s does not exist. Use --list to see all available notifications.", testmailFlagNotification)
+		}
+		previews = map[string]notifications.Notification{testmailFlagNotification: n}
+	}
+
+	for name, n := range previews {
+		m := n.ToMail(testmailFlagLanguage)
+		if m == nil {
+			continue
+		}
+		mails[name] = m.Language(testmailFlagLanguage)
+	}
+
+	return
+}
+
 var testmailCmd = &cobra.Command{
 	Use:   "testmail [email]",
 	Short: "Send a test mail using the configured smtp connection",
-	Args:  cobra.ExactArgs(1),
+	Long: `Send a test mail using the configured smtp connection.
+
+With --notification, a preview of one or all notification mails is rendered with sample data using the configured
+mail templates. With --output, the rendered mails are written to a directory instead of being sent.`,
+	Args: cobra.RangeArgs(0, 1),
 	PreRun: func(cmd *cobra.Command, args []string) {
 		initialize.LightInit()
 
-		// Start the mail daemon
-		mail.StartMailDaemon()
+		if err := notifications.InitMailTemplates(); err != nil {
+			log.Fatalf("Invalid mail templates: %s", err)
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		log.Info("Sending testmail...")
-		message := notifications.NewMail().
-			From("Vikunja <"+config.MailerFromEmail.GetString()+">").
-			To(args[0]).
-			Subject("Test from Vikunja").
-			Line("This is a test mail!").
-			Line("If you received this, Vikunja is correctly set up to send emails.").
-			Action("Go to your instance", config.ServiceFrontendurl.GetString())
-
-		opts, err := notifications.RenderMail(message)
-		if err != nil {
-			log.Errorf("Error sending test mail: %s", err.Error())
+		if testmailFlagList {
+			names := make([]string, 0)
+			for name := range getTestmailPreviews() {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				log.Info(name)
+			}
 			return
 		}
-		if err := mail.SendTestMail(opts); err != nil {
-			log.Errorf("Error sending test mail: %s", err.Error())
-			return
+
+		if testmailFlagOutput == "" && len(args) == 0 {
+			log.Fatalf("Please provide an email address to send the test mail to or use --output to write it to a directory.")
+		}
+
+		lang := i18n.ResolveLanguage(testmailFlagLanguage)
+		if lang == "" {
+			log.Fatalf("Language %s is not available.", testmailFlagLanguage)
+		}
+		testmailFlagLanguage = lang
+
+		mails := getTestmailMails()
+		names := make([]string, 0, len(mails))
+		for name := range mails {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if testmailFlagOutput != "" {
+			if err := os.MkdirAll(testmailFlagOutput, 0o755); err != nil {
+				log.Fatalf("Could not create output directory: %s", err)
+			}
+		}
+
+		for _, name := range names {
+			m := mails[name].From("Vikunja <" + config.MailerFromEmail.GetString() + ">")
+			if len(args) > 0 {
+				m.To(args[0])
+			}
+
+			opts, err := notifications.RenderMail(m)
+			if err != nil {
+				log.Errorf("Error rendering mail %s: %s", name, err.Error())
+				return
+			}
+
+			if testmailFlagOutput != "" {
+				base := filepath.Join(testmailFlagOutput, name)
+				if err := os.WriteFile(base+".html", []byte(opts.HTMLMessage), 0o644); err != nil {
+					log.Fatalf("Could not write %s.html: %s", name, err)
+				}
+				if err := os.WriteFile(base+".txt", []byte(opts.Message), 0o644); err != nil {
+					log.Fatalf("Could not write %s.txt: %s", name, err)
+				}
+				log.Infof("Wrote %s.html and %s.txt", base, base)
+				continue
+			}
+
+			log.Infof("Sending %s...", name)
+			if err := mail.SendTestMail(opts); err != nil {
+				log.Errorf("Error sending test mail: %s", err.Error())
+				return
+			}
+		}
+
+		if testmailFlagOutput == "" {
+			log.Info("Testmail successfully sent.")
 		}
-		log.Info("Testmail successfully sent.")
 	},
 }
@@ -54,6 +54,7 @@ const (
 	ServiceEnableMetrics         Key = `service.enablemetrics`
 	ServiceMotd                  Key = `service.motd`
 	ServiceEnableLinkSharing     Key = `service.enablelinksharing`
+	ServiceEnablePublicProjects  Key = `service.enablepublicprojects`
 	ServiceEnableRegistration    Key = `service.enableregistration`
 	ServiceEnableTaskAttachments Key = `service.enabletaskattachments`
 	ServiceTimeZone              Key = `service.timezone`
@@ -64,6 +65,7 @@ const (
 	ServiceEnableEmailReminders  Key = `service.enableemailreminders`
 	ServiceEnableUserDeletion    Key = `service.enableuserdeletion`
 	ServiceMaxAvatarSize         Key = `service.maxavatarsize`
+	ServiceTrashRetention        Key = `service.trashretention`
 
 	AuthLocalEnabled      Key = `auth.local.enabled`
 	AuthOpenIDEnabled     Key = `auth.openid.enabled`
@@ -103,12 +105,44 @@ const (
 	MailerQueuelength   Key = `mailer.queuelength`
 	MailerQueueTimeout  Key = `mailer.queuetimeout`
 	MailerForceSSL      Key = `mailer.forcessl`
+	MailerTransport     Key = `mailer.transport`
+	MailerSendmailPath  Key = `mailer.sendmailpath`
+	MailerMaildir       Key = `mailer.maildir`
+	MailerMaxAttempts   Key = `mailer.maxattempts`
+	MailerRetryInterval Key = `mailer.retryinterval`
+	MailerLogRetention  Key = `mailer.logretention`
+
+	MailerTemplatesDir            Key = `mailer.templatesdir`
+	MailerBrandingPrimaryColor    Key = `mailer.branding.primarycolor`
+	MailerBrandingBackgroundColor Key = `mailer.branding.backgroundcolor`
+	MailerBrandingFooter          Key = `mailer.branding.footer`
+
+	InboundEnabled    Key = `inbound.enabled`
+	InboundDomain     Key = `inbound.domain`
+	InboundListen     Key = `inbound.listen`
+	InboundMaxSize    Key = `inbound.maxsize`
+	InboundAuthservID Key = `inbound.authservid`
+
+	PushEnabled           Key = `push.enabled`
+	PushTimeout           Key = `push.timeout`
+	PushAllowedHosts      Key = `push.allowedhosts`
+	PushWebPushEnabled    Key = `push.webpush.enabled`
+	PushWebPushPublicKey  Key = `push.webpush.publickey`
+	PushWebPushPrivateKey Key = `push.webpush.privatekey`
+	PushWebPushSubject    Key = `push.webpush.subject`
+	PushNtfyEnabled       Key = `push.ntfy.enabled`
+	PushNtfyDefaultServer Key = `push.ntfy.defaultserver`
+	PushGotifyEnabled     Key = `push.gotify.enabled`
 
 	RedisEnabled  Key = `redis.enabled`
 	RedisHost     Key = `redis.host`
 	RedisPassword Key = `redis.password`
 	RedisDB       Key = `redis.db`
 
+	RealtimeEnabled    Key = `realtime.enabled`
+	RealtimeBacklog    Key = `realtime.backlog`
+	RealtimeBufferSize Key = `realtime.buffersize`
+
 	LogEnabled       Key = `log.enabled`
 	LogStandard      Key = `log.standard`
 	LogLevel         Key = `log.level`
@@ -293,6 +327,7 @@ func InitDefaultConfig() {
 	ServiceEnableMetrics.setDefault(false)
 	ServiceMotd.setDefault("")
 	ServiceEnableLinkSharing.setDefault(true)
+	ServiceEnablePublicProjects.setDefault(true)
 	ServiceEnableRegistration.setDefault(true)
 	ServiceEnableTaskAttachments.setDefault(true)
 	ServiceTimeZone.setDefault("GMT")
@@ -301,6 +336,7 @@ func InitDefaultConfig() {
 	ServiceEnableEmailReminders.setDefault(true)
 	ServiceEnableUserDeletion.setDefault(true)
 	ServiceMaxAvatarSize.setDefault(1024)
+	ServiceTrashRetention.setDefault(30)
 	ServiceDemoMode.setDefault(false)
 
 	// Auth
@@ -338,11 +374,38 @@ func InitDefaultConfig() {
 	MailerQueueTimeout.setDefault(30)
 	MailerForceSSL.setDefault(false)
 	MailerAuthType.setDefault("plain")
+	MailerTransport.setDefault("smtp")
+	MailerSendmailPath.setDefault("/usr/sbin/sendmail")
+	MailerMaildir.setDefault("")
+	MailerMaxAttempts.setDefault(10)
+	MailerRetryInterval.setDefault(60)
+	MailerLogRetention.setDefault(30)
+	MailerTemplatesDir.setDefault("")
+	MailerBrandingPrimaryColor.setDefault("#1973ff")
+	MailerBrandingBackgroundColor.setDefault("#f3f4f6")
+	MailerBrandingFooter.setDefault("")
+	// Inbound mail
+	InboundEnabled.setDefault(false)
+	InboundListen.setDefault("127.0.0.1:2525")
+	InboundMaxSize.setDefault("25MB")
+	InboundAuthservID.setDefault("")
+	// Push
+	PushEnabled.setDefault(true)
+	PushTimeout.setDefault(10)
+	PushAllowedHosts.setDefault([]string{})
+	PushWebPushEnabled.setDefault(false)
+	PushNtfyEnabled.setDefault(true)
+	PushNtfyDefaultServer.setDefault("https://ntfy.sh")
+	PushGotifyEnabled.setDefault(true)
 	// Redis
 	RedisEnabled.setDefault(false)
 	RedisHost.setDefault("localhost:6379")
 	RedisPassword.setDefault("")
 	RedisDB.setDefault(0)
+	// Realtime
+	RealtimeEnabled.setDefault(true)
+	RealtimeBacklog.setDefault(1000)
+	RealtimeBufferSize.setDefault(100)
 	// Logger
 	LogEnabled.setDefault(true)
 	LogStandard.setDefault("stdout")
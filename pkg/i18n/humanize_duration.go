@@ -14,18 +14,17 @@
 // You should have received a copy of the GNU Affero General Public Licensee
 // along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
-package utils
+package i18n
 
 import (
-	"fmt"
 	"math"
 	"strings"
 	"time"
 )
 
-// HumanizeDuration formats a time.Duration in a human-friendly format.
+// HumanizeDuration formats a time.Duration in a human-friendly format in the given language.
 // Based on https://gist.github.com/harshavardhana/327e0577c4fed9211f65
-func HumanizeDuration(duration time.Duration) string {
+func HumanizeDuration(lang string, duration time.Duration) string {
 	years := int64(duration.Hours() / 24 / 365)
 	days := int64(duration.Hours()/24) - years*365
 	weeks := days / 7
@@ -35,32 +34,28 @@ func HumanizeDuration(duration time.Duration) string {
 	minutes := int64(math.Mod(duration.Minutes(), 60))
 
 	chunks := []struct {
-		singularName string
-		amount       int64
+		key    string
+		amount int64
 	}{
-		{"year", years},
-		{"week", weeks},
-		{"day", days},
-		{"hour", hours},
-		{"minute", minutes},
+		{"time.years", years},
+		{"time.weeks", weeks},
+		{"time.days", days},
+		{"time.hours", hours},
+		{"time.minutes", minutes},
 	}
 
 	parts := []string{}
 
 	for _, chunk := range chunks {
-		switch chunk.amount {
-		case 0:
+		if chunk.amount == 0 {
 			continue
-		case 1:
-			parts = append(parts, fmt.Sprintf("one %s", chunk.singularName))
-		default:
-			parts = append(parts, fmt.Sprintf("%d %ss", chunk.amount, chunk.singularName))
 		}
+		parts = append(parts, TPlural(lang, chunk.key, chunk.amount))
 	}
 
 	if len(parts) > 1 {
-		return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+		return strings.Join(parts[:len(parts)-1], T(lang, "time.list_separator")) + T(lang, "time.list_last_separator") + parts[len(parts)-1]
 	}
 
-	return strings.Join(parts, ", ")
+	return strings.Join(parts, T(lang, "time.list_separator"))
 }
@@ -0,0 +1,227 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package i18n translates the texts Vikunja sends to users, like notification mails and error messages.
+// Translations are kept in one json file per language in the lang directory. English is the source language
+// and every other language falls back to it for keys it does not translate.
+// Error messages are written in English in the code, other languages translate them by their code below "errors".
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+)
+
+// DefaultLanguage is the language used when no translation exists in the requested language.
+const DefaultLanguage = "en"
+
+//go:embed lang/*.json
+var langFS embed.FS
+
+var (
+	catalogues map[string]map[string]string
+	loadOnce   sync.Once
+)
+
+func load() {
+	catalogues = make(map[string]map[string]string)
+
+	entries, err := langFS.ReadDir("lang")
+	if err != nil {
+		log.Errorf("Could not read translations: %s", err)
+		return
+	}
+
+	for _, entry := range entries {
+		content, err := langFS.ReadFile(path.Join("lang", entry.Name()))
+		if err != nil {
+			log.Errorf("Could not read translation %s: %s", entry.Name(), err)
+			continue
+		}
+
+		var nested map[string]interface{}
+		err = json.Unmarshal(content, &nested)
+		if err != nil {
+			log.Errorf("Could not parse translation %s: %s", entry.Name(), err)
+			continue
+		}
+
+		catalogue := make(map[string]string)
+		flatten("", nested, catalogue)
+		catalogues[strings.ToLower(strings.TrimSuffix(entry.Name(), ".json"))] = catalogue
+	}
+}
+
+// flatten turns nested translation objects into keys separated by dots.
+func flatten(prefix string, nested map[string]interface{}, out map[string]string) {
+	for key, value := range nested {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			out[key] = v
+		case map[string]interface{}:
+			flatten(key, v, out)
+		}
+	}
+}
+
+func getCatalogues() map[string]map[string]string {
+	loadOnce.Do(load)
+	return catalogues
+}
+
+// Languages returns the codes of all languages Vikunja has translations for.
+func Languages() []string {
+	langs := make([]string, 0, len(getCatalogues()))
+	for lang := range getCatalogues() {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// ResolveLanguage returns the code of the translation which should be used for a language as users or
+// browsers specify it, for example "de-DE" or "de_de". If there is no translation for the region, the one for
+// the base language is used. Returns an empty string if there is no translation for the language at all.
+func ResolveLanguage(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	if lang == "" {
+		return ""
+	}
+
+	if _, exists := getCatalogues()[lang]; exists {
+		return lang
+	}
+
+	base, _, _ := strings.Cut(lang, "-")
+	if _, exists := getCatalogues()[base]; exists {
+		return base
+	}
+
+	return ""
+}
+
+// MatchAcceptLanguage returns the best language with a translation from the value of an Accept-Language header.
+// Returns an empty string if none of the languages have a translation.
+func MatchAcceptLanguage(header string) string {
+	type weightedLanguage struct {
+		lang   string
+		weight float64
+	}
+
+	var langs []weightedLanguage
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if q, has := strings.CutPrefix(strings.TrimSpace(params), "q="); has {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if lang == "" || lang == "*" || weight <= 0 {
+			continue
+		}
+		langs = append(langs, weightedLanguage{lang: lang, weight: weight})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].weight > langs[j].weight
+	})
+
+	for _, l := range langs {
+		if resolved := ResolveLanguage(l.lang); resolved != "" {
+			return resolved
+		}
+	}
+
+	return ""
+}
+
+func lookup(lang, key string) (string, bool) {
+	resolved := ResolveLanguage(lang)
+	if resolved != "" {
+		if translation, exists := getCatalogues()[resolved][key]; exists {
+			return translation, true
+		}
+	}
+
+	translation, exists := getCatalogues()[DefaultLanguage][key]
+	return translation, exists
+}
+
+// T returns the translation of a key in a language, falling back to English if the language does not have a
+// translation for it. The translation is used as a format string for the params, which allows translations to
+// reorder them with explicit argument indexes like %[2]s or to leave them out entirely.
+// If the key does not exist at all, the key is returned.
+func T(lang, key string, params ...interface{}) string {
+	translation, exists := lookup(lang, key)
+	if !exists {
+		return key
+	}
+
+	if len(params) == 0 || !strings.Contains(translation, "%") {
+		return translation
+	}
+
+	return fmt.Sprintf(translation, params...)
+}
+
+// TPlural returns the translation of a key which depends on a count. The key needs to have a "one" and an
+// "other" variant, the count is passed as the first param.
+func TPlural(lang, key string, count int64, params ...interface{}) string {
+	variant := ".other"
+	if count == 1 {
+		variant = ".one"
+	}
+
+	return T(lang, key+variant, append([]interface{}{count}, params...)...)
+}
+
+// HasTranslation checks if a language has its own translation for a key, without falling back to English.
+func HasTranslation(lang, key string) bool {
+	resolved := ResolveLanguage(lang)
+	if resolved == "" {
+		return false
+	}
+
+	_, exists := getCatalogues()[resolved][key]
+	return exists
+}
+
+// FormatDateTime formats a time with the date and time format of a language in a time zone. If the time zone
+// is empty or invalid, the time is formatted in the time zone it already has.
+func FormatDateTime(lang string, t time.Time, timezone string) string {
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+
+	return t.Format(T(lang, "time.datetime_format"))
+}
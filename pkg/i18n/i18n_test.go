@@ -0,0 +1,104 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLanguage(t *testing.T) {
+	assert.Equal(t, "de", ResolveLanguage("de"))
+	assert.Equal(t, "de", ResolveLanguage("de-DE"))
+	assert.Equal(t, "de", ResolveLanguage("DE_ch"))
+	assert.Equal(t, "en", ResolveLanguage("en-US"))
+	assert.Equal(t, "", ResolveLanguage("xx-XX"))
+	assert.Equal(t, "", ResolveLanguage(""))
+}
+
+func TestMatchAcceptLanguage(t *testing.T) {
+	assert.Equal(t, "de", MatchAcceptLanguage("de-DE,de;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", MatchAcceptLanguage("fr;q=0.9, en;q=0.8, de;q=0.7"))
+	assert.Equal(t, "de", MatchAcceptLanguage("en;q=0.5, de"))
+	assert.Equal(t, "", MatchAcceptLanguage("xx, *;q=0.1"))
+	assert.Equal(t, "", MatchAcceptLanguage(""))
+}
+
+func TestT(t *testing.T) {
+	t.Run("translated", func(t *testing.T) {
+		assert.Equal(t, "Hallo Frederick,", T("de-DE", "notifications.common.greeting", "Frederick"))
+	})
+	t.Run("fallback to english", func(t *testing.T) {
+		assert.Equal(t, "Hi Frederick,", T("xx", "notifications.common.greeting", "Frederick"))
+		assert.Equal(t, "Hi Frederick,", T("", "notifications.common.greeting", "Frederick"))
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		assert.Equal(t, "does.not.exist", T("de", "does.not.exist"))
+	})
+	t.Run("reordered params", func(t *testing.T) {
+		assert.Equal(t,
+			"If you did not receive an email with reset instructions, you can always request a new one at [https://example.com](https://example.com).",
+			T("en", "notifications.totp.account_locked.reset_info", "https://example.com"),
+		)
+	})
+	t.Run("plural", func(t *testing.T) {
+		assert.Equal(t, "tomorrow", TPlural("en", "notifications.account.deletion.in_days", 1))
+		assert.Equal(t, "in 3 days", TPlural("en", "notifications.account.deletion.in_days", 3))
+		assert.Equal(t, "in 3 Tagen", TPlural("de", "notifications.account.deletion.in_days", 3))
+	})
+}
+
+func TestHasTranslation(t *testing.T) {
+	assert.True(t, HasTranslation("de-DE", "errors.3001"))
+	assert.False(t, HasTranslation("de", "errors.99999"))
+	assert.False(t, HasTranslation("xx", "notifications.common.greeting"))
+}
+
+func TestFormatDateTime(t *testing.T) {
+	date := time.Date(2023, 10, 2, 14, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "2023-10-02 14:30", FormatDateTime("en", date, ""))
+	assert.Equal(t, "02.10.2023 16:30", FormatDateTime("de", date, "Europe/Berlin"))
+	assert.Equal(t, "2023-10-02 14:30", FormatDateTime("en", date, "Not/AZone"))
+}
+
+// Every translation needs to have the same parameters as the english original, otherwise the mails would contain
+// formatting errors.
+func TestTranslationsMatchEnglish(t *testing.T) {
+	english := getCatalogues()[DefaultLanguage]
+	for _, lang := range Languages() {
+		for key, translation := range getCatalogues()[lang] {
+			original, exists := english[key]
+			if !exists {
+				continue
+			}
+			assert.Equal(t, countVerbs(original), countVerbs(translation), "%s in %s", key, lang)
+		}
+	}
+}
+
+func countVerbs(s string) int {
+	count := 0
+	for i := 0; i < len(s)-1; i++ {
+		if s[i] == '%' && s[i+1] != '%' {
+			count++
+		}
+	}
+	return count
+}
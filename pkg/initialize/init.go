@@ -28,7 +28,10 @@ import (
 	"code.vikunja.io/api/pkg/migration"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth/openid"
+	"code.vikunja.io/api/pkg/modules/inbound"
 	"code.vikunja.io/api/pkg/modules/keyvalue"
+	"code.vikunja.io/api/pkg/modules/realtime"
+	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/red"
 	"code.vikunja.io/api/pkg/user"
 )
@@ -78,6 +81,11 @@ func FullInitWithoutAsync() {
 
 	// Start the mail daemon
 	mail.StartMailDaemon()
+
+	// Load and validate custom mail templates
+	if err := notifications.InitMailTemplates(); err != nil {
+		log.Fatalf("Invalid mail templates: %s", err)
+	}
 }
 
 // FullInit initializes all kinds of things in the right order
@@ -89,13 +97,22 @@ func FullInit() {
 	cron.Init()
 	models.RegisterReminderCron()
 	models.RegisterOverdueReminderCron()
+	models.RegisterNotificationDigestCron()
 	user.RegisterTokenCleanupCron()
 	user.RegisterDeletionNotificationCron()
 	models.RegisterUserDeletionCron()
 	models.RegisterOldExportCleanupCron()
+	models.RegisterTrashPurgeCron()
+	mail.RegisterMailLogCleanupCron()
 	openid.CleanupSavedOpenIDProviders()
 	models.RegisterPeriodicTypesenseResyncCron()
 
+	// Start streaming changes to clients
+	realtime.InitRealtime()
+
+	// Start accepting emails which create tasks and comments
+	inbound.Init()
+
 	// Start processing events
 	go func() {
 		models.RegisterListeners()
@@ -17,7 +17,6 @@
 package mail
 
 import (
-	"context"
 	"crypto/tls"
 	"time"
 
@@ -27,8 +26,16 @@ import (
 	"github.com/wneessen/go-mail"
 )
 
-// Queue is the mail queue
-var Queue chan *mail.Msg
+// queueSignal is used to tell the mail daemon about new mails in the queue
+var queueSignal = make(chan struct{}, 1)
+
+func wakeUpQueue() {
+	select {
+	case queueSignal <- struct{}{}:
+	default:
+		// The daemon was already notified
+	}
+}
 
 func getClient() (*mail.Client, error) {
 
@@ -82,58 +89,30 @@ func getClient() (*mail.Client, error) {
 	)
 }
 
-// StartMailDaemon starts the mail daemon
+// StartMailDaemon starts the mail daemon which delivers all mails in the queue
 func StartMailDaemon() {
-	Queue = make(chan *mail.Msg, config.MailerQueuelength.GetInt())
-
-	if !config.MailerEnabled.GetBool() {
+	if !config.MailerEnabled.GetBool() || isUnderTest {
 		return
 	}
 
-	if config.MailerHost.GetString() == "" {
+	if config.MailerTransport.GetString() == TransportSMTP && config.MailerHost.GetString() == "" {
 		log.Warning("Mailer seems to be not configured! Please see the config docs for more details.")
 		return
 	}
 
-	c, err := getClient()
+	t, err := getTransport()
 	if err != nil {
-		log.Errorf("Could not create mail client: %v", err)
+		log.Errorf("Could not create mail transport: %v", err)
 		return
 	}
+
 	go func() {
-		var err error
-		open := false
 		for {
+			processQueue(t)
+
 			select {
-			case m, ok := <-Queue:
-				if !ok {
-					return
-				}
-				if !open {
-					err = c.DialWithContext(context.Background())
-					if err != nil {
-						log.Errorf("Error during connect to smtp server: %s", err)
-						break
-					}
-					open = true
-				}
-				err = c.Send(m)
-				if err != nil {
-					log.Errorf("Error when sending mail: %s", err)
-					break
-				}
-				// Close the connection to the SMTP server if no email was sent in
-				// the last 30 seconds.
-			case <-time.After(config.MailerQueueTimeout.GetDuration() * time.Second):
-				if open {
-					open = false
-					err = c.Close()
-					if err != nil {
-						log.Errorf("Error closing the mail server connection: %s\n", err)
-						break
-					}
-					log.Info("Closed connection to mail server")
-				}
+			case <-queueSignal:
+			case <-time.After(queuePollInterval):
 			}
 		}
 	}()
@@ -0,0 +1,446 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mail
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+
+	"github.com/wneessen/go-mail"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// QueuedMailStatus is the delivery status of a mail in the queue
+type QueuedMailStatus int
+
+// All delivery states of a mail
+const (
+	QueuedMailStatusPending QueuedMailStatus = iota
+	QueuedMailStatusSending
+	QueuedMailStatusSent
+	QueuedMailStatusFailed
+)
+
+func (s QueuedMailStatus) String() string {
+	switch s {
+	case QueuedMailStatusPending:
+		return "pending"
+	case QueuedMailStatusSending:
+		return "sending"
+	case QueuedMailStatusSent:
+		return "sent"
+	case QueuedMailStatusFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+// ParseQueuedMailStatus returns the status for its string representation
+func ParseQueuedMailStatus(status string) (QueuedMailStatus, error) {
+	for _, s := range []QueuedMailStatus{QueuedMailStatusPending, QueuedMailStatusSending, QueuedMailStatusSent, QueuedMailStatusFailed} {
+		if strings.EqualFold(s.String(), status) {
+			return s, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown mail status '%s'", status)
+}
+
+// The longest time we wait between two delivery attempts
+const maxRetryInterval = 12 * time.Hour
+
+// How often the queue is checked for mails which are due for another delivery attempt
+const queuePollInterval = 10 * time.Second
+
+// After this time, mails which are still being sent are assumed to be interrupted, for example because Vikunja
+// was stopped while sending them.
+const sendingTimeout = 10 * time.Minute
+
+// QueuedMail is an outgoing mail persisted in the database. Mails stay in the table after they were sent or
+// failed permanently to serve as delivery log until they are removed after mailer.logretention days.
+// The content of sent mails is removed, since it can contain secrets like password reset tokens.
+type QueuedMail struct {
+	// The unique, numeric id of this mail.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+
+	From        string      `xorm:"text not null" json:"from"`
+	To          string      `xorm:"text not null" json:"to"`
+	ReplyTo     string      `xorm:"text null" json:"reply_to"`
+	Subject     string      `xorm:"text null" json:"subject"`
+	Message     string      `xorm:"longtext null" json:"-"`
+	HTMLMessage string      `xorm:"longtext null" json:"-"`
+	ContentType ContentType `xorm:"int not null default 0" json:"-"`
+	Headers     []*header   `xorm:"json null" json:"-"`
+	// The files embedded into the mail as json object of file names and their base64 encoded content.
+	Embeds string `xorm:"longtext null" json:"-"`
+
+	// The delivery status of this mail.
+	Status QueuedMailStatus `xorm:"int not null default 0 INDEX" json:"status"`
+	// How often we tried to deliver this mail.
+	Attempts int `xorm:"int not null default 0" json:"attempts"`
+	// The error of the last failed delivery attempt.
+	LastError string `xorm:"text null" json:"last_error"`
+	// When the next delivery attempt will be made. Only set for pending mails.
+	NextAttempt time.Time `xorm:"DATETIME null INDEX" json:"next_attempt"`
+	// When the mail was delivered.
+	SentAt time.Time `xorm:"DATETIME null" json:"sent_at"`
+
+	// A timestamp when this mail was queued.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when the status of this mail changed the last time.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+}
+
+// TableName returns the table name for queued mails
+func (*QueuedMail) TableName() string {
+	return "mail_queue"
+}
+
+// newQueuedMail converts mail options to a mail which can be persisted. All embedded files are read into memory.
+func newQueuedMail(opts *Opts) (qm *QueuedMail, err error) {
+	if opts.From == "" {
+		opts.From = "Vikunja <" + config.MailerFromEmail.GetString() + ">"
+	}
+
+	embeds := make(map[string][]byte, len(opts.Embeds)+len(opts.EmbedFS))
+	for name, r := range opts.Embeds {
+		embeds[name], err = io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("could not read embedded file %s: %w", name, err)
+		}
+	}
+	for name, fs := range opts.EmbedFS {
+		embeds[name], err = fs.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read embedded file %s: %w", name, err)
+		}
+	}
+
+	qm = &QueuedMail{
+		From:        opts.From,
+		To:          opts.To,
+		ReplyTo:     opts.ReplyTo,
+		Subject:     opts.Subject,
+		Message:     opts.Message,
+		HTMLMessage: opts.HTMLMessage,
+		ContentType: opts.ContentType,
+		Headers:     opts.Headers,
+		Status:      QueuedMailStatusPending,
+		NextAttempt: time.Now(),
+	}
+
+	if len(embeds) > 0 {
+		raw, err := json.Marshal(embeds)
+		if err != nil {
+			return nil, err
+		}
+		qm.Embeds = string(raw)
+	}
+
+	return qm, nil
+}
+
+func (qm *QueuedMail) getMessage() (*mail.Msg, error) {
+	opts := &Opts{
+		From:        qm.From,
+		To:          qm.To,
+		ReplyTo:     qm.ReplyTo,
+		Subject:     qm.Subject,
+		Message:     qm.Message,
+		HTMLMessage: qm.HTMLMessage,
+		ContentType: qm.ContentType,
+		Headers:     qm.Headers,
+	}
+
+	if qm.Embeds != "" {
+		embeds := make(map[string][]byte)
+		err := json.Unmarshal([]byte(qm.Embeds), &embeds)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode embedded files: %w", err)
+		}
+		opts.Embeds = make(map[string]io.Reader, len(embeds))
+		for name, content := range embeds {
+			opts.Embeds[name] = bytes.NewReader(content)
+		}
+	}
+
+	return getMessage(opts), nil
+}
+
+// getRetryDelay returns how long to wait before the next delivery attempt. The delay doubles with every attempt.
+func getRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(config.MailerRetryInterval.GetInt64()) * time.Second
+	for i := 1; i < attempts && delay < maxRetryInterval; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryInterval {
+		delay = maxRetryInterval
+	}
+	return delay
+}
+
+// isPermanentError returns true if the smtp server rejected the mail in a way which will not change when
+// trying again, for example because the recipient does not exist.
+func isPermanentError(err error) bool {
+	var sendErr *mail.SendError
+	if !errors.As(err, &sendErr) || sendErr.IsTemp() {
+		return false
+	}
+
+	switch sendErr.Reason {
+	case mail.ErrSMTPMailFrom, mail.ErrSMTPRcptTo, mail.ErrSMTPData:
+		return true
+	}
+
+	return false
+}
+
+// enqueue persists a mail so that the mail daemon delivers it.
+func enqueue(opts *Opts) error {
+	qm, err := newQueuedMail(opts)
+	if err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err = s.Insert(qm)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	if err := s.Commit(); err != nil {
+		return err
+	}
+
+	wakeUpQueue()
+	return nil
+}
+
+// recordAttempt saves the outcome of a delivery attempt. Mails which could not be delivered are retried with
+// an increasing delay until mailer.maxattempts is reached.
+func recordAttempt(s *xorm.Session, qm *QueuedMail, sendErr error) error {
+	qm.Attempts++
+	cols := []string{"status", "attempts", "last_error", "next_attempt", "sent_at"}
+
+	switch {
+	case sendErr == nil:
+		qm.Status = QueuedMailStatusSent
+		qm.SentAt = time.Now()
+		qm.LastError = ""
+		// Only the metadata is kept for the delivery log
+		qm.Message = ""
+		qm.HTMLMessage = ""
+		qm.Headers = nil
+		qm.Embeds = ""
+		cols = append(cols, "message", "html_message", "headers", "embeds")
+	case isPermanentError(sendErr) || qm.Attempts >= config.MailerMaxAttempts.GetInt():
+		qm.Status = QueuedMailStatusFailed
+		qm.LastError = sendErr.Error()
+		log.Errorf("Giving up sending mail %d to %s after %d attempts: %s", qm.ID, qm.To, qm.Attempts, sendErr)
+	default:
+		qm.Status = QueuedMailStatusPending
+		qm.LastError = sendErr.Error()
+		qm.NextAttempt = time.Now().Add(getRetryDelay(qm.Attempts))
+		log.Warningf("Could not send mail %d to %s, will try again at %s: %s", qm.ID, qm.To, qm.NextAttempt.Format(time.RFC3339), sendErr)
+	}
+
+	_, err := s.
+		Where("id = ?", qm.ID).
+		Cols(cols...).
+		Update(qm)
+	return err
+}
+
+// claimDueMails returns all mails which are due for delivery and marks them as being sent so that other Vikunja
+// instances using the same database do not send them as well.
+func claimDueMails(s *xorm.Session) (claimed []*QueuedMail, err error) {
+	mails := []*QueuedMail{}
+	err = s.
+		Where("status = ? AND next_attempt <= ?", QueuedMailStatusPending, time.Now()).
+		OrderBy("id asc").
+		Limit(config.MailerQueuelength.GetInt()).
+		Find(&mails)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed = make([]*QueuedMail, 0, len(mails))
+	for _, qm := range mails {
+		qm.Status = QueuedMailStatusSending
+		affected, err := s.
+			Where("id = ? AND status = ?", qm.ID, QueuedMailStatusPending).
+			Cols("status").
+			Update(qm)
+		if err != nil {
+			return nil, err
+		}
+		if affected == 0 {
+			// Another instance was faster
+			continue
+		}
+		claimed = append(claimed, qm)
+	}
+
+	return claimed, nil
+}
+
+// processQueue sends all mails which are due.
+func processQueue(t transport) {
+	s := db.NewSession()
+	defer s.Close()
+
+	err := releaseInterruptedMails(s)
+	if err != nil {
+		log.Errorf("Could not requeue interrupted mails: %s", err)
+	}
+
+	mails, err := claimDueMails(s)
+	if err != nil {
+		log.Errorf("Could not get mails from the queue: %s", err)
+		return
+	}
+
+	if len(mails) == 0 {
+		return
+	}
+
+	log.Debugf("Sending %d mails from the queue", len(mails))
+
+	openErr := t.open()
+	if openErr != nil {
+		log.Errorf("Error during connect to smtp server: %s", openErr)
+	}
+
+	for _, qm := range mails {
+		sendErr := openErr
+		if sendErr == nil {
+			var m *mail.Msg
+			m, sendErr = qm.getMessage()
+			if sendErr == nil {
+				sendErr = t.send(m)
+			}
+		}
+
+		err = recordAttempt(s, qm, sendErr)
+		if err != nil {
+			log.Errorf("Could not save the delivery status of mail %d: %s", qm.ID, err)
+		}
+	}
+
+	if openErr == nil {
+		if err := t.close(); err != nil {
+			log.Errorf("Error closing the mail server connection: %s", err)
+		}
+	}
+}
+
+// releaseInterruptedMails puts mails back into the queue which were being sent when Vikunja was stopped.
+func releaseInterruptedMails(s *xorm.Session) error {
+	_, err := s.
+		Where("status = ? AND updated < ?", QueuedMailStatusSending, time.Now().Add(-sendingTimeout)).
+		Cols("status").
+		Update(&QueuedMail{Status: QueuedMailStatusPending})
+	return err
+}
+
+// ListQueuedMails returns the newest mails in the queue and delivery log, optionally only those with a status.
+func ListQueuedMails(s *xorm.Session, status *QueuedMailStatus, limit int) (mails []*QueuedMail, err error) {
+	cond := builder.NewCond()
+	if status != nil {
+		cond = builder.Eq{"status": *status}
+	}
+
+	mails = []*QueuedMail{}
+	err = s.
+		Where(cond).
+		OrderBy("id desc").
+		Limit(limit).
+		Find(&mails)
+	return
+}
+
+// RetryQueuedMail queues a mail for immediate delivery again, for example after it failed permanently.
+func RetryQueuedMail(s *xorm.Session, id int64) error {
+	qm := &QueuedMail{}
+	exists, err := s.Where("id = ?", id).Get(qm)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("mail %d does not exist", id)
+	}
+	if qm.Status == QueuedMailStatusSent || qm.Status == QueuedMailStatusSending {
+		return fmt.Errorf("mail %d is already %s", id, qm.Status)
+	}
+
+	qm.Status = QueuedMailStatusPending
+	qm.Attempts = 0
+	qm.NextAttempt = time.Now()
+	_, err = s.
+		Where("id = ?", id).
+		Cols("status", "attempts", "next_attempt").
+		Update(qm)
+	if err != nil {
+		return err
+	}
+
+	wakeUpQueue()
+	return nil
+}
+
+// RegisterMailLogCleanupCron removes sent and failed mails from the delivery log once they are older than
+// mailer.logretention days.
+func RegisterMailLogCleanupCron() {
+	retention := config.MailerLogRetention.GetInt()
+	if retention <= 0 {
+		return
+	}
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		deleted, err := s.
+			Where("status IN (?, ?) AND updated < ?", QueuedMailStatusSent, QueuedMailStatusFailed, time.Now().Add(-time.Duration(retention)*24*time.Hour)).
+			Delete(&QueuedMail{})
+		if err != nil {
+			log.Errorf("Could not remove old mails from the delivery log: %s", err)
+			return
+		}
+		if deleted > 0 {
+			log.Debugf("Removed %d old mails from the delivery log", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register mail log cleanup cron: %s", err)
+	}
+}
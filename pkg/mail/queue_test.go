@@ -0,0 +1,164 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed testing.go
+var testEmbedFS embed.FS
+
+func TestQueuedMail(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		opts := &Opts{
+			From:        "Vikunja <noreply@example.com>",
+			To:          "user@example.com",
+			ReplyTo:     "reply@example.com",
+			Subject:     "Test",
+			Message:     "Plain text",
+			HTMLMessage: "<p>Html</p>",
+			ContentType: ContentTypeMultipart,
+			Embeds: map[string]io.Reader{
+				"image.png": strings.NewReader("not really an image"),
+			},
+			EmbedFS: map[string]*embed.FS{
+				"testing.go": &testEmbedFS,
+			},
+		}
+
+		qm, err := newQueuedMail(opts)
+		require.NoError(t, err)
+		assert.Equal(t, QueuedMailStatusPending, qm.Status)
+		assert.Contains(t, qm.Embeds, "image.png")
+		assert.Contains(t, qm.Embeds, "testing.go")
+
+		m, err := qm.getMessage()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, err = m.WriteTo(&buf)
+		require.NoError(t, err)
+		raw := buf.String()
+		assert.Contains(t, raw, "To: <user@example.com>")
+		assert.Contains(t, raw, "Reply-To: <reply@example.com>")
+		assert.Contains(t, raw, "Subject: Test")
+		assert.Contains(t, raw, "Plain text")
+		assert.Contains(t, raw, "<p>Html</p>")
+		assert.Contains(t, raw, `filename="image.png"`)
+		assert.Contains(t, raw, `filename="testing.go"`)
+	})
+	t.Run("default from", func(t *testing.T) {
+		qm, err := newQueuedMail(&Opts{To: "user@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "Vikunja <"+config.MailerFromEmail.GetString()+">", qm.From)
+		assert.Empty(t, qm.Embeds)
+	})
+}
+
+func TestRecordAttempt(t *testing.T) {
+	x, err := db.CreateTestEngine()
+	require.NoError(t, err)
+	require.NoError(t, x.Sync2(&QueuedMail{}))
+
+	s := db.NewSession()
+	defer s.Close()
+
+	qm, err := newQueuedMail(&Opts{
+		To:          "user@example.com",
+		Subject:     "Reset your password",
+		Message:     "Your token is secret",
+		HTMLMessage: "<p>Your token is secret</p>",
+		Embeds: map[string]io.Reader{
+			"image.png": strings.NewReader("not really an image"),
+		},
+	})
+	require.NoError(t, err)
+	_, err = s.Insert(qm)
+	require.NoError(t, err)
+
+	err = recordAttempt(s, qm, nil)
+	require.NoError(t, err)
+
+	sent := &QueuedMail{}
+	exists, err := s.Where("id = ?", qm.ID).Get(sent)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, QueuedMailStatusSent, sent.Status)
+	assert.Equal(t, "Reset your password", sent.Subject)
+	assert.Equal(t, "user@example.com", sent.To)
+	assert.Empty(t, sent.Message)
+	assert.Empty(t, sent.HTMLMessage)
+	assert.Empty(t, sent.Embeds)
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	config.MailerRetryInterval.Set(60)
+
+	assert.Equal(t, time.Minute, getRetryDelay(1))
+	assert.Equal(t, 2*time.Minute, getRetryDelay(2))
+	assert.Equal(t, 4*time.Minute, getRetryDelay(3))
+	assert.Equal(t, maxRetryInterval, getRetryDelay(100))
+}
+
+func TestParseQueuedMailStatus(t *testing.T) {
+	status, err := ParseQueuedMailStatus("Failed")
+	require.NoError(t, err)
+	assert.Equal(t, QueuedMailStatusFailed, status)
+
+	_, err = ParseQueuedMailStatus("lost")
+	assert.Error(t, err)
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := t.TempDir()
+	tr := &fileTransport{dir: dir}
+
+	require.NoError(t, tr.open())
+	require.NoError(t, tr.send(getMessage(&Opts{
+		From:    "noreply@example.com",
+		To:      "user@example.com",
+		Subject: "Maildir",
+		Message: "Hello",
+	})))
+	require.NoError(t, tr.close())
+
+	files, err := os.ReadDir(filepath.Join(dir, "new"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Subject: Maildir")
+
+	tmpFiles, err := os.ReadDir(filepath.Join(dir, "tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, tmpFiles)
+}
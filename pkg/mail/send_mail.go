@@ -31,6 +31,7 @@ import (
 type Opts struct {
 	From        string
 	To          string
+	ReplyTo     string
 	Subject     string
 	Message     string
 	HTMLMessage string
@@ -59,19 +60,28 @@ type header struct {
 // SendTestMail sends a test mail to a recipient.
 // It works without a queue.
 func SendTestMail(opts *Opts) error {
-	if config.MailerHost.GetString() == "" {
+	if config.MailerTransport.GetString() == TransportSMTP && config.MailerHost.GetString() == "" {
 		log.Warning("Mailer seems to be not configured! Please see the config docs for more details.")
 		return nil
 	}
 
-	c, err := getClient()
+	t, err := getTransport()
 	if err != nil {
 		return err
 	}
 
-	m := getMessage(opts)
+	err = t.open()
+	if err != nil {
+		return err
+	}
+
+	err = t.send(getMessage(opts))
+	if err != nil {
+		_ = t.close()
+		return err
+	}
 
-	return c.DialAndSend(m)
+	return t.close()
 }
 
 func getMessage(opts *Opts) *mail.Msg {
@@ -82,6 +92,9 @@ func getMessage(opts *Opts) *mail.Msg {
 	}
 	_ = m.From(opts.From)
 	_ = m.To(opts.To)
+	if opts.ReplyTo != "" {
+		_ = m.ReplyTo(opts.ReplyTo)
+	}
 	m.Subject(opts.Subject)
 
 	for _, h := range opts.Headers {
@@ -113,12 +126,15 @@ func getMessage(opts *Opts) *mail.Msg {
 }
 
 // SendMail puts a mail in the queue
-func SendMail(opts *Opts) {
+func SendMail(opts *Opts) error {
 	if isUnderTest {
 		sentMails = append(sentMails, opts)
-		return
+		return nil
+	}
+
+	if !config.MailerEnabled.GetBool() {
+		return nil
 	}
 
-	m := getMessage(opts)
-	Queue <- m
+	return enqueue(opts)
 }
@@ -0,0 +1,146 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"github.com/wneessen/go-mail"
+)
+
+// The transports mails can be sent with, configured through mailer.transport
+const (
+	TransportSMTP     = "smtp"
+	TransportSendmail = "sendmail"
+	TransportFile     = "file"
+)
+
+// transport delivers mails to their recipients.
+type transport interface {
+	// open is called before sending a batch of mails.
+	open() error
+	send(m *mail.Msg) error
+	// close is called after a batch of mails was sent.
+	close() error
+}
+
+func getTransport() (transport, error) {
+	switch config.MailerTransport.GetString() {
+	case TransportSMTP:
+		if config.MailerHost.GetString() == "" {
+			return nil, fmt.Errorf("mailer.host is not configured")
+		}
+		c, err := getClient()
+		if err != nil {
+			return nil, err
+		}
+		return &smtpTransport{client: c}, nil
+	case TransportSendmail:
+		return &sendmailTransport{path: config.MailerSendmailPath.GetString()}, nil
+	case TransportFile:
+		dir := config.MailerMaildir.GetString()
+		if dir == "" {
+			dir = filepath.Join(config.ServiceRootpath.GetString(), "mails")
+		}
+		return &fileTransport{dir: dir}, nil
+	}
+
+	return nil, fmt.Errorf("unknown mail transport '%s', must be one of %s, %s or %s", config.MailerTransport.GetString(), TransportSMTP, TransportSendmail, TransportFile)
+}
+
+// smtpTransport sends mails through the configured smtp server, reusing one connection per batch.
+type smtpTransport struct {
+	client *mail.Client
+}
+
+func (t *smtpTransport) open() error {
+	return t.client.DialWithContext(context.Background())
+}
+
+func (t *smtpTransport) send(m *mail.Msg) error {
+	err := t.client.Send(m)
+	if err != nil {
+		// Make sure the next mail in the batch does not fail because the connection is in a weird state
+		_ = t.client.Reset()
+	}
+	return err
+}
+
+func (t *smtpTransport) close() error {
+	return t.client.Close()
+}
+
+// sendmailTransport pipes mails into the local sendmail binary.
+type sendmailTransport struct {
+	path string
+}
+
+func (t *sendmailTransport) open() error {
+	return nil
+}
+
+func (t *sendmailTransport) send(m *mail.Msg) error {
+	return m.WriteToSendmailWithCommand(t.path)
+}
+
+func (t *sendmailTransport) close() error {
+	return nil
+}
+
+// fileTransport writes mails into a maildir instead of sending them. Useful for development, the maildir
+// can be opened with most mail clients.
+type fileTransport struct {
+	dir string
+}
+
+func (t *fileTransport) open() error {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(t.dir, sub), 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *fileTransport) send(m *mail.Msg) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "vikunja"
+	}
+
+	// Unique file names as recommended in https://cr.yp.to/proto/maildir.html
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "." + strconv.Itoa(os.Getpid()) + "." + hostname
+	tmp := filepath.Join(t.dir, "tmp", name)
+
+	err = m.WriteToFile(tmp)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(t.dir, "new", name))
+}
+
+func (t *fileTransport) close() error {
+	return nil
+}
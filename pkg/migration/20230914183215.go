@@ -0,0 +1,63 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskUsers20230914183215 struct {
+	ID      int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID  int64     `xorm:"bigint not null INDEX" json:"-"`
+	TaskID  int64     `xorm:"bigint not null INDEX" json:"-"`
+	Right   int64     `xorm:"bigint INDEX not null default 0" json:"right"`
+	Created time.Time `xorm:"created not null" json:"created"`
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+}
+
+func (taskUsers20230914183215) TableName() string {
+	return "task_users"
+}
+
+type linkShares20230914183215 struct {
+	TaskID int64 `xorm:"bigint INDEX not null default 0" json:"task_id"`
+}
+
+func (linkShares20230914183215) TableName() string {
+	return "link_shares"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20230914183215",
+		Description: "Add task shares for users and link shares",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(taskUsers20230914183215{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(linkShares20230914183215{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
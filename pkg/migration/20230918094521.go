@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type linkShares20230918094521 struct {
+	ExpiresAt time.Time `xorm:"DATETIME null 'expires_at'" json:"expires_at"`
+	MaxUses   int64     `xorm:"bigint not null default 0" json:"max_uses"`
+	Uses      int64     `xorm:"bigint not null default 0" json:"uses"`
+	Disabled  bool      `xorm:"not null default false" json:"disabled"`
+}
+
+func (linkShares20230918094521) TableName() string {
+	return "link_shares"
+}
+
+type linkShareAccesses20230918094521 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	LinkShareID int64     `xorm:"bigint not null INDEX" json:"link_share_id"`
+	IP          string    `xorm:"varchar(45) null" json:"ip"`
+	UserAgent   string    `xorm:"text null" json:"user_agent"`
+	Created     time.Time `xorm:"created not null" json:"created"`
+}
+
+func (linkShareAccesses20230918094521) TableName() string {
+	return "link_share_accesses"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20230918094521",
+		Description: "Add expiry, usage limits and an access log to link shares",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(linkShares20230918094521{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(linkShareAccesses20230918094521{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,52 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type notificationPreferences20230924163201 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	NotifiableID int64     `xorm:"bigint not null INDEX" json:"-"`
+	Name         string    `xorm:"varchar(250) not null INDEX" json:"name"`
+	ProjectID    int64     `xorm:"bigint not null default 0 INDEX" json:"project_id"`
+	Mail         bool      `xorm:"not null default true" json:"mail"`
+	InApp        bool      `xorm:"not null default true" json:"in_app"`
+	Created      time.Time `xorm:"created not null" json:"created"`
+	Updated      time.Time `xorm:"updated not null" json:"updated"`
+}
+
+func (notificationPreferences20230924163201) TableName() string {
+	return "notification_preferences"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20230924163201",
+		Description: "Add notification preferences",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(notificationPreferences20230924163201{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,66 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type users20230926081544 struct {
+	DigestMode string `xorm:"varchar(10) null" json:"-"`
+	DigestTime string `xorm:"varchar(5) null" json:"-"`
+}
+
+func (users20230926081544) TableName() string {
+	return "users"
+}
+
+type notificationDigestEntries20230926081544 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	NotifiableID int64     `xorm:"bigint not null INDEX" json:"-"`
+	Name         string    `xorm:"varchar(250) not null" json:"name"`
+	ProjectID    int64     `xorm:"bigint not null default 0" json:"project_id"`
+	TaskID       int64     `xorm:"bigint not null default 0" json:"task_id"`
+	TaskTitle    string    `xorm:"text null" json:"task_title"`
+	Line         string    `xorm:"text not null" json:"line"`
+	Created      time.Time `xorm:"created not null" json:"created"`
+}
+
+func (notificationDigestEntries20230926081544) TableName() string {
+	return "notification_digest_entries"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20230926081544",
+		Description: "Add notification digests",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(users20230926081544{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(notificationDigestEntries20230926081544{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,67 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type notificationPreferences20230928143012 struct {
+	Push bool `xorm:"not null default true" json:"push"`
+}
+
+func (notificationPreferences20230928143012) TableName() string {
+	return "notification_preferences"
+}
+
+type pushTargets20230928143012 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	NotifiableID int64     `xorm:"bigint not null INDEX" json:"-"`
+	Type         string    `xorm:"varchar(20) not null" json:"type"`
+	Title        string    `xorm:"varchar(250) null" json:"title"`
+	URL          string    `xorm:"text null" json:"url"`
+	Topic        string    `xorm:"varchar(250) null" json:"topic"`
+	Token        string    `xorm:"text null" json:"token"`
+	P256dh       string    `xorm:"text null" json:"p256dh"`
+	Auth         string    `xorm:"text null" json:"auth"`
+	Created      time.Time `xorm:"created not null" json:"created"`
+}
+
+func (pushTargets20230928143012) TableName() string {
+	return "push_targets"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20230928143012",
+		Description: "Add push notification targets",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(notificationPreferences20230928143012{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(pushTargets20230928143012{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
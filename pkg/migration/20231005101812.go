@@ -0,0 +1,61 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type mailQueue20231005101812 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk"`
+	From        string    `xorm:"text not null"`
+	To          string    `xorm:"text not null"`
+	ReplyTo     string    `xorm:"text null"`
+	Subject     string    `xorm:"text null"`
+	Message     string    `xorm:"longtext null"`
+	HTMLMessage string    `xorm:"longtext null"`
+	ContentType int       `xorm:"int not null default 0"`
+	Headers     []string  `xorm:"json null"`
+	Embeds      string    `xorm:"longtext null"`
+	Status      int       `xorm:"int not null default 0 INDEX"`
+	Attempts    int       `xorm:"int not null default 0"`
+	LastError   string    `xorm:"text null"`
+	NextAttempt time.Time `xorm:"DATETIME null INDEX"`
+	SentAt      time.Time `xorm:"DATETIME null"`
+	Created     time.Time `xorm:"created not null"`
+	Updated     time.Time `xorm:"updated not null"`
+}
+
+func (mailQueue20231005101812) TableName() string {
+	return "mail_queue"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231005101812",
+		Description: "Add mail queue table",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(mailQueue20231005101812{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(mailQueue20231005101812{})
+		},
+	})
+}
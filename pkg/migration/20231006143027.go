@@ -0,0 +1,63 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type users20231006143027 struct {
+	QuietHoursStart string `xorm:"varchar(5) null" json:"-"`
+	QuietHoursEnd   string `xorm:"varchar(5) null" json:"-"`
+}
+
+func (users20231006143027) TableName() string {
+	return "users"
+}
+
+type deferredReminders20231006143027 struct {
+	ID       int64     `xorm:"bigint autoincr not null unique pk" json:"-"`
+	UserID   int64     `xorm:"bigint not null INDEX" json:"-"`
+	TaskID   int64     `xorm:"bigint not null INDEX" json:"-"`
+	RemindAt time.Time `xorm:"DATETIME not null INDEX" json:"remind_at"`
+	Created  time.Time `xorm:"created not null" json:"-"`
+}
+
+func (deferredReminders20231006143027) TableName() string {
+	return "deferred_reminders"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231006143027",
+		Description: "Add quiet hours and deferred reminders",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(users20231006143027{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(deferredReminders20231006143027{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
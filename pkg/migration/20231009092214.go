@@ -0,0 +1,74 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type defaultReminder20231009092214 struct {
+	RelativePeriod int64  `json:"relative_period"`
+	RelativeTo     string `json:"relative_to"`
+}
+
+type users20231009092214 struct {
+	DefaultReminders []*defaultReminder20231009092214 `xorm:"json null" json:"-"`
+}
+
+func (users20231009092214) TableName() string {
+	return "users"
+}
+
+type projects20231009092214 struct {
+	DefaultReminders []*defaultReminder20231009092214 `xorm:"json null" json:"default_reminders"`
+}
+
+func (projects20231009092214) TableName() string {
+	return "projects"
+}
+
+type tasks20231009092214 struct {
+	DisableDefaultReminders bool `xorm:"not null default false" json:"disable_default_reminders"`
+}
+
+func (tasks20231009092214) TableName() string {
+	return "tasks"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231009092214",
+		Description: "Add default reminders to users and projects",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(users20231009092214{})
+			if err != nil {
+				return err
+			}
+
+			err = tx.Sync2(projects20231009092214{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(tasks20231009092214{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,58 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type tasks20231012084519 struct {
+	ComputedProgress *float64 `xorm:"DOUBLE null" json:"computed_progress"`
+	Estimate         float64  `xorm:"DOUBLE null" json:"estimate"`
+}
+
+func (tasks20231012084519) TableName() string {
+	return "tasks"
+}
+
+type projects20231012084519 struct {
+	ProgressFromSubtasks bool `xorm:"not null default false" json:"progress_from_subtasks"`
+	DoneFromSubtasks     bool `xorm:"not null default false" json:"done_from_subtasks"`
+}
+
+func (projects20231012084519) TableName() string {
+	return "projects"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231012084519",
+		Description: "Add progress roll-up from subtasks",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(tasks20231012084519{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(projects20231012084519{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
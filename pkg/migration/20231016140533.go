@@ -0,0 +1,79 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type projectTemplates20231016140533 struct {
+	ID                     int64       `xorm:"bigint autoincr not null unique pk" json:"id"`
+	Title                  string      `xorm:"varchar(250) not null" json:"title"`
+	Description            string      `xorm:"longtext null" json:"description"`
+	HexColor               string      `xorm:"varchar(6) null" json:"hex_color"`
+	DefaultReminders       interface{} `xorm:"json null" json:"default_reminders"`
+	EnforceBlockingTasks   bool        `xorm:"not null default false" json:"enforce_blocking_tasks"`
+	AutoScheduling         bool        `xorm:"not null default false" json:"auto_scheduling"`
+	SchedulingSkipWeekends bool        `xorm:"not null default false" json:"scheduling_skip_weekends"`
+	ProgressFromSubtasks   bool        `xorm:"not null default false" json:"progress_from_subtasks"`
+	DoneFromSubtasks       bool        `xorm:"not null default false" json:"done_from_subtasks"`
+	Buckets                interface{} `xorm:"json null" json:"buckets"`
+	DefaultBucketKey       int64       `xorm:"bigint null" json:"default_bucket_key"`
+	DoneBucketKey          int64       `xorm:"bigint null" json:"done_bucket_key"`
+	Tasks                  interface{} `xorm:"json null" json:"tasks"`
+	OwnerID                int64       `xorm:"bigint not null INDEX" json:"-"`
+	Created                time.Time   `xorm:"created not null" json:"created"`
+	Updated                time.Time   `xorm:"updated not null" json:"updated"`
+}
+
+func (projectTemplates20231016140533) TableName() string {
+	return "project_templates"
+}
+
+type taskTemplates20231016140533 struct {
+	ID      int64       `xorm:"bigint autoincr not null unique pk" json:"id"`
+	Title   string      `xorm:"varchar(250) not null" json:"title"`
+	Task    interface{} `xorm:"json not null" json:"task"`
+	OwnerID int64       `xorm:"bigint not null INDEX" json:"-"`
+	Created time.Time   `xorm:"created not null" json:"created"`
+	Updated time.Time   `xorm:"updated not null" json:"updated"`
+}
+
+func (taskTemplates20231016140533) TableName() string {
+	return "task_templates"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231016140533",
+		Description: "Add project and task templates",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(projectTemplates20231016140533{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(taskTemplates20231016140533{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(projectTemplates20231016140533{}, taskTemplates20231016140533{})
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type trashedItems20231018091522 struct {
+	ID          int64       `xorm:"bigint autoincr not null unique pk" json:"id"`
+	Kind        string      `xorm:"varchar(50) not null" json:"kind"`
+	EntityID    int64       `xorm:"bigint not null INDEX" json:"entity_id"`
+	Title       string      `xorm:"text not null" json:"title"`
+	ProjectID   int64       `xorm:"bigint not null default 0" json:"project_id"`
+	Content     interface{} `xorm:"json not null" json:"-"`
+	OwnerID     int64       `xorm:"bigint not null INDEX" json:"-"`
+	DeletedByID int64       `xorm:"bigint not null INDEX" json:"-"`
+	Deleted     time.Time   `xorm:"created not null INDEX" json:"deleted"`
+}
+
+func (trashedItems20231018091522) TableName() string {
+	return "trashed_items"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231018091522",
+		Description: "Add trash for tasks and projects",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(trashedItems20231018091522{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(trashedItems20231018091522{})
+		},
+	})
+}
@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type milestones20231019083047 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	Title       string    `xorm:"varchar(250) not null" json:"title"`
+	Description string    `xorm:"longtext null" json:"description"`
+	TargetDate  time.Time `xorm:"DATETIME null" json:"target_date"`
+	ProjectID   int64     `xorm:"bigint not null default 0 INDEX" json:"project_id"`
+	OwnerID     int64     `xorm:"bigint not null INDEX" json:"-"`
+	Created     time.Time `xorm:"created not null" json:"created"`
+	Updated     time.Time `xorm:"updated not null" json:"updated"`
+}
+
+func (milestones20231019083047) TableName() string {
+	return "milestones"
+}
+
+type tasks20231019083047 struct {
+	MilestoneID int64 `xorm:"bigint not null default 0 INDEX" json:"milestone_id"`
+}
+
+func (tasks20231019083047) TableName() string {
+	return "tasks"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231019083047",
+		Description: "Add milestones",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(milestones20231019083047{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(tasks20231019083047{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(milestones20231019083047{})
+		},
+	})
+}
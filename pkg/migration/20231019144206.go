@@ -0,0 +1,84 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type sprints20231019144206 struct {
+	ID        int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	Title     string    `xorm:"varchar(250) not null" json:"title"`
+	Goal      string    `xorm:"longtext null" json:"goal"`
+	ProjectID int64     `xorm:"bigint not null INDEX" json:"project_id"`
+	StartDate time.Time `xorm:"DATETIME not null" json:"start_date"`
+	EndDate   time.Time `xorm:"DATETIME not null" json:"end_date"`
+	ClosedAt  time.Time `xorm:"DATETIME null" json:"closed_at"`
+	Created   time.Time `xorm:"created not null" json:"created"`
+	Updated   time.Time `xorm:"updated not null" json:"updated"`
+}
+
+func (sprints20231019144206) TableName() string {
+	return "sprints"
+}
+
+type sprintTasks20231019144206 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	SprintID    int64     `xorm:"bigint not null INDEX" json:"sprint_id"`
+	TaskID      int64     `xorm:"bigint not null INDEX" json:"task_id"`
+	CarriedOver bool      `xorm:"not null default false" json:"carried_over"`
+	Completed   bool      `xorm:"not null default false" json:"completed"`
+	Added       time.Time `xorm:"created not null" json:"added"`
+}
+
+func (sprintTasks20231019144206) TableName() string {
+	return "sprint_tasks"
+}
+
+type tasks20231019144206 struct {
+	SprintID int64 `xorm:"bigint not null default 0 INDEX" json:"sprint_id"`
+}
+
+func (tasks20231019144206) TableName() string {
+	return "tasks"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20231019144206",
+		Description: "Add sprints",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(sprints20231019144206{})
+			if err != nil {
+				return err
+			}
+
+			err = tx.Sync2(sprintTasks20231019144206{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(tasks20231019144206{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(sprints20231019144206{}, sprintTasks20231019144206{})
+		},
+	})
+}
@@ -80,6 +80,21 @@ func (bt *BulkTask) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/bulk [post]
 func (bt *BulkTask) Update(s *xorm.Session, a web.Auth) (err error) {
+	if bt.Done {
+		project, err := GetProjectSimpleByID(s, bt.Tasks[0].ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, oldtask := range bt.Tasks {
+			if oldtask.Done {
+				continue
+			}
+			if err := checkTaskIsNotBlocked(s, oldtask.ID, project); err != nil {
+				return err
+			}
+		}
+	}
+
 	for _, oldtask := range bt.Tasks {
 
 		// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
@@ -118,5 +133,5 @@ func (bt *BulkTask) Update(s *xorm.Session, a web.Auth) (err error) {
 		}
 	}
 
-	return
+	return updateProgressFromSubtasks(s, bt.IDs...)
 }
@@ -0,0 +1,356 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// BulkTaskOperationKind is the change a bulk operation makes to all of its tasks
+type BulkTaskOperationKind string
+
+const (
+	BulkTaskOperationMove     BulkTaskOperationKind = "move"
+	BulkTaskOperationLabel    BulkTaskOperationKind = "label"
+	BulkTaskOperationUnlabel  BulkTaskOperationKind = "unlabel"
+	BulkTaskOperationAssign   BulkTaskOperationKind = "assign"
+	BulkTaskOperationUnassign BulkTaskOperationKind = "unassign"
+	BulkTaskOperationDone     BulkTaskOperationKind = "done"
+	BulkTaskOperationDelete   BulkTaskOperationKind = "delete"
+)
+
+// BulkTaskOperation applies the same change to a set of tasks which can be spread across multiple projects.
+type BulkTaskOperation struct {
+	// The ids of all tasks to change.
+	TaskIDs []int64 `json:"task_ids"`
+	// What to do with the tasks. One of `move`, `label`, `unlabel`, `assign`, `unassign`, `done` or `delete`.
+	Operation BulkTaskOperationKind `json:"operation"`
+
+	// The project to move the tasks to. Only used with `move`.
+	ProjectID int64 `json:"project_id"`
+	// The bucket in the new project to put the tasks in. Only used with `move`, the default bucket is used if none is provided.
+	BucketID int64 `json:"bucket_id"`
+	// The labels to add or remove. Only used with `label` and `unlabel`.
+	LabelIDs []int64 `json:"label_ids"`
+	// The users to assign or unassign. Only used with `assign` and `unassign`.
+	AssigneeIDs []int64 `json:"assignee_ids"`
+	// Whether to mark the tasks as done or undone. Only used with `done`.
+	Done bool `json:"done"`
+
+	// The outcome of the operation for every task.
+	Results []*BulkTaskOperationResult `json:"results"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// BulkTaskOperationResult holds the outcome of a bulk operation for a single task
+type BulkTaskOperationResult struct {
+	TaskID  int64 `json:"task_id"`
+	Success bool  `json:"success"`
+	// Why the operation failed for this task. The task is left unchanged, other tasks are still changed.
+	Error *web.HTTPError `json:"error,omitempty"`
+}
+
+func (bo *BulkTaskOperation) validate() error {
+	if len(bo.TaskIDs) == 0 {
+		return ErrBulkTasksNeedAtLeastOne{}
+	}
+
+	switch bo.Operation {
+	case BulkTaskOperationMove:
+		if bo.ProjectID == 0 {
+			return ErrInvalidBulkTaskOperation{Operation: bo.Operation}
+		}
+	case BulkTaskOperationLabel, BulkTaskOperationUnlabel:
+		if len(bo.LabelIDs) == 0 {
+			return ErrInvalidBulkTaskOperation{Operation: bo.Operation}
+		}
+	case BulkTaskOperationAssign, BulkTaskOperationUnassign:
+		if len(bo.AssigneeIDs) == 0 {
+			return ErrInvalidBulkTaskOperation{Operation: bo.Operation}
+		}
+	case BulkTaskOperationDone, BulkTaskOperationDelete:
+	default:
+		return ErrInvalidBulkTaskOperation{Operation: bo.Operation}
+	}
+
+	return nil
+}
+
+// CanCreate checks everything which applies to all tasks of the operation. The rights to the individual tasks are
+// checked when applying the operation and reported per task.
+func (bo *BulkTaskOperation) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if err := bo.validate(); err != nil {
+		return false, err
+	}
+
+	switch bo.Operation {
+	case BulkTaskOperationMove:
+		project := &Project{ID: bo.ProjectID}
+		can, err := project.CanWrite(s, a)
+		if err != nil || !can {
+			return false, err
+		}
+
+		if bo.BucketID != 0 {
+			bucket, err := getBucketByID(s, bo.BucketID)
+			if err != nil {
+				return false, err
+			}
+			if bucket.ProjectID != bo.ProjectID {
+				return false, ErrBucketDoesNotBelongToProject{BucketID: bo.BucketID, ProjectID: bo.ProjectID}
+			}
+		}
+	case BulkTaskOperationLabel:
+		for _, labelID := range bo.LabelIDs {
+			label, err := getLabelByIDSimple(s, labelID)
+			if err != nil {
+				return false, err
+			}
+			has, _, err := label.hasAccessToLabel(s, a)
+			if err != nil || !has {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// Create applies the operation to all tasks
+// @Summary Change many tasks at once
+// @Description Moves, labels, unlabels, assigns, unassigns, marks as done or deletes any set of tasks, even across projects. All changes happen in one transaction. Tasks the user cannot write to, which don't exist or for which the change is not possible are skipped and reported with their error in `results`, everything already changed for such a task is undone. All other tasks are still changed. Tasks moved to another project get a new index and are put into the given bucket or the default bucket of the new project.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param operation body models.BulkTaskOperation true "The operation and the ids of all tasks it should be applied to."
+// @Success 201 {object} models.BulkTaskOperation "The operation with the result for every task."
+// @Failure 400 {object} web.HTTPError "Invalid operation provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the target project or one of the labels."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/bulk/operations [post]
+func (bo *BulkTaskOperation) Create(s *xorm.Session, a web.Auth) (err error) {
+	bo.Results = make([]*BulkTaskOperationResult, 0, len(bo.TaskIDs))
+	seen := make(map[int64]bool, len(bo.TaskIDs))
+
+	// Savepoints only work inside a transaction
+	if !s.IsInTx() {
+		err = s.Begin()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, taskID := range bo.TaskIDs {
+		if seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+
+		result := &BulkTaskOperationResult{TaskID: taskID}
+		bo.Results = append(bo.Results, result)
+
+		// A task can fail after some of its changes were already written, those must not be kept
+		_, err = s.Exec("SAVEPOINT bulk_task_operation")
+		if err != nil {
+			return err
+		}
+
+		err = bo.applyToTask(s, taskID, a)
+		if err != nil {
+			httpErr, is := err.(web.HTTPErrorProcessor)
+			if !is {
+				return err
+			}
+			_, err = s.Exec("ROLLBACK TO SAVEPOINT bulk_task_operation")
+			if err != nil {
+				return err
+			}
+			e := httpErr.HTTPError()
+			result.Error = &e
+		} else {
+			result.Success = true
+		}
+
+		_, err = s.Exec("RELEASE SAVEPOINT bulk_task_operation")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bo *BulkTaskOperation) applyToTask(s *xorm.Session, taskID int64, a web.Auth) (err error) {
+	// Moving the task also checks the rights to the new project
+	probe := &Task{ID: taskID}
+	if bo.Operation == BulkTaskOperationMove {
+		probe.ProjectID = bo.ProjectID
+	}
+	can, err := probe.CanUpdate(s, a)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+
+	switch bo.Operation {
+	case BulkTaskOperationMove:
+		// Events of the first update are dispatched right away, so everything which could make the second
+		// update fail has to be checked before the task is changed at all.
+		err = bo.checkTargetBucketLimit(s, taskID)
+		if err != nil {
+			return err
+		}
+
+		movedProject := false
+		err = bo.updateTask(s, taskID, a, func(t *Task) {
+			if t.ProjectID != bo.ProjectID {
+				movedProject = true
+				t.ProjectID = bo.ProjectID
+				t.BucketID = 0
+				return
+			}
+			if bo.BucketID != 0 {
+				t.BucketID = bo.BucketID
+			}
+		})
+		if err != nil || !movedProject || bo.BucketID == 0 {
+			return err
+		}
+		// A task moved to another project always lands in the default bucket first. Only once it is in the
+		// new project it can be moved into the requested bucket.
+		return bo.updateTask(s, taskID, a, func(t *Task) {
+			t.BucketID = bo.BucketID
+		})
+	case BulkTaskOperationDone:
+		return bo.updateTask(s, taskID, a, func(t *Task) {
+			t.Done = bo.Done
+		})
+	case BulkTaskOperationLabel:
+		for _, labelID := range bo.LabelIDs {
+			lt := &LabelTask{TaskID: taskID, LabelID: labelID}
+			err = lt.Create(s, a)
+			if err != nil && !IsErrLabelIsAlreadyOnTask(err) {
+				return err
+			}
+		}
+		return nil
+	case BulkTaskOperationUnlabel:
+		_, err = s.
+			Where("task_id = ?", taskID).
+			In("label_id", bo.LabelIDs).
+			Delete(&LabelTask{})
+		if err != nil {
+			return err
+		}
+		return updateProjectByTaskID(s, taskID)
+	case BulkTaskOperationAssign:
+		return bo.assign(s, taskID, a)
+	case BulkTaskOperationUnassign:
+		for _, userID := range bo.AssigneeIDs {
+			exists, err := s.
+				Where("task_id = ? AND user_id = ?", taskID, userID).
+				Exist(&TaskAssginee{})
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+			la := &TaskAssginee{TaskID: taskID, UserID: userID}
+			err = la.Delete(s, a)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case BulkTaskOperationDelete:
+		return (&Task{ID: taskID}).Delete(s, a)
+	}
+
+	return nil
+}
+
+func (bo *BulkTaskOperation) checkTargetBucketLimit(s *xorm.Session, taskID int64) error {
+	if bo.BucketID == 0 {
+		return nil
+	}
+
+	task, err := GetTaskByIDSimple(s, taskID)
+	if err != nil {
+		return err
+	}
+	if task.BucketID == bo.BucketID {
+		return nil
+	}
+
+	bucket, err := getBucketByID(s, bo.BucketID)
+	if err != nil {
+		return err
+	}
+	return checkBucketLimit(s, &task, bucket)
+}
+
+// updateTask loads the complete task, changes it and saves it through the normal update so that buckets, indexes,
+// repeating tasks and everything else is handled like when updating a single task.
+func (bo *BulkTaskOperation) updateTask(s *xorm.Session, taskID int64, a web.Auth, change func(t *Task)) error {
+	t := &Task{ID: taskID}
+	err := t.ReadOne(s, a)
+	if err != nil {
+		return err
+	}
+
+	change(t)
+	return t.Update(s, a)
+}
+
+func (bo *BulkTaskOperation) assign(s *xorm.Session, taskID int64, a web.Auth) error {
+	task, err := GetTaskByIDSimple(s, taskID)
+	if err != nil {
+		return err
+	}
+
+	project, err := GetProjectSimpleByID(s, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range bo.AssigneeIDs {
+		exists, err := s.
+			Where("task_id = ? AND user_id = ?", taskID, userID).
+			Exist(&TaskAssginee{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		err = task.addNewAssigneeByID(s, userID, project, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
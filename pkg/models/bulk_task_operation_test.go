@@ -0,0 +1,330 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkTaskOperation_CanCreate(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("no tasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bo := &BulkTaskOperation{Operation: BulkTaskOperationDelete}
+		_, err := bo.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBulkTasksNeedAtLeastOne(err))
+	})
+	t.Run("unknown operation", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bo := &BulkTaskOperation{TaskIDs: []int64{1}, Operation: "archive"}
+		_, err := bo.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidBulkTaskOperation(err))
+	})
+	t.Run("move without project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bo := &BulkTaskOperation{TaskIDs: []int64{1}, Operation: BulkTaskOperationMove}
+		_, err := bo.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidBulkTaskOperation(err))
+	})
+	t.Run("move to a read only project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bo := &BulkTaskOperation{TaskIDs: []int64{1}, Operation: BulkTaskOperationMove, ProjectID: 9}
+		can, err := bo.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("move to a bucket of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bo := &BulkTaskOperation{TaskIDs: []int64{1}, Operation: BulkTaskOperationMove, ProjectID: 11, BucketID: 1}
+		_, err := bo.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketDoesNotBelongToProject(err))
+	})
+	t.Run("label without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bo := &BulkTaskOperation{TaskIDs: []int64{1}, Operation: BulkTaskOperationLabel, LabelIDs: []int64{3}}
+		can, err := bo.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestBulkTaskOperation_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	run := func(t *testing.T, bo *BulkTaskOperation) {
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := bo.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = bo.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+	}
+
+	t.Run("done across projects", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{1, 19, 20},
+			Operation: BulkTaskOperationDone,
+			Done:      true,
+		}
+		run(t, bo)
+
+		assert.Len(t, bo.Results, 3)
+		for _, result := range bo.Results {
+			assert.True(t, result.Success)
+			assert.Nil(t, result.Error)
+			db.AssertExists(t, "tasks", map[string]interface{}{
+				"id":   result.TaskID,
+				"done": true,
+			}, false)
+		}
+	})
+	t.Run("reports failures per task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{1, 18, 13, 9999, 1},
+			Operation: BulkTaskOperationDone,
+			Done:      true,
+		}
+		run(t, bo)
+
+		assert.Len(t, bo.Results, 4)
+		assert.True(t, bo.Results[0].Success)
+		assert.False(t, bo.Results[1].Success)
+		assert.Equal(t, http.StatusForbidden, bo.Results[1].Error.HTTPCode)
+		assert.False(t, bo.Results[2].Success)
+		assert.Equal(t, http.StatusForbidden, bo.Results[2].Error.HTTPCode)
+		assert.False(t, bo.Results[3].Success)
+		assert.Equal(t, ErrCodeTaskDoesNotExist, bo.Results[3].Error.Code)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":   1,
+			"done": true,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":   18,
+			"done": false,
+		}, false)
+	})
+	t.Run("move into the default bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{1, 19},
+			Operation: BulkTaskOperationMove,
+			ProjectID: 11,
+		}
+		run(t, bo)
+
+		s := db.NewSession()
+		defer s.Close()
+		indexes := map[int64]bool{}
+		for _, result := range bo.Results {
+			assert.True(t, result.Success)
+			task, err := GetTaskByIDSimple(s, result.TaskID)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(11), task.ProjectID)
+			bucket, err := getBucketByID(s, task.BucketID)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(11), bucket.ProjectID)
+			// Task #20 already has index 1 in that project
+			assert.NotEqual(t, int64(1), task.Index)
+			assert.False(t, indexes[task.Index])
+			indexes[task.Index] = true
+		}
+	})
+	t.Run("move into a bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{1, 19},
+			Operation: BulkTaskOperationMove,
+			ProjectID: 11,
+			BucketID:  27,
+		}
+		run(t, bo)
+
+		for _, id := range []int64{1, 19} {
+			db.AssertExists(t, "tasks", map[string]interface{}{
+				"id":         id,
+				"project_id": 11,
+				"bucket_id":  27,
+			}, false)
+		}
+	})
+	t.Run("move into a full bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		// Bucket 2 already holds as many tasks as its limit allows
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{19},
+			Operation: BulkTaskOperationMove,
+			ProjectID: 1,
+			BucketID:  2,
+		}
+		run(t, bo)
+
+		assert.Len(t, bo.Results, 1)
+		assert.False(t, bo.Results[0].Success)
+		assert.Equal(t, ErrCodeBucketLimitExceeded, bo.Results[0].Error.Code)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         19,
+			"project_id": 10,
+			"bucket_id":  10,
+		}, false)
+	})
+	t.Run("label and unlabel", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{19, 20},
+			Operation: BulkTaskOperationLabel,
+			LabelIDs:  []int64{1, 2},
+		}
+		run(t, bo)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  19,
+			"label_id": 2,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  20,
+			"label_id": 1,
+		}, false)
+
+		bo = &BulkTaskOperation{
+			TaskIDs:   []int64{1, 19},
+			Operation: BulkTaskOperationUnlabel,
+			LabelIDs:  []int64{2, 4},
+		}
+		run(t, bo)
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{
+			"task_id":  1,
+			"label_id": 4,
+		})
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{
+			"task_id":  19,
+			"label_id": 2,
+		})
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  19,
+			"label_id": 1,
+		}, false)
+	})
+	t.Run("assign and unassign", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:     []int64{1, 20},
+			Operation:   BulkTaskOperationAssign,
+			AssigneeIDs: []int64{1},
+		}
+		run(t, bo)
+		for _, result := range bo.Results {
+			assert.True(t, result.Success)
+			db.AssertExists(t, "task_assignees", map[string]interface{}{
+				"task_id": result.TaskID,
+				"user_id": 1,
+			}, false)
+		}
+
+		bo = &BulkTaskOperation{
+			TaskIDs:     []int64{1, 20},
+			Operation:   BulkTaskOperationUnassign,
+			AssigneeIDs: []int64{1},
+		}
+		run(t, bo)
+		for _, result := range bo.Results {
+			assert.True(t, result.Success)
+			db.AssertMissing(t, "task_assignees", map[string]interface{}{
+				"task_id": result.TaskID,
+				"user_id": 1,
+			})
+		}
+	})
+	t.Run("failing task is left unchanged", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		// User 1 can be assigned to task 1, but user 2 has no access to its project
+		bo := &BulkTaskOperation{
+			TaskIDs:     []int64{1},
+			Operation:   BulkTaskOperationAssign,
+			AssigneeIDs: []int64{1, 2},
+		}
+		run(t, bo)
+
+		assert.Len(t, bo.Results, 1)
+		assert.False(t, bo.Results[0].Success)
+		assert.Equal(t, ErrCodeUserDoesNotHaveAccessToProject, bo.Results[0].Error.Code)
+		db.AssertMissing(t, "task_assignees", map[string]interface{}{
+			"task_id": 1,
+			"user_id": 1,
+		})
+	})
+	t.Run("delete", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		bo := &BulkTaskOperation{
+			TaskIDs:   []int64{1, 20},
+			Operation: BulkTaskOperationDelete,
+		}
+		run(t, bo)
+		for _, id := range []int64{1, 20} {
+			db.AssertMissing(t, "tasks", map[string]interface{}{
+				"id": id,
+			})
+			db.AssertExists(t, "trashed_items", map[string]interface{}{
+				"kind":      TrashedItemKindTask,
+				"entity_id": id,
+			}, false)
+		}
+	})
+}
@@ -19,7 +19,9 @@ package models
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/web"
@@ -992,6 +994,127 @@ func (err ErrReminderRelativeToMissing) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrRelationCreatesCycle represents an error where a new relation would create a cycle, for example a task being
+// its own grandparent.
+type ErrRelationCreatesCycle struct {
+	TaskID      int64
+	OtherTaskID int64
+	Kind        RelationKind
+}
+
+// IsErrRelationCreatesCycle checks if an error is ErrRelationCreatesCycle.
+func IsErrRelationCreatesCycle(err error) bool {
+	_, ok := err.(ErrRelationCreatesCycle)
+	return ok
+}
+
+func (err ErrRelationCreatesCycle) Error() string {
+	return fmt.Sprintf("Task relation would create a cycle [TaskID: %v, OtherTaskID: %v, Kind: %v]", err.TaskID, err.OtherTaskID, err.Kind)
+}
+
+// ErrCodeRelationCreatesCycle holds the unique world-error code of this error
+const ErrCodeRelationCreatesCycle = 4023
+
+// HTTPError holds the http error description
+func (err ErrRelationCreatesCycle) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeRelationCreatesCycle,
+		Message:  "This relation would create a cycle between the tasks.",
+	}
+}
+
+// ErrTaskIsBlocked represents an error where a task is marked as done while it is blocked by open tasks.
+type ErrTaskIsBlocked struct {
+	TaskID     int64
+	BlockerIDs []int64
+}
+
+// IsErrTaskIsBlocked checks if an error is ErrTaskIsBlocked.
+func IsErrTaskIsBlocked(err error) bool {
+	_, ok := err.(ErrTaskIsBlocked)
+	return ok
+}
+
+func (err ErrTaskIsBlocked) blockers() string {
+	blockers := make([]string, 0, len(err.BlockerIDs))
+	for _, id := range err.BlockerIDs {
+		blockers = append(blockers, "#"+strconv.FormatInt(id, 10))
+	}
+	return strings.Join(blockers, ", ")
+}
+
+func (err ErrTaskIsBlocked) Error() string {
+	return fmt.Sprintf("Task is blocked by open tasks [TaskID: %v, BlockerIDs: %v]", err.TaskID, err.BlockerIDs)
+}
+
+// ErrCodeTaskIsBlocked holds the unique world-error code of this error
+const ErrCodeTaskIsBlocked = 4024
+
+// HTTPError holds the http error description
+func (err ErrTaskIsBlocked) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTaskIsBlocked,
+		Message:  fmt.Sprintf("The task cannot be marked as done while it is blocked by the open tasks %s.", err.blockers()),
+	}
+}
+
+// ErrChecklistItemDoesNotExist represents an error where a checklist item does not exist or does not belong to a task.
+type ErrChecklistItemDoesNotExist struct {
+	ID     int64
+	TaskID int64
+}
+
+// IsErrChecklistItemDoesNotExist checks if an error is ErrChecklistItemDoesNotExist.
+func IsErrChecklistItemDoesNotExist(err error) bool {
+	_, ok := err.(ErrChecklistItemDoesNotExist)
+	return ok
+}
+
+func (err ErrChecklistItemDoesNotExist) Error() string {
+	return fmt.Sprintf("Checklist item does not exist [ID: %d, TaskID: %d]", err.ID, err.TaskID)
+}
+
+// ErrCodeChecklistItemDoesNotExist holds the unique world-error code of this error
+const ErrCodeChecklistItemDoesNotExist = 4025
+
+// HTTPError holds the http error description
+func (err ErrChecklistItemDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeChecklistItemDoesNotExist,
+		Message:  "This checklist item does not exist.",
+	}
+}
+
+// ErrInvalidBulkTaskOperation represents an error where a bulk task operation is unknown or misses its parameters
+type ErrInvalidBulkTaskOperation struct {
+	Operation BulkTaskOperationKind
+}
+
+// IsErrInvalidBulkTaskOperation checks if an error is ErrInvalidBulkTaskOperation.
+func IsErrInvalidBulkTaskOperation(err error) bool {
+	_, ok := err.(ErrInvalidBulkTaskOperation)
+	return ok
+}
+
+func (err ErrInvalidBulkTaskOperation) Error() string {
+	return fmt.Sprintf("Bulk task operation is invalid [Operation: %s]", err.Operation)
+}
+
+// ErrCodeInvalidBulkTaskOperation holds the unique world-error code of this error
+const ErrCodeInvalidBulkTaskOperation = 4026
+
+// HTTPError holds the http error description
+func (err ErrInvalidBulkTaskOperation) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidBulkTaskOperation,
+		Message:  "The bulk operation does not exist or is missing its parameters.",
+	}
+}
+
 // ============
 // Team errors
 // ============
@@ -1190,6 +1313,54 @@ func (err ErrUserDoesNotHaveAccessToProject) HTTPError() web.HTTPError {
 	return web.HTTPError{HTTPCode: http.StatusForbidden, Code: ErrCodeUserDoesNotHaveAccessToProject, Message: "This user does not have access to the project."}
 }
 
+// ErrUserAlreadyHasAccessToTask represents an error where a user already has access to a task, either directly or through its project
+type ErrUserAlreadyHasAccessToTask struct {
+	UserID int64
+	TaskID int64
+}
+
+// IsErrUserAlreadyHasAccessToTask checks if an error is ErrUserAlreadyHasAccessToTask.
+func IsErrUserAlreadyHasAccessToTask(err error) bool {
+	_, ok := err.(ErrUserAlreadyHasAccessToTask)
+	return ok
+}
+
+func (err ErrUserAlreadyHasAccessToTask) Error() string {
+	return fmt.Sprintf("User already has access to that task. [User ID: %d, Task ID: %d]", err.UserID, err.TaskID)
+}
+
+// ErrCodeUserAlreadyHasAccessToTask holds the unique world-error code of this error
+const ErrCodeUserAlreadyHasAccessToTask = 7004
+
+// HTTPError holds the http error description
+func (err ErrUserAlreadyHasAccessToTask) HTTPError() web.HTTPError {
+	return web.HTTPError{HTTPCode: http.StatusConflict, Code: ErrCodeUserAlreadyHasAccessToTask, Message: "This user already has access to this task."}
+}
+
+// ErrUserDoesNotHaveAccessToTask represents an error, where a task was not shared with a user
+type ErrUserDoesNotHaveAccessToTask struct {
+	TaskID int64
+	UserID int64
+}
+
+// IsErrUserDoesNotHaveAccessToTask checks if an error is ErrUserDoesNotHaveAccessToTask.
+func IsErrUserDoesNotHaveAccessToTask(err error) bool {
+	_, ok := err.(ErrUserDoesNotHaveAccessToTask)
+	return ok
+}
+
+func (err ErrUserDoesNotHaveAccessToTask) Error() string {
+	return fmt.Sprintf("User does not have access to the task [TaskID: %d, UserID: %d]", err.TaskID, err.UserID)
+}
+
+// ErrCodeUserDoesNotHaveAccessToTask holds the unique world-error code of this error
+const ErrCodeUserDoesNotHaveAccessToTask = 7005
+
+// HTTPError holds the http error description
+func (err ErrUserDoesNotHaveAccessToTask) HTTPError() web.HTTPError {
+	return web.HTTPError{HTTPCode: http.StatusForbidden, Code: ErrCodeUserDoesNotHaveAccessToTask, Message: "This user does not have access to the task."}
+}
+
 // =============
 // Label errors
 // =============
@@ -1656,6 +1827,87 @@ func (err ErrLinkShareTokenInvalid) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrLinkShareExpired represents an error where a link share is used after its expiry date
+type ErrLinkShareExpired struct {
+	ShareID int64
+}
+
+// IsErrLinkShareExpired checks if an error is ErrLinkShareExpired.
+func IsErrLinkShareExpired(err error) bool {
+	_, ok := err.(*ErrLinkShareExpired)
+	return ok
+}
+
+func (err *ErrLinkShareExpired) Error() string {
+	return fmt.Sprintf("Link Share has expired [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareExpired holds the unique world-error code of this error
+const ErrCodeLinkShareExpired = 13004
+
+// HTTPError holds the http error description
+func (err ErrLinkShareExpired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareExpired,
+		Message:  "This link share has expired.",
+	}
+}
+
+// ErrLinkShareMaxUsesReached represents an error where a link share was used more often than allowed
+type ErrLinkShareMaxUsesReached struct {
+	ShareID int64
+}
+
+// IsErrLinkShareMaxUsesReached checks if an error is ErrLinkShareMaxUsesReached.
+func IsErrLinkShareMaxUsesReached(err error) bool {
+	_, ok := err.(*ErrLinkShareMaxUsesReached)
+	return ok
+}
+
+func (err *ErrLinkShareMaxUsesReached) Error() string {
+	return fmt.Sprintf("Link Share has reached its maximum number of uses [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareMaxUsesReached holds the unique world-error code of this error
+const ErrCodeLinkShareMaxUsesReached = 13005
+
+// HTTPError holds the http error description
+func (err ErrLinkShareMaxUsesReached) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareMaxUsesReached,
+		Message:  "This link share has reached its maximum number of uses.",
+	}
+}
+
+// ErrLinkShareDisabled represents an error where a disabled link share is used
+type ErrLinkShareDisabled struct {
+	ShareID int64
+}
+
+// IsErrLinkShareDisabled checks if an error is ErrLinkShareDisabled.
+func IsErrLinkShareDisabled(err error) bool {
+	_, ok := err.(*ErrLinkShareDisabled)
+	return ok
+}
+
+func (err *ErrLinkShareDisabled) Error() string {
+	return fmt.Sprintf("Link Share is disabled [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareDisabled holds the unique world-error code of this error
+const ErrCodeLinkShareDisabled = 13006
+
+// HTTPError holds the http error description
+func (err ErrLinkShareDisabled) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareDisabled,
+		Message:  "This link share is disabled.",
+	}
+}
+
 // ================
 // API Token Errors
 // ================
@@ -1713,3 +1965,596 @@ func (err ErrInvalidAPITokenPermission) HTTPError() web.HTTPError {
 		Message:  fmt.Sprintf("The permission %s of group %s is invalid.", err.Permission, err.Group),
 	}
 }
+
+// =================================
+// Notification Preference Errors
+// =================================
+
+// ErrInvalidNotificationName represents an error where a notification preference is for a notification which cannot be configured
+type ErrInvalidNotificationName struct {
+	Name string
+}
+
+// IsErrInvalidNotificationName checks if an error is ErrInvalidNotificationName.
+func IsErrInvalidNotificationName(err error) bool {
+	_, ok := err.(*ErrInvalidNotificationName)
+	return ok
+}
+
+func (err *ErrInvalidNotificationName) Error() string {
+	return fmt.Sprintf("Notification name is invalid [Name: %s]", err.Name)
+}
+
+// ErrCodeInvalidNotificationName holds the unique world-error code of this error
+const ErrCodeInvalidNotificationName = 15001
+
+// HTTPError holds the http error description
+func (err ErrInvalidNotificationName) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidNotificationName,
+		Message:  fmt.Sprintf("The notification %s does not exist or cannot be configured.", err.Name),
+	}
+}
+
+// ErrNotificationPreferenceDoesNotExist represents an error where a notification preference does not exist
+type ErrNotificationPreferenceDoesNotExist struct {
+	ID int64
+}
+
+// IsErrNotificationPreferenceDoesNotExist checks if an error is ErrNotificationPreferenceDoesNotExist.
+func IsErrNotificationPreferenceDoesNotExist(err error) bool {
+	_, ok := err.(*ErrNotificationPreferenceDoesNotExist)
+	return ok
+}
+
+func (err *ErrNotificationPreferenceDoesNotExist) Error() string {
+	return fmt.Sprintf("Notification preference does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeNotificationPreferenceDoesNotExist holds the unique world-error code of this error
+const ErrCodeNotificationPreferenceDoesNotExist = 15002
+
+// HTTPError holds the http error description
+func (err ErrNotificationPreferenceDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeNotificationPreferenceDoesNotExist,
+		Message:  "The notification preference does not exist.",
+	}
+}
+
+// ErrInvalidPushTarget represents an error where a push target is incomplete or its type is not available
+type ErrInvalidPushTarget struct {
+	Type   string
+	Reason string
+}
+
+// IsErrInvalidPushTarget checks if an error is ErrInvalidPushTarget.
+func IsErrInvalidPushTarget(err error) bool {
+	_, ok := err.(*ErrInvalidPushTarget)
+	return ok
+}
+
+func (err *ErrInvalidPushTarget) Error() string {
+	return fmt.Sprintf("Push target is invalid [Type: %s, Reason: %s]", err.Type, err.Reason)
+}
+
+// ErrCodeInvalidPushTarget holds the unique world-error code of this error
+const ErrCodeInvalidPushTarget = 15003
+
+// HTTPError holds the http error description
+func (err ErrInvalidPushTarget) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidPushTarget,
+		Message:  "The push target is invalid: " + err.Reason,
+	}
+}
+
+// ErrPushTargetDoesNotExist represents an error where a push target does not exist
+type ErrPushTargetDoesNotExist struct {
+	ID int64
+}
+
+// IsErrPushTargetDoesNotExist checks if an error is ErrPushTargetDoesNotExist.
+func IsErrPushTargetDoesNotExist(err error) bool {
+	_, ok := err.(*ErrPushTargetDoesNotExist)
+	return ok
+}
+
+func (err *ErrPushTargetDoesNotExist) Error() string {
+	return fmt.Sprintf("Push target does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodePushTargetDoesNotExist holds the unique world-error code of this error
+const ErrCodePushTargetDoesNotExist = 15004
+
+// HTTPError holds the http error description
+func (err ErrPushTargetDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodePushTargetDoesNotExist,
+		Message:  "The push target does not exist.",
+	}
+}
+
+// ErrNotificationCannotBeSnoozed represents an error where a notification which is not a reminder is snoozed
+type ErrNotificationCannotBeSnoozed struct {
+	ID int64
+}
+
+// IsErrNotificationCannotBeSnoozed checks if an error is ErrNotificationCannotBeSnoozed.
+func IsErrNotificationCannotBeSnoozed(err error) bool {
+	_, ok := err.(*ErrNotificationCannotBeSnoozed)
+	return ok
+}
+
+func (err *ErrNotificationCannotBeSnoozed) Error() string {
+	return fmt.Sprintf("Notification cannot be snoozed [ID: %d]", err.ID)
+}
+
+// ErrCodeNotificationCannotBeSnoozed holds the unique world-error code of this error
+const ErrCodeNotificationCannotBeSnoozed = 15005
+
+// HTTPError holds the http error description
+func (err ErrNotificationCannotBeSnoozed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeNotificationCannotBeSnoozed,
+		Message:  "Only reminder notifications can be snoozed.",
+	}
+}
+
+// ErrInvalidReminderSnooze represents an error where a reminder is snoozed without a duration
+type ErrInvalidReminderSnooze struct{}
+
+// IsErrInvalidReminderSnooze checks if an error is ErrInvalidReminderSnooze.
+func IsErrInvalidReminderSnooze(err error) bool {
+	_, ok := err.(*ErrInvalidReminderSnooze)
+	return ok
+}
+
+func (err *ErrInvalidReminderSnooze) Error() string {
+	return "Reminder snooze needs a duration"
+}
+
+// ErrCodeInvalidReminderSnooze holds the unique world-error code of this error
+const ErrCodeInvalidReminderSnooze = 15006
+
+// HTTPError holds the http error description
+func (err ErrInvalidReminderSnooze) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidReminderSnooze,
+		Message:  "You need to snooze a reminder for at least one minute or until tomorrow.",
+	}
+}
+
+// ====================
+// Inbound Email Errors
+// ====================
+
+// ErrInboundEmailNotEnabled represents an error where inbound email is not enabled
+type ErrInboundEmailNotEnabled struct{}
+
+// IsErrInboundEmailNotEnabled checks if an error is ErrInboundEmailNotEnabled.
+func IsErrInboundEmailNotEnabled(err error) bool {
+	_, ok := err.(*ErrInboundEmailNotEnabled)
+	return ok
+}
+
+func (err *ErrInboundEmailNotEnabled) Error() string {
+	return "Inbound email is not enabled"
+}
+
+// ErrCodeInboundEmailNotEnabled holds the unique world-error code of this error
+const ErrCodeInboundEmailNotEnabled = 16001
+
+// HTTPError holds the http error description
+func (err ErrInboundEmailNotEnabled) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInboundEmailNotEnabled,
+		Message:  "Inbound email is not enabled on this instance.",
+	}
+}
+
+// ErrInboundEmailRecipientDoesNotExist represents an error where an email was sent to an address which does not
+// belong to a project or reply token
+type ErrInboundEmailRecipientDoesNotExist struct {
+	Recipient string
+}
+
+// IsErrInboundEmailRecipientDoesNotExist checks if an error is ErrInboundEmailRecipientDoesNotExist.
+func IsErrInboundEmailRecipientDoesNotExist(err error) bool {
+	_, ok := err.(*ErrInboundEmailRecipientDoesNotExist)
+	return ok
+}
+
+func (err *ErrInboundEmailRecipientDoesNotExist) Error() string {
+	return fmt.Sprintf("Inbound email recipient does not exist [Recipient: %s]", err.Recipient)
+}
+
+// ErrCodeInboundEmailRecipientDoesNotExist holds the unique world-error code of this error
+const ErrCodeInboundEmailRecipientDoesNotExist = 16002
+
+// HTTPError holds the http error description
+func (err ErrInboundEmailRecipientDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeInboundEmailRecipientDoesNotExist,
+		Message:  "The recipient address does not exist.",
+	}
+}
+
+// ErrInboundEmailSenderNotAllowed represents an error where the sender of a reply is not allowed to comment on the task
+type ErrInboundEmailSenderNotAllowed struct {
+	Sender string
+	TaskID int64
+}
+
+// IsErrInboundEmailSenderNotAllowed checks if an error is ErrInboundEmailSenderNotAllowed.
+func IsErrInboundEmailSenderNotAllowed(err error) bool {
+	_, ok := err.(*ErrInboundEmailSenderNotAllowed)
+	return ok
+}
+
+func (err *ErrInboundEmailSenderNotAllowed) Error() string {
+	return fmt.Sprintf("Inbound email sender is not allowed to comment [Sender: %s, TaskID: %d]", err.Sender, err.TaskID)
+}
+
+// ErrCodeInboundEmailSenderNotAllowed holds the unique world-error code of this error
+const ErrCodeInboundEmailSenderNotAllowed = 16003
+
+// HTTPError holds the http error description
+func (err ErrInboundEmailSenderNotAllowed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeInboundEmailSenderNotAllowed,
+		Message:  "The sender is not allowed to comment on this task.",
+	}
+}
+
+// ================
+// Template Errors
+// ================
+
+// ErrProjectTemplateDoesNotExist represents an error where a project template does not exist
+type ErrProjectTemplateDoesNotExist struct {
+	TemplateID int64
+}
+
+// IsErrProjectTemplateDoesNotExist checks if an error is ErrProjectTemplateDoesNotExist.
+func IsErrProjectTemplateDoesNotExist(err error) bool {
+	_, ok := err.(ErrProjectTemplateDoesNotExist)
+	return ok
+}
+
+func (err ErrProjectTemplateDoesNotExist) Error() string {
+	return fmt.Sprintf("Project template does not exist [TemplateID: %d]", err.TemplateID)
+}
+
+// ErrCodeProjectTemplateDoesNotExist holds the unique world-error code of this error
+const ErrCodeProjectTemplateDoesNotExist = 17001
+
+// HTTPError holds the http error description
+func (err ErrProjectTemplateDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeProjectTemplateDoesNotExist,
+		Message:  "This project template does not exist.",
+	}
+}
+
+// ErrTaskTemplateDoesNotExist represents an error where a task template does not exist
+type ErrTaskTemplateDoesNotExist struct {
+	TemplateID int64
+}
+
+// IsErrTaskTemplateDoesNotExist checks if an error is ErrTaskTemplateDoesNotExist.
+func IsErrTaskTemplateDoesNotExist(err error) bool {
+	_, ok := err.(ErrTaskTemplateDoesNotExist)
+	return ok
+}
+
+func (err ErrTaskTemplateDoesNotExist) Error() string {
+	return fmt.Sprintf("Task template does not exist [TemplateID: %d]", err.TemplateID)
+}
+
+// ErrCodeTaskTemplateDoesNotExist holds the unique world-error code of this error
+const ErrCodeTaskTemplateDoesNotExist = 17002
+
+// HTTPError holds the http error description
+func (err ErrTaskTemplateDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTaskTemplateDoesNotExist,
+		Message:  "This task template does not exist.",
+	}
+}
+
+// ErrTemplateNotAvailableForLinkShare represents an error where a link share tries to use templates
+type ErrTemplateNotAvailableForLinkShare struct {
+	LinkShareID int64
+}
+
+// IsErrTemplateNotAvailableForLinkShare checks if an error is ErrTemplateNotAvailableForLinkShare.
+func IsErrTemplateNotAvailableForLinkShare(err error) bool {
+	_, ok := err.(ErrTemplateNotAvailableForLinkShare)
+	return ok
+}
+
+func (err ErrTemplateNotAvailableForLinkShare) Error() string {
+	return fmt.Sprintf("Templates are not available for link shares [LinkShareID: %d]", err.LinkShareID)
+}
+
+// ErrCodeTemplateNotAvailableForLinkShare holds the unique world-error code of this error
+const ErrCodeTemplateNotAvailableForLinkShare = 17003
+
+// HTTPError holds the http error description
+func (err ErrTemplateNotAvailableForLinkShare) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTemplateNotAvailableForLinkShare,
+		Message:  "Templates are not available for link shares.",
+	}
+}
+
+// =============
+// Trash Errors
+// =============
+
+// ErrTrashedItemDoesNotExist represents an error where an item in the trash does not exist
+type ErrTrashedItemDoesNotExist struct {
+	ID int64
+}
+
+// IsErrTrashedItemDoesNotExist checks if an error is ErrTrashedItemDoesNotExist.
+func IsErrTrashedItemDoesNotExist(err error) bool {
+	_, ok := err.(ErrTrashedItemDoesNotExist)
+	return ok
+}
+
+func (err ErrTrashedItemDoesNotExist) Error() string {
+	return fmt.Sprintf("Trashed item does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeTrashedItemDoesNotExist holds the unique world-error code of this error
+const ErrCodeTrashedItemDoesNotExist = 18001
+
+// HTTPError holds the http error description
+func (err ErrTrashedItemDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTrashedItemDoesNotExist,
+		Message:  "This item does not exist in the trash.",
+	}
+}
+
+// ErrTrashNotAvailableForLinkShare represents an error where a link share tries to access the trash
+type ErrTrashNotAvailableForLinkShare struct {
+	LinkShareID int64
+}
+
+// IsErrTrashNotAvailableForLinkShare checks if an error is ErrTrashNotAvailableForLinkShare.
+func IsErrTrashNotAvailableForLinkShare(err error) bool {
+	_, ok := err.(ErrTrashNotAvailableForLinkShare)
+	return ok
+}
+
+func (err ErrTrashNotAvailableForLinkShare) Error() string {
+	return fmt.Sprintf("The trash is not available for link shares [LinkShareID: %d]", err.LinkShareID)
+}
+
+// ErrCodeTrashNotAvailableForLinkShare holds the unique world-error code of this error
+const ErrCodeTrashNotAvailableForLinkShare = 18002
+
+// HTTPError holds the http error description
+func (err ErrTrashNotAvailableForLinkShare) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTrashNotAvailableForLinkShare,
+		Message:  "The trash is not available for link shares.",
+	}
+}
+
+// ================
+// Milestone Errors
+// ================
+
+// ErrMilestoneDoesNotExist represents an error where a milestone does not exist
+type ErrMilestoneDoesNotExist struct {
+	MilestoneID int64
+}
+
+// IsErrMilestoneDoesNotExist checks if an error is ErrMilestoneDoesNotExist.
+func IsErrMilestoneDoesNotExist(err error) bool {
+	_, ok := err.(ErrMilestoneDoesNotExist)
+	return ok
+}
+
+func (err ErrMilestoneDoesNotExist) Error() string {
+	return fmt.Sprintf("Milestone does not exist [MilestoneID: %d]", err.MilestoneID)
+}
+
+// ErrCodeMilestoneDoesNotExist holds the unique world-error code of this error
+const ErrCodeMilestoneDoesNotExist = 19001
+
+// HTTPError holds the http error description
+func (err ErrMilestoneDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeMilestoneDoesNotExist,
+		Message:  "This milestone does not exist.",
+	}
+}
+
+// ErrMilestoneDoesNotBelongToProject represents an error where a task should be added to a milestone of another project
+type ErrMilestoneDoesNotBelongToProject struct {
+	MilestoneID int64
+	ProjectID   int64
+}
+
+// IsErrMilestoneDoesNotBelongToProject checks if an error is ErrMilestoneDoesNotBelongToProject.
+func IsErrMilestoneDoesNotBelongToProject(err error) bool {
+	_, ok := err.(ErrMilestoneDoesNotBelongToProject)
+	return ok
+}
+
+func (err ErrMilestoneDoesNotBelongToProject) Error() string {
+	return fmt.Sprintf("Milestone does not belong to project [MilestoneID: %d, ProjectID: %d]", err.MilestoneID, err.ProjectID)
+}
+
+// ErrCodeMilestoneDoesNotBelongToProject holds the unique world-error code of this error
+const ErrCodeMilestoneDoesNotBelongToProject = 19002
+
+// HTTPError holds the http error description
+func (err ErrMilestoneDoesNotBelongToProject) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeMilestoneDoesNotBelongToProject,
+		Message:  "This milestone belongs to another project than the task.",
+	}
+}
+
+// ErrMilestoneNotAvailableForLinkShare represents an error where a link share tries to access a cross-project milestone
+type ErrMilestoneNotAvailableForLinkShare struct {
+	LinkShareID int64
+}
+
+// IsErrMilestoneNotAvailableForLinkShare checks if an error is ErrMilestoneNotAvailableForLinkShare.
+func IsErrMilestoneNotAvailableForLinkShare(err error) bool {
+	_, ok := err.(ErrMilestoneNotAvailableForLinkShare)
+	return ok
+}
+
+func (err ErrMilestoneNotAvailableForLinkShare) Error() string {
+	return fmt.Sprintf("Cross-project milestones are not available for link shares [LinkShareID: %d]", err.LinkShareID)
+}
+
+// ErrCodeMilestoneNotAvailableForLinkShare holds the unique world-error code of this error
+const ErrCodeMilestoneNotAvailableForLinkShare = 19003
+
+// HTTPError holds the http error description
+func (err ErrMilestoneNotAvailableForLinkShare) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeMilestoneNotAvailableForLinkShare,
+		Message:  "Cross-project milestones are not available for link shares.",
+	}
+}
+
+// =============
+// Sprint Errors
+// =============
+
+// ErrSprintDoesNotExist represents an error where a sprint does not exist
+type ErrSprintDoesNotExist struct {
+	SprintID int64
+}
+
+// IsErrSprintDoesNotExist checks if an error is ErrSprintDoesNotExist.
+func IsErrSprintDoesNotExist(err error) bool {
+	_, ok := err.(ErrSprintDoesNotExist)
+	return ok
+}
+
+func (err ErrSprintDoesNotExist) Error() string {
+	return fmt.Sprintf("Sprint does not exist [SprintID: %d]", err.SprintID)
+}
+
+// ErrCodeSprintDoesNotExist holds the unique world-error code of this error
+const ErrCodeSprintDoesNotExist = 20001
+
+// HTTPError holds the http error description
+func (err ErrSprintDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeSprintDoesNotExist,
+		Message:  "This sprint does not exist.",
+	}
+}
+
+// ErrInvalidSprintDates represents an error where the end date of a sprint is not after its start date
+type ErrInvalidSprintDates struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// IsErrInvalidSprintDates checks if an error is ErrInvalidSprintDates.
+func IsErrInvalidSprintDates(err error) bool {
+	_, ok := err.(ErrInvalidSprintDates)
+	return ok
+}
+
+func (err ErrInvalidSprintDates) Error() string {
+	return fmt.Sprintf("Invalid sprint dates [StartDate: %s, EndDate: %s]", err.StartDate, err.EndDate)
+}
+
+// ErrCodeInvalidSprintDates holds the unique world-error code of this error
+const ErrCodeInvalidSprintDates = 20002
+
+// HTTPError holds the http error description
+func (err ErrInvalidSprintDates) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidSprintDates,
+		Message:  "A sprint needs a start and an end date and the end date must be after the start date.",
+	}
+}
+
+// ErrSprintIsClosed represents an error where a closed sprint should be changed
+type ErrSprintIsClosed struct {
+	SprintID int64
+}
+
+// IsErrSprintIsClosed checks if an error is ErrSprintIsClosed.
+func IsErrSprintIsClosed(err error) bool {
+	_, ok := err.(ErrSprintIsClosed)
+	return ok
+}
+
+func (err ErrSprintIsClosed) Error() string {
+	return fmt.Sprintf("Sprint is closed [SprintID: %d]", err.SprintID)
+}
+
+// ErrCodeSprintIsClosed holds the unique world-error code of this error
+const ErrCodeSprintIsClosed = 20003
+
+// HTTPError holds the http error description
+func (err ErrSprintIsClosed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeSprintIsClosed,
+		Message:  "This sprint is closed.",
+	}
+}
+
+// ErrSprintDoesNotBelongToProject represents an error where a sprint of another project should be used
+type ErrSprintDoesNotBelongToProject struct {
+	SprintID  int64
+	ProjectID int64
+}
+
+// IsErrSprintDoesNotBelongToProject checks if an error is ErrSprintDoesNotBelongToProject.
+func IsErrSprintDoesNotBelongToProject(err error) bool {
+	_, ok := err.(ErrSprintDoesNotBelongToProject)
+	return ok
+}
+
+func (err ErrSprintDoesNotBelongToProject) Error() string {
+	return fmt.Sprintf("Sprint does not belong to project [SprintID: %d, ProjectID: %d]", err.SprintID, err.ProjectID)
+}
+
+// ErrCodeSprintDoesNotBelongToProject holds the unique world-error code of this error
+const ErrCodeSprintDoesNotBelongToProject = 20004
+
+// HTTPError holds the http error description
+func (err ErrSprintDoesNotBelongToProject) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeSprintDoesNotBelongToProject,
+		Message:  "This sprint belongs to another project.",
+	}
+}
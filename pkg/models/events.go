@@ -58,6 +58,17 @@ func (t *TaskDeletedEvent) Name() string {
 	return "task.deleted"
 }
 
+// TaskRestoredEvent represents an event where a task has been restored from the trash
+type TaskRestoredEvent struct {
+	Task *Task
+	Doer *user.User
+}
+
+// Name defines the name for TaskRestoredEvent
+func (t *TaskRestoredEvent) Name() string {
+	return "task.restored"
+}
+
 // TaskAssigneeCreatedEvent represents an event where a task has been assigned to a user
 type TaskAssigneeCreatedEvent struct {
 	Task     *Task
@@ -166,6 +177,43 @@ func (t *TaskRelationDeletedEvent) Name() string {
 	return "task.relation.deleted"
 }
 
+///////////////////
+// Bucket Events //
+///////////////////
+
+// BucketCreatedEvent represents an event where a kanban bucket has been created
+type BucketCreatedEvent struct {
+	Bucket *Bucket
+	Doer   *user.User
+}
+
+// Name defines the name for BucketCreatedEvent
+func (b *BucketCreatedEvent) Name() string {
+	return "bucket.created"
+}
+
+// BucketUpdatedEvent represents an event where a kanban bucket has been updated
+type BucketUpdatedEvent struct {
+	Bucket *Bucket
+	Doer   *user.User
+}
+
+// Name defines the name for BucketUpdatedEvent
+func (b *BucketUpdatedEvent) Name() string {
+	return "bucket.updated"
+}
+
+// BucketDeletedEvent represents an event where a kanban bucket has been deleted
+type BucketDeletedEvent struct {
+	Bucket *Bucket
+	Doer   *user.User
+}
+
+// Name defines the name for BucketDeletedEvent
+func (b *BucketDeletedEvent) Name() string {
+	return "bucket.deleted"
+}
+
 ////////////////////
 // Project Events //
 ////////////////////
@@ -203,6 +251,17 @@ func (t *ProjectDeletedEvent) Name() string {
 	return "project.deleted"
 }
 
+// ProjectRestoredEvent represents an event where a project has been restored from the trash
+type ProjectRestoredEvent struct {
+	Project *Project
+	Doer    web.Auth
+}
+
+// Name defines the name for ProjectRestoredEvent
+func (t *ProjectRestoredEvent) Name() string {
+	return "project.restored"
+}
+
 ////////////////////
 // Sharing Events //
 ////////////////////
@@ -0,0 +1,479 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// InboundEmail is a received email which creates a task or a comment.
+type InboundEmail struct {
+	// The address of the sender.
+	From string
+	// The display name of the sender, if any.
+	FromName string
+	Subject  string
+	// The plain text content of the email.
+	Text        string
+	Attachments []*InboundEmailAttachment
+	// Whether the mail server verified that the email was actually sent from the domain of the sender.
+	// Only verified senders are treated as the author of the task they create.
+	SenderVerified bool
+}
+
+// InboundEmailAttachment is a file attached to a received email.
+type InboundEmailAttachment struct {
+	Filename string
+	Content  []byte
+}
+
+const (
+	inboundEmailProjectPrefix = "project-"
+	inboundEmailReplyPrefix   = "reply-"
+)
+
+func inboundEmailEnabled() bool {
+	return config.InboundEnabled.GetBool() && config.InboundDomain.GetString() != ""
+}
+
+func getInboundEmailAddress(localPart string) string {
+	return localPart + "@" + config.InboundDomain.GetString()
+}
+
+// getInboundEmailLocalPart returns the local part of an address if it belongs to the configured inbound domain.
+func getInboundEmailLocalPart(address string) (localPart string, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if at < 1 {
+		return "", false
+	}
+	if !strings.EqualFold(address[at+1:], config.InboundDomain.GetString()) {
+		return "", false
+	}
+	return strings.ToLower(address[:at]), true
+}
+
+func getReplySignature(userID, taskID int64) string {
+	mac := hmac.New(sha256.New, []byte(config.ServiceJWTSecret.GetString()))
+	_, _ = fmt.Fprintf(mac, "inbound-reply:%d:%d", userID, taskID)
+	return hex.EncodeToString(mac.Sum(nil))[:24]
+}
+
+// GetReplyAddress returns the address a user can send an email to in order to comment on a task.
+// Returns an empty string if inbound email is not enabled.
+func GetReplyAddress(userID, taskID int64) string {
+	if !inboundEmailEnabled() {
+		return ""
+	}
+
+	return getInboundEmailAddress(fmt.Sprintf("%s%d-%d-%s", inboundEmailReplyPrefix, userID, taskID, getReplySignature(userID, taskID)))
+}
+
+// parseReplyAddress returns the user and task a reply address was created for.
+func parseReplyAddress(localPart string) (userID, taskID int64, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(localPart, inboundEmailReplyPrefix), "-")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	taskID, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(getReplySignature(userID, taskID))) {
+		return 0, 0, false
+	}
+
+	return userID, taskID, true
+}
+
+// IsInboundEmailRecipient checks if Vikunja accepts emails for an address. It only checks the format of the
+// address, not if the project or task it belongs to exists.
+func IsInboundEmailRecipient(address string) bool {
+	if !inboundEmailEnabled() {
+		return false
+	}
+
+	localPart, ok := getInboundEmailLocalPart(address)
+	if !ok {
+		return false
+	}
+
+	return strings.HasPrefix(localPart, inboundEmailProjectPrefix) || strings.HasPrefix(localPart, inboundEmailReplyPrefix)
+}
+
+// ProcessInboundEmail creates a task if the email was sent to the address of a project or a comment if it was a
+// reply to a notification.
+func ProcessInboundEmail(s *xorm.Session, recipient string, email *InboundEmail) error {
+	if !inboundEmailEnabled() {
+		return &ErrInboundEmailNotEnabled{}
+	}
+
+	localPart, ok := getInboundEmailLocalPart(recipient)
+	if !ok {
+		return &ErrInboundEmailRecipientDoesNotExist{Recipient: recipient}
+	}
+
+	switch {
+	case strings.HasPrefix(localPart, inboundEmailProjectPrefix):
+		hash := strings.TrimPrefix(localPart, inboundEmailProjectPrefix)
+		project := &Project{}
+		exists, err := s.Where("inbound_email_hash = ?", hash).Get(project)
+		if err != nil {
+			return err
+		}
+		if !exists || hash == "" {
+			return &ErrInboundEmailRecipientDoesNotExist{Recipient: recipient}
+		}
+		return createTaskFromInboundEmail(s, project, email)
+	case strings.HasPrefix(localPart, inboundEmailReplyPrefix):
+		userID, taskID, ok := parseReplyAddress(localPart)
+		if !ok {
+			return &ErrInboundEmailRecipientDoesNotExist{Recipient: recipient}
+		}
+		return createCommentFromInboundEmail(s, userID, taskID, email)
+	}
+
+	return &ErrInboundEmailRecipientDoesNotExist{Recipient: recipient}
+}
+
+// inboundEmailShareName is the name of the link share which is the author of tasks from unverified senders.
+const inboundEmailShareName = "Email"
+
+// getInboundEmailShare returns the link share of a project which is used as a neutral author for tasks created
+// from emails. It is created on first use and disabled so that it cannot be used to access the project.
+func getInboundEmailShare(s *xorm.Session, project *Project) (share *LinkSharing, err error) {
+	share = &LinkSharing{}
+	exists, err := s.
+		Where("project_id = ? AND task_id = 0 AND name = ? AND disabled = ?", project.ID, inboundEmailShareName, true).
+		Get(share)
+	if err != nil || exists {
+		return share, err
+	}
+
+	share = &LinkSharing{
+		Hash:        utils.MakeRandomString(40),
+		ProjectID:   project.ID,
+		Name:        inboundEmailShareName,
+		Right:       RightWrite,
+		SharingType: SharingTypeWithoutPassword,
+		SharedByID:  project.OwnerID,
+		Disabled:    true,
+	}
+	_, err = s.Insert(share)
+	return share, err
+}
+
+// getInboundEmailAuthor returns the user who sent an email if the mail server verified the sender and they can
+// create tasks in the project. Otherwise, the task is created in the name of the project's email link share.
+// The From header of an email can be set to anything, so it is not enough to attribute a task to a user.
+func getInboundEmailAuthor(s *xorm.Session, project *Project, email *InboundEmail) (author web.Auth, isSender bool, err error) {
+	if email.SenderVerified && email.From != "" {
+		sender, err := user.GetUserWithEmail(s, &user.User{Email: email.From})
+		if err != nil && !user.IsErrUserDoesNotExist(err) {
+			return nil, false, err
+		}
+		if err == nil && sender.Status != user.StatusDisabled {
+			can, err := project.CanWrite(s, sender)
+			if err != nil {
+				return nil, false, err
+			}
+			if can {
+				return sender, true, nil
+			}
+		}
+	}
+
+	author, err = getInboundEmailShare(s, project)
+	return author, false, err
+}
+
+func createTaskFromInboundEmail(s *xorm.Session, project *Project, email *InboundEmail) error {
+	author, isSender, err := getInboundEmailAuthor(s, project, email)
+	if err != nil {
+		return err
+	}
+
+	title := strings.TrimSpace(email.Subject)
+	for _, prefix := range []string{"fwd:", "fw:"} {
+		if strings.HasPrefix(strings.ToLower(title), prefix) {
+			title = strings.TrimSpace(title[len(prefix):])
+		}
+	}
+	if title == "" {
+		title = "Email from " + email.From
+	}
+	if runes := []rune(title); len(runes) > 250 {
+		title = string(runes[:250])
+	}
+
+	description := inboundEmailTextToHTML(email.Text)
+	if !isSender {
+		sender := email.From
+		if email.FromName != "" {
+			sender = email.FromName + " <" + email.From + ">"
+		}
+		description = "<p>Sent by " + html.EscapeString(sender) + "</p>" + description
+	}
+
+	task := &Task{
+		Title:       title,
+		Description: description,
+		ProjectID:   project.ID,
+	}
+	err = task.Create(s, author)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Created task %d in project %d from inbound email", task.ID, project.ID)
+
+	return addInboundEmailAttachments(s, task.ID, email, author)
+}
+
+func createCommentFromInboundEmail(s *xorm.Session, userID, taskID int64, email *InboundEmail) error {
+	u, err := user.GetUserWithEmail(s, &user.User{ID: userID})
+	if err != nil {
+		return err
+	}
+
+	// The reply address is only valid when used by the user it was created for
+	if !strings.EqualFold(u.Email, email.From) || u.Status == user.StatusDisabled {
+		return &ErrInboundEmailSenderNotAllowed{Sender: email.From, TaskID: taskID}
+	}
+
+	task := &Task{ID: taskID}
+	can, err := task.CanComment(s, u)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return &ErrInboundEmailSenderNotAllowed{Sender: email.From, TaskID: taskID}
+	}
+
+	text := stripQuotedReply(email.Text)
+	if text != "" {
+		comment := &TaskComment{
+			TaskID:  taskID,
+			Comment: inboundEmailTextToHTML(text),
+		}
+		err = comment.Create(s, u)
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("Created comment %d on task %d from inbound email", comment.ID, taskID)
+	}
+
+	return addInboundEmailAttachments(s, taskID, email, u)
+}
+
+func addInboundEmailAttachments(s *xorm.Session, taskID int64, email *InboundEmail, author web.Auth) error {
+	for _, attachment := range email.Attachments {
+		ta := &TaskAttachment{TaskID: taskID}
+		err := ta.NewAttachment(s, io.NopCloser(bytes.NewReader(attachment.Content)), attachment.Filename, uint64(len(attachment.Content)), author)
+		if IsErrTaskAttachmentIsTooLarge(err) {
+			log.Warningf("Skipping attachment %s of inbound email for task %d because it is too large", attachment.Filename, taskID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stripQuotedReply removes the quoted original message from a reply, keeping only what was written above it.
+func stripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	kept := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, ">") ||
+			trimmed == "-- " || trimmed == "--" ||
+			strings.HasPrefix(trimmed, "-----Original Message-----") ||
+			(strings.HasPrefix(trimmed, "On ") && strings.HasSuffix(trimmed, "wrote:")) {
+			break
+		}
+		kept = append(kept, line)
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// inboundEmailTextToHTML converts the plain text of an email to the html used for descriptions and comments.
+func inboundEmailTextToHTML(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+
+	paragraphs := strings.Split(text, "\n\n")
+	var out strings.Builder
+	for _, p := range paragraphs {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		lines := strings.Split(p, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		out.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>")
+	}
+
+	return out.String()
+}
+
+// ProjectInboundEmail holds the address emails can be sent to in order to create tasks in a project.
+type ProjectInboundEmail struct {
+	// The project this address belongs to.
+	ProjectID int64 `xorm:"-" json:"-" param:"project"`
+	// The address. Every email sent to it creates a new task in the project. Empty if not enabled for the project.
+	Address string `xorm:"-" json:"address"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// ReadOne returns the inbound email address of a project
+// @Summary Get the inbound email address of a project
+// @Description Returns the address emails can be sent to in order to create tasks in the project. The subject becomes the title, the text the description and attachments are added to the task. If the mail server verified the sender and they are a user who can write to the project, they become the author. Otherwise the task is created by a disabled link share called "Email".
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 200 {object} models.ProjectInboundEmail "The inbound email address."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the project."
+// @Failure 412 {object} web.HTTPError "Inbound email is not enabled."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/inboundemail [get]
+func (pie *ProjectInboundEmail) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	if !inboundEmailEnabled() {
+		return &ErrInboundEmailNotEnabled{}
+	}
+
+	project, err := GetProjectSimpleByID(s, pie.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	pie.Address = ""
+	if project.InboundEmailHash != "" {
+		pie.Address = getInboundEmailAddress(inboundEmailProjectPrefix + project.InboundEmailHash)
+	}
+	return nil
+}
+
+// Create enables the inbound email address of a project
+// @Summary Create a new inbound email address for a project
+// @Description Creates a new address emails can be sent to in order to create tasks in the project. If the project already has an address, it is replaced and the old one stops working.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 201 {object} models.ProjectInboundEmail "The new inbound email address."
+// @Failure 403 {object} web.HTTPError "The user is not admin of the project."
+// @Failure 412 {object} web.HTTPError "Inbound email is not enabled."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/inboundemail [put]
+func (pie *ProjectInboundEmail) Create(s *xorm.Session, _ web.Auth) (err error) {
+	if !inboundEmailEnabled() {
+		return &ErrInboundEmailNotEnabled{}
+	}
+
+	hash := strings.ToLower(utils.MakeRandomString(32))
+	_, err = s.
+		Where("id = ?", pie.ProjectID).
+		Cols("inbound_email_hash").
+		NoAutoTime().
+		Update(&Project{InboundEmailHash: hash})
+	if err != nil {
+		return err
+	}
+
+	pie.Address = getInboundEmailAddress(inboundEmailProjectPrefix + hash)
+	return nil
+}
+
+// Delete disables the inbound email address of a project
+// @Summary Disable the inbound email address of a project
+// @Description Removes the inbound email address of a project. Emails sent to it will be rejected afterwards.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 200 {object} models.Message "The address was removed."
+// @Failure 403 {object} web.HTTPError "The user is not admin of the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/inboundemail [delete]
+func (pie *ProjectInboundEmail) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = s.
+		Where("id = ?", pie.ProjectID).
+		Cols("inbound_email_hash").
+		NoAutoTime().
+		Update(&Project{InboundEmailHash: ""})
+	return err
+}
+
+// CanRead checks if a user can see the inbound email address of a project
+func (pie *ProjectInboundEmail) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	can, err := (&Project{ID: pie.ProjectID}).CanWrite(s, a)
+	return can, int(RightWrite), err
+}
+
+// CanCreate checks if a user can create a new inbound email address for a project
+func (pie *ProjectInboundEmail) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	return (&Project{ID: pie.ProjectID}).IsAdmin(s, a)
+}
+
+// CanDelete checks if a user can remove the inbound email address of a project
+func (pie *ProjectInboundEmail) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return pie.CanCreate(s, a)
+}
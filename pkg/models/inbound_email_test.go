@@ -0,0 +1,249 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func enableInboundEmail(t *testing.T) {
+	config.InboundEnabled.Set(true)
+	config.InboundDomain.Set("inbound.example.com")
+	t.Cleanup(func() {
+		config.InboundEnabled.Set(false)
+		config.InboundDomain.Set("")
+	})
+}
+
+func TestProcessInboundEmail(t *testing.T) {
+	createProjectAddress := func(t *testing.T, projectID int64) string {
+		s := db.NewSession()
+		defer s.Close()
+
+		pie := &ProjectInboundEmail{ProjectID: projectID}
+		err := pie.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Commit())
+		return pie.Address
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ProcessInboundEmail(s, "project-abc@inbound.example.com", &InboundEmail{})
+		assert.Error(t, err)
+		assert.True(t, IsErrInboundEmailNotEnabled(err))
+	})
+	t.Run("task from project member", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+		address := createProjectAddress(t, 1)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ProcessInboundEmail(s, address, &InboundEmail{
+			From:    "user1@example.com",
+			Subject: "Fwd: Buy milk",
+			Text:    "Two liters\nof milk\n\nPlease <3",
+			Attachments: []*InboundEmailAttachment{
+				{Filename: "list.txt", Content: []byte("milk")},
+			},
+			SenderVerified: true,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Commit())
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":         "Buy milk",
+			"description":   "<p>Two liters<br>of milk</p><p>Please &lt;3</p>",
+			"project_id":    1,
+			"created_by_id": 1,
+		}, false)
+		db.AssertExists(t, "files", map[string]interface{}{
+			"name": "list.txt",
+			"size": 4,
+		}, false)
+	})
+	t.Run("task from unknown sender", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		address := createProjectAddress(t, 1)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ProcessInboundEmail(s, address, &InboundEmail{
+			From:     "someone@example.org",
+			FromName: "Someone",
+			Subject:  "Report",
+			Text:     "Something broke",
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Commit())
+
+		share := &LinkSharing{}
+		exists, err := s.Where("project_id = ? AND name = ?", 1, inboundEmailShareName).Get(share)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.True(t, share.Disabled)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":         "Report",
+			"description":   "<p>Sent by Someone &lt;someone@example.org&gt;</p><p>Something broke</p>",
+			"project_id":    1,
+			"created_by_id": share.getUserID(),
+		}, false)
+	})
+	t.Run("task from unverified project member", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		address := createProjectAddress(t, 1)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		// Anyone can put the address of a project member in the From header
+		email := &InboundEmail{
+			From:    "user1@example.com",
+			Subject: "Spoofed",
+			Text:    "Not from user 1",
+		}
+		err := ProcessInboundEmail(s, address, email)
+		assert.NoError(t, err)
+		err = ProcessInboundEmail(s, address, email)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Commit())
+
+		shares := []*LinkSharing{}
+		err = s.Where("project_id = ? AND name = ?", 1, inboundEmailShareName).Find(&shares)
+		assert.NoError(t, err)
+		assert.Len(t, shares, 1)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":         "Spoofed",
+			"description":   "<p>Sent by user1@example.com</p><p>Not from user 1</p>",
+			"project_id":    1,
+			"created_by_id": shares[0].getUserID(),
+		}, false)
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"title":         "Spoofed",
+			"created_by_id": 1,
+		})
+	})
+	t.Run("unknown project address", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ProcessInboundEmail(s, "project-doesnotexist@inbound.example.com", &InboundEmail{From: "user1@example.com"})
+		assert.Error(t, err)
+		assert.True(t, IsErrInboundEmailRecipientDoesNotExist(err))
+	})
+	t.Run("removed project address", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		address := createProjectAddress(t, 1)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&ProjectInboundEmail{ProjectID: 1}).Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+
+		err = ProcessInboundEmail(s, address, &InboundEmail{From: "user1@example.com"})
+		assert.Error(t, err)
+		assert.True(t, IsErrInboundEmailRecipientDoesNotExist(err))
+	})
+	t.Run("reply", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ProcessInboundEmail(s, GetReplyAddress(1, 1), &InboundEmail{
+			From: "User1@example.com",
+			Text: "Sounds good!\n\nOn Mon, 2 Oct 2023 at 10:00, Vikunja wrote:\n> The old comment",
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Commit())
+
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"task_id":   1,
+			"author_id": 1,
+			"comment":   "<p>Sounds good!</p>",
+		}, false)
+	})
+	t.Run("reply from a different sender", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ProcessInboundEmail(s, GetReplyAddress(1, 1), &InboundEmail{
+			From: "user2@example.com",
+			Text: "Sounds good!",
+		})
+		assert.Error(t, err)
+		assert.True(t, IsErrInboundEmailSenderNotAllowed(err))
+	})
+	t.Run("reply with a forged signature", func(t *testing.T) {
+		enableInboundEmail(t)
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		address := strings.Replace(GetReplyAddress(1, 1), "reply-1-1-", "reply-1-2-", 1)
+		err := ProcessInboundEmail(s, address, &InboundEmail{
+			From: "user1@example.com",
+			Text: "Sounds good!",
+		})
+		assert.Error(t, err)
+		assert.True(t, IsErrInboundEmailRecipientDoesNotExist(err))
+	})
+}
+
+func TestStripQuotedReply(t *testing.T) {
+	assert.Equal(t, "Hello", stripQuotedReply("Hello\r\n\r\n> quoted"))
+	assert.Equal(t, "Hello\nthere", stripQuotedReply("Hello\nthere\n-----Original Message-----\nold"))
+	assert.Equal(t, "Hello", stripQuotedReply("Hello\n-- \nSignature"))
+}
+
+func TestProjectInboundEmail_CanRead(t *testing.T) {
+	enableInboundEmail(t)
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	can, _, err := (&ProjectInboundEmail{ProjectID: 1}).CanRead(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+
+	can, _, err = (&ProjectInboundEmail{ProjectID: 1}).CanRead(s, &LinkSharing{ID: 1, ProjectID: 1, Right: RightAdmin})
+	assert.NoError(t, err)
+	assert.False(t, can)
+}
@@ -19,6 +19,7 @@ package models
 import (
 	"time"
 
+	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
@@ -259,7 +260,15 @@ func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
 
 	b.Position = calculateDefaultPosition(b.ID, b.Position)
 	_, err = s.Where("id = ?", b.ID).Update(b)
-	return
+	if err != nil {
+		return
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	return events.Dispatch(&BucketCreatedEvent{
+		Bucket: b,
+		Doer:   doer,
+	})
 }
 
 // Update Updates an existing bucket
@@ -277,7 +286,7 @@ func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 404 {object} web.HTTPError "The bucket does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{projectID}/buckets/{bucketID} [post]
-func (b *Bucket) Update(s *xorm.Session, _ web.Auth) (err error) {
+func (b *Bucket) Update(s *xorm.Session, a web.Auth) (err error) {
 	_, err = s.
 		Where("id = ?", b.ID).
 		Cols(
@@ -286,7 +295,15 @@ func (b *Bucket) Update(s *xorm.Session, _ web.Auth) (err error) {
 			"position",
 		).
 		Update(b)
-	return
+	if err != nil {
+		return
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	return events.Dispatch(&BucketUpdatedEvent{
+		Bucket: b,
+		Doer:   doer,
+	})
 }
 
 // Delete removes a bucket, but no tasks
@@ -302,7 +319,7 @@ func (b *Bucket) Update(s *xorm.Session, _ web.Auth) (err error) {
 // @Failure 404 {object} web.HTTPError "The bucket does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{projectID}/buckets/{bucketID} [delete]
-func (b *Bucket) Delete(s *xorm.Session, _ web.Auth) (err error) {
+func (b *Bucket) Delete(s *xorm.Session, a web.Auth) (err error) {
 
 	// Prevent removing the last bucket
 	total, err := s.Where("project_id = ?", b.ProjectID).Count(&Bucket{})
@@ -337,5 +354,13 @@ func (b *Bucket) Delete(s *xorm.Session, _ web.Auth) (err error) {
 		Where("bucket_id = ?", b.ID).
 		Cols("bucket_id").
 		Update(&Task{BucketID: defaultBucketID})
-	return
+	if err != nil {
+		return
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	return events.Dispatch(&BucketDeletedEvent{
+		Bucket: b,
+		Doer:   doer,
+	})
 }
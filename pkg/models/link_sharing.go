@@ -52,8 +52,10 @@ type LinkSharing struct {
 	Name string `xorm:"text null" json:"name"`
 	// The ID of the shared project
 	ProjectID int64 `xorm:"bigint not null" json:"-" param:"project"`
-	// The right this project is shared with. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
-	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// The ID of the shared task. If set, the link share only gives access to this task and not the whole project.
+	TaskID int64 `xorm:"bigint INDEX not null default 0" json:"task_id" param:"projecttask"`
+	// The right this project is shared with. 0 = Read only, 1 = Read & Write, 2 = Admin, 3 = Read & Comment. See the docs for more details.
+	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|3)" maximum:"3" default:"0"`
 
 	// The kind of this link. 0 = undefined, 1 = without password, 2 = with password.
 	SharingType SharingType `xorm:"bigint INDEX not null default 0" json:"sharing_type" valid:"length(0|2)" maximum:"2" default:"0"`
@@ -61,6 +63,15 @@ type LinkSharing struct {
 	// The password of this link share. You can only set it, not retrieve it after the link share has been created.
 	Password string `xorm:"text null" json:"password"`
 
+	// The date when this link share expires. After this date it cannot be used anymore. If not set, the link share never expires.
+	ExpiresAt time.Time `xorm:"DATETIME null 'expires_at'" json:"expires_at"`
+	// How often this link share can be used to authenticate. 0 means unlimited.
+	MaxUses int64 `xorm:"bigint not null default 0" json:"max_uses" valid:"range(0|9223372036854775807)"`
+	// How often this link share was used to authenticate. You cannot change this value.
+	Uses int64 `xorm:"bigint not null default 0" json:"uses"`
+	// If true, the link share cannot be used until it is enabled again. This allows to revoke access without deleting the link share.
+	Disabled bool `xorm:"not null default false" json:"disabled"`
+
 	// The user who shared this project
 	SharedBy   *user.User `xorm:"-" json:"shared_by"`
 	SharedByID int64      `xorm:"bigint INDEX not null" json:"-"`
@@ -95,6 +106,10 @@ func GetLinkShareFromClaims(claims jwt.MapClaims) (share *LinkSharing, err error
 	share.ID = int64(claims["id"].(float64))
 	share.Hash = claims["hash"].(string)
 	share.ProjectID = int64(projectID)
+	// Tokens issued before task shares existed don't have a task id
+	if taskID, has := claims["task_id"].(float64); has {
+		share.TaskID = int64(taskID)
+	}
 	share.Right = Right(claims["right"].(float64))
 	share.SharedByID = int64(claims["sharedByID"].(float64))
 	return
@@ -121,12 +136,13 @@ func (share *LinkSharing) toUser() *user.User {
 
 // Create creates a new link share for a given project
 // @Summary Share a project via link
-// @Description Share a project via link. The user needs to have write-access to the project to be able do this.
+// @Description Share a project via link. The user needs to have write-access to the project to be able do this. When created through a task, the link share only gives access to that task and cannot have admin rights.
 // @tags sharing
 // @Accept json
 // @Produce json
 // @Security JWTKeyAuth
 // @Param project path int true "Project ID"
+// @Param projecttask path int true "Task ID"
 // @Param label body models.LinkSharing true "The new link share object"
 // @Success 201 {object} models.LinkSharing "The created link share object."
 // @Failure 400 {object} web.HTTPError "Invalid link share object provided."
@@ -134,6 +150,7 @@ func (share *LinkSharing) toUser() *user.User {
 // @Failure 404 {object} web.HTTPError "The project does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/shares [put]
+// @Router /tasks/{projecttask}/shares [put]
 func (share *LinkSharing) Create(s *xorm.Session, a web.Auth) (err error) {
 
 	err = share.Right.isValid()
@@ -141,8 +158,22 @@ func (share *LinkSharing) Create(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	if share.TaskID != 0 {
+		// A task can only be shared read only or with write access
+		if share.Right == RightAdmin {
+			return ErrInvalidRight{share.Right}
+		}
+
+		task, err := GetTaskByIDSimple(s, share.TaskID)
+		if err != nil {
+			return err
+		}
+		share.ProjectID = task.ProjectID
+	}
+
 	share.SharedByID = a.GetID()
 	share.Hash = utils.MakeRandomString(40)
+	share.Uses = 0
 
 	if share.Password != "" {
 		share.SharingType = SharingTypeWithPassword
@@ -174,6 +205,7 @@ func (share *LinkSharing) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 404 {object} web.HTTPError "Share Link not found."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/shares/{share} [get]
+// @Router /tasks/{projecttask}/shares/{share} [get]
 func (share *LinkSharing) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
 	exists, err := s.Where("id = ?", share.ID).Get(share)
 	if err != nil {
@@ -188,7 +220,7 @@ func (share *LinkSharing) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
 
 // ReadAll returns all shares for a given project
 // @Summary Get all link shares for a project
-// @Description Returns all link shares which exist for a given project
+// @Description Returns all link shares which exist for a given project. When requested through a task, only the link shares of that task are returned.
 // @tags sharing
 // @Accept json
 // @Produce json
@@ -200,9 +232,16 @@ func (share *LinkSharing) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
 // @Success 200 {array} models.LinkSharing "The share links"
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/shares [get]
+// @Router /tasks/{projecttask}/shares [get]
 func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
-	project := &Project{ID: share.ProjectID}
-	can, _, err := project.CanRead(s, a)
+	var can bool
+	if share.TaskID != 0 {
+		task := &Task{ID: share.TaskID}
+		can, _, err = task.CanRead(s, a)
+	} else {
+		project := &Project{ID: share.ProjectID}
+		can, _, err = project.CanRead(s, a)
+	}
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -212,10 +251,19 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 
 	limit, start := getLimitFromPageIndex(page, perPage)
 
+	// Task shares only show up on their task, not on the project
+	scopeCond := builder.And(
+		builder.Eq{"project_id": share.ProjectID},
+		builder.Eq{"task_id": 0},
+	)
+	if share.TaskID != 0 {
+		scopeCond = builder.Eq{"task_id": share.TaskID}
+	}
+
 	var shares []*LinkSharing
 	query := s.
 		Where(builder.And(
-			builder.Eq{"project_id": share.ProjectID},
+			scopeCond,
 			builder.Or(
 				db.ILIKE("hash", search),
 				db.ILIKE("name", search),
@@ -251,7 +299,10 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 
 	// Total count
 	totalItems, err = s.
-		Where("project_id = ? AND hash LIKE ?", share.ProjectID, "%"+search+"%").
+		Where(builder.And(
+			scopeCond,
+			builder.Like{"hash", "%" + search + "%"},
+		)).
 		Count(&LinkSharing{})
 	if err != nil {
 		return nil, 0, 0, err
@@ -260,6 +311,55 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 	return shares, len(shares), totalItems, err
 }
 
+// Update updates a link share
+// @Summary Update a link share
+// @Description Update the name, right, expiry date and maximum number of uses of a link share or disable it. The user needs to have write-access to the project to be able do this.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param share path int true "Share Link ID"
+// @Param share body models.LinkSharing true "The link share with updated values"
+// @Success 200 {object} models.LinkSharing "The updated link share."
+// @Failure 400 {object} web.HTTPError "Invalid link share object provided."
+// @Failure 403 {object} web.HTTPError "Not allowed to update the link."
+// @Failure 404 {object} web.HTTPError "Share Link not found."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/shares/{share} [post]
+// @Router /tasks/{projecttask}/shares/{share} [post]
+func (share *LinkSharing) Update(s *xorm.Session, _ web.Auth) (err error) {
+	err = share.Right.isValid()
+	if err != nil {
+		return
+	}
+
+	original, err := share.getLinkShareOfRoute(s)
+	if err != nil {
+		return err
+	}
+
+	if original.TaskID != 0 && share.Right == RightAdmin {
+		return ErrInvalidRight{share.Right}
+	}
+
+	_, err = s.
+		Where("id = ?", share.ID).
+		Cols("name", "right", "expires_at", "max_uses", "disabled").
+		Update(share)
+	if err != nil {
+		return err
+	}
+
+	updated, err := GetLinkShareByID(s, share.ID)
+	if err != nil {
+		return err
+	}
+	*share = *updated
+	share.Password = ""
+	return nil
+}
+
 // Delete removes a link share
 // @Summary Remove a link share
 // @Description Remove a link share. The user needs to have write-access to the project to be able do this.
@@ -274,9 +374,40 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 // @Failure 404 {object} web.HTTPError "Share Link not found."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/shares/{share} [delete]
+// @Router /tasks/{projecttask}/shares/{share} [delete]
 func (share *LinkSharing) Delete(s *xorm.Session, _ web.Auth) (err error) {
-	_, err = s.Where("id = ?", share.ID).Delete(share)
-	return
+	_, err = share.getLinkShareOfRoute(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("id = ?", share.ID).Delete(&LinkSharing{})
+	if err != nil {
+		return
+	}
+
+	return deleteLinkShareAccesses(s, []int64{share.ID})
+}
+
+// getLinkShareOfRoute returns the stored link share and makes sure it belongs to the project or task it was
+// requested with. The rights check only covers the project or task of the route.
+func (share *LinkSharing) getLinkShareOfRoute(s *xorm.Session) (*LinkSharing, error) {
+	original, err := GetLinkShareByID(s, share.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if share.TaskID != 0 {
+		if original.TaskID != share.TaskID {
+			return nil, ErrProjectShareDoesNotExist{ID: share.ID}
+		}
+		return original, nil
+	}
+
+	if original.ProjectID != share.ProjectID || original.TaskID != 0 {
+		return nil, ErrProjectShareDoesNotExist{ID: share.ID}
+	}
+	return original, nil
 }
 
 // GetLinkShareByHash returns a link share by hash
@@ -323,6 +454,25 @@ func GetLinkSharesByIDs(s *xorm.Session, ids []int64) (shares map[int64]*LinkSha
 	return
 }
 
+// CheckIsUsable checks if a link share can still be used to access anything.
+// It does not check the maximum number of uses, since that's only relevant when authenticating.
+func (share *LinkSharing) CheckIsUsable() error {
+	if share.Disabled {
+		return &ErrLinkShareDisabled{ShareID: share.ID}
+	}
+
+	if !share.ExpiresAt.IsZero() && time.Now().After(share.ExpiresAt) {
+		return &ErrLinkShareExpired{ShareID: share.ID}
+	}
+
+	// The maximum might have been lowered after the share was used
+	if share.MaxUses > 0 && share.Uses > share.MaxUses {
+		return &ErrLinkShareMaxUsesReached{ShareID: share.ID}
+	}
+
+	return nil
+}
+
 // VerifyLinkSharePassword checks if a password of a link share matches a provided one.
 func VerifyLinkSharePassword(share *LinkSharing, password string) (err error) {
 	if password == "" {
@@ -0,0 +1,143 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// LinkShareAccess represents one authentication with a link share
+type LinkShareAccess struct {
+	// The unique, numeric id of this access log entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The link share which was used.
+	LinkShareID int64 `xorm:"bigint not null INDEX" json:"link_share_id" param:"share"`
+	// The ip address the link share was used from.
+	IP string `xorm:"varchar(45) null" json:"ip"`
+	// The user agent of the client which used the link share.
+	UserAgent string `xorm:"text null" json:"user_agent"`
+
+	// Used to check access to the project the link share belongs to.
+	ProjectID int64 `xorm:"-" json:"-" param:"project"`
+
+	// A timestamp when the link share was used.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName holds the table name
+func (LinkShareAccess) TableName() string {
+	return "link_share_accesses"
+}
+
+// RecordLinkShareAccess checks if a link share can be used to authenticate and records the authentication in its access log.
+func RecordLinkShareAccess(s *xorm.Session, share *LinkSharing, ip, userAgent string) (err error) {
+	err = share.CheckIsUsable()
+	if err != nil {
+		return err
+	}
+
+	// Checking the limit in the same statement as the increment makes sure concurrent
+	// authentications can't use the share more often than allowed.
+	affected, err := s.
+		Where("id = ? AND (max_uses = 0 OR uses < max_uses)", share.ID).
+		Incr("uses").
+		NoAutoTime().
+		Update(&LinkSharing{})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &ErrLinkShareMaxUsesReached{ShareID: share.ID}
+	}
+	share.Uses++
+
+	_, err = s.Insert(&LinkShareAccess{
+		LinkShareID: share.ID,
+		IP:          ip,
+		UserAgent:   userAgent,
+	})
+	return
+}
+
+// ReadAll returns the access log of a link share
+// @Summary Get the access log of a link share
+// @Description Returns every time a link share was used to authenticate, including the ip address and user agent. The user needs to have write-access to the project to see this.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param project path int true "Project ID"
+// @Param share path int true "Share ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.LinkShareAccess "The access log entries, newest first."
+// @Failure 403 {object} web.HTTPError "No access to the project."
+// @Failure 404 {object} web.HTTPError "Share Link not found."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/shares/{share}/log [get]
+func (la *LinkShareAccess) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	share, err := GetLinkShareByID(s, la.LinkShareID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if share.ProjectID != la.ProjectID {
+		return nil, 0, 0, ErrProjectShareDoesNotExist{ID: la.LinkShareID}
+	}
+
+	can, err := share.canDoLinkShare(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	accesses := []*LinkShareAccess{}
+	query := s.
+		Where("link_share_id = ?", la.LinkShareID).
+		OrderBy("created DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&accesses)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalItems, err = s.
+		Where("link_share_id = ?", la.LinkShareID).
+		Count(&LinkShareAccess{})
+	return accesses, len(accesses), totalItems, err
+}
+
+// Deletes the access log of a link share.
+func deleteLinkShareAccesses(s *xorm.Session, shareIDs []int64) (err error) {
+	if len(shareIDs) == 0 {
+		return nil
+	}
+
+	_, err = s.In("link_share_id", shareIDs).Delete(&LinkShareAccess{})
+	return
+}
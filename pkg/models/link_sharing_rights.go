@@ -56,7 +56,16 @@ func (share *LinkSharing) canDoLinkShare(s *xorm.Session, a web.Auth) (bool, err
 		return false, nil
 	}
 
-	l, err := GetProjectSimpleByID(s, share.ProjectID)
+	projectID := share.ProjectID
+	if share.TaskID != 0 {
+		task, err := GetTaskByIDSimple(s, share.TaskID)
+		if err != nil {
+			return false, err
+		}
+		projectID = task.ProjectID
+	}
+
+	l, err := GetProjectSimpleByID(s, projectID)
 	if err != nil {
 		return false, err
 	}
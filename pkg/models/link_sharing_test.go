@@ -18,6 +18,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/user"
@@ -153,3 +154,232 @@ func TestLinkSharing_ReadOne(t *testing.T) {
 		assert.Empty(t, share.Password)
 	})
 }
+
+func TestLinkSharing_Update(t *testing.T) {
+	doer := &user.User{ID: 1}
+
+	t.Run("disable", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ID:        1,
+			ProjectID: 1,
+			Right:     RightRead,
+			Disabled:  true,
+			MaxUses:   5,
+		}
+		err := share.Update(s, doer)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test", share.Hash)
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":       1,
+			"disabled": true,
+			"max_uses": 5,
+		}, false)
+	})
+	t.Run("admin right on a task share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID: 1,
+			TaskID:    1,
+			Right:     RightRead,
+		}
+		err := share.Create(s, doer)
+		assert.NoError(t, err)
+
+		share.Right = RightAdmin
+		err = share.Update(s, doer)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRight(err))
+	})
+	t.Run("share of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// User 1 is admin of project 1 but share 2 belongs to project 2
+		share := &LinkSharing{
+			ID:        2,
+			ProjectID: 1,
+			Right:     RightAdmin,
+		}
+		can, err := share.CanUpdate(s, doer)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = share.Update(s, doer)
+		assert.Error(t, err)
+		assert.True(t, IsErrProjectShareDoesNotExist(err))
+
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":    2,
+			"right": RightWrite,
+		}, false)
+	})
+	t.Run("task share through the project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID: 1,
+			TaskID:    1,
+			Right:     RightRead,
+		}
+		err := share.Create(s, doer)
+		assert.NoError(t, err)
+
+		update := &LinkSharing{
+			ID:        share.ID,
+			ProjectID: 1,
+			Right:     RightWrite,
+		}
+		err = update.Update(s, doer)
+		assert.Error(t, err)
+		assert.True(t, IsErrProjectShareDoesNotExist(err))
+	})
+}
+
+func TestLinkSharing_Delete(t *testing.T) {
+	t.Run("share of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ID:        2,
+			ProjectID: 1,
+		}
+		err := share.Delete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrProjectShareDoesNotExist(err))
+
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id": 2,
+		}, false)
+	})
+}
+
+func TestRecordLinkShareAccess(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 2)
+		assert.NoError(t, err)
+
+		err = RecordLinkShareAccess(s, share, "127.0.0.1", "Test")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(1), share.Uses)
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":   2,
+			"uses": 1,
+		}, false)
+		db.AssertExists(t, "link_share_accesses", map[string]interface{}{
+			"link_share_id": 2,
+			"ip":            "127.0.0.1",
+			"user_agent":    "Test",
+		}, false)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 2)
+		assert.NoError(t, err)
+		share.Disabled = true
+
+		err = RecordLinkShareAccess(s, share, "127.0.0.1", "Test")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareDisabled(err))
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 2)
+		assert.NoError(t, err)
+		share.ExpiresAt = time.Now().Add(-time.Hour)
+
+		err = RecordLinkShareAccess(s, share, "127.0.0.1", "Test")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareExpired(err))
+	})
+	t.Run("max uses reached", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 2).Cols("max_uses").Update(&LinkSharing{MaxUses: 1})
+		assert.NoError(t, err)
+		share, err := GetLinkShareByID(s, 2)
+		assert.NoError(t, err)
+
+		err = RecordLinkShareAccess(s, share, "127.0.0.1", "Test")
+		assert.NoError(t, err)
+
+		err = RecordLinkShareAccess(s, share, "127.0.0.1", "Test")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareMaxUsesReached(err))
+	})
+	t.Run("max uses reached concurrently", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 2).Cols("max_uses").Update(&LinkSharing{MaxUses: 1})
+		assert.NoError(t, err)
+		share, err := GetLinkShareByID(s, 2)
+		assert.NoError(t, err)
+		// Another authentication used the share after it was loaded
+		_, err = s.Where("id = ?", 2).Cols("uses").NoAutoTime().Update(&LinkSharing{Uses: 1})
+		assert.NoError(t, err)
+
+		err = RecordLinkShareAccess(s, share, "127.0.0.1", "Test")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareMaxUsesReached(err))
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":   2,
+			"uses": 1,
+		}, false)
+	})
+}
+
+func TestLinkShareAccess_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		la := &LinkShareAccess{LinkShareID: 1, ProjectID: 1}
+		all, _, total, err := la.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		assert.NoError(t, err)
+		accesses := all.([]*LinkShareAccess)
+		assert.Len(t, accesses, 2)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, int64(2), accesses[0].ID)
+	})
+	t.Run("no write access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		la := &LinkShareAccess{LinkShareID: 1, ProjectID: 1}
+		_, _, _, err := la.ReadAll(s, &user.User{ID: 2}, "", 1, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
@@ -38,7 +38,9 @@ import (
 func RegisterListeners() {
 	events.RegisterListener((&ProjectCreatedEvent{}).Name(), &IncreaseProjectCounter{})
 	events.RegisterListener((&ProjectDeletedEvent{}).Name(), &DecreaseProjectCounter{})
+	events.RegisterListener((&ProjectRestoredEvent{}).Name(), &IncreaseProjectCounter{})
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &IncreaseTaskCounter{})
+	events.RegisterListener((&TaskRestoredEvent{}).Name(), &IncreaseTaskCounter{})
 	events.RegisterListener((&TaskDeletedEvent{}).Name(), &DecreaseTaskCounter{})
 	events.RegisterListener((&TeamDeletedEvent{}).Name(), &DecreaseTeamCounter{})
 	events.RegisterListener((&TeamCreatedEvent{}).Name(), &IncreaseTeamCounter{})
@@ -61,9 +63,13 @@ func RegisterListeners() {
 	events.RegisterListener((&TaskAttachmentDeletedEvent{}).Name(), &HandleTaskUpdateLastUpdated{})
 	events.RegisterListener((&TaskRelationCreatedEvent{}).Name(), &HandleTaskUpdateLastUpdated{})
 	events.RegisterListener((&TaskRelationDeletedEvent{}).Name(), &HandleTaskUpdateLastUpdated{})
+	for _, name := range realtimeEvents {
+		events.RegisterListener(name, &PublishRealtimeEvent{event: name})
+	}
 	if config.TypesenseEnabled.GetBool() {
 		events.RegisterListener((&TaskDeletedEvent{}).Name(), &RemoveTaskFromTypesense{})
 		events.RegisterListener((&TaskCreatedEvent{}).Name(), &AddTaskToTypesense{})
+		events.RegisterListener((&TaskRestoredEvent{}).Name(), &AddTaskToTypesense{})
 	}
 }
 
@@ -122,6 +128,18 @@ func notifyMentionedUsers(sess *xorm.Session, task *Task, text string, n notific
 		}
 
 		// Don't notify a user if they were already notified
+		sent, err := sess.
+			Where("user_id = ? AND name = ? AND subject_id = ?", u.ID, n.Name(), n.SubjectID()).
+			Exist(&SentMention{})
+		if err != nil {
+			return users, err
+		}
+
+		if sent {
+			continue
+		}
+
+		// Mentions from before sent mentions were recorded only have their in-app notification
 		dbn, err := notifications.GetNotificationsForNameAndUser(sess, u.ID, n.Name(), n.SubjectID())
 		if err != nil {
 			return users, err
@@ -135,6 +153,15 @@ func notifyMentionedUsers(sess *xorm.Session, task *Task, text string, n notific
 		if err != nil {
 			return users, err
 		}
+
+		_, err = sess.Insert(&SentMention{
+			UserID:    u.ID,
+			Name:      n.Name(),
+			SubjectID: n.SubjectID(),
+		})
+		if err != nil {
+			return users, err
+		}
 		notified++
 	}
 
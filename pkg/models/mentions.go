@@ -19,15 +19,43 @@ package models
 import (
 	"regexp"
 	"strings"
+	"time"
 
 	"code.vikunja.io/api/pkg/user"
 
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
+// SentMention records that a user was notified about a mention. It makes sure users are only notified once
+// about every mention, regardless of the channels they receive notifications through.
+type SentMention struct {
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	// The user who was mentioned.
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The name of the notification the user got about the mention.
+	Name string `xorm:"varchar(250) not null" json:"-"`
+	// The thing the user was mentioned in, for example a comment.
+	SubjectID int64 `xorm:"bigint not null" json:"-"`
+	// A timestamp when the user was notified.
+	Created time.Time `xorm:"created not null" json:"-"`
+}
+
+// TableName returns the table name for sent mentions
+func (SentMention) TableName() string {
+	return "sent_mentions"
+}
+
+var (
+	userMentionRegex = regexp.MustCompile(`@\w+`)
+	teamMentionRegex = regexp.MustCompile(`@\w+(-\w+)*`)
+)
+
+// FindMentionedUsersInText returns all users mentioned with @username in a text. Teams can be mentioned with their
+// name, where spaces are written as hyphens, for example @my-team. Mentioning a team includes all of its members.
+// Users who are mentioned more than once, either directly or through several teams, are only included once.
 func FindMentionedUsersInText(s *xorm.Session, text string) (users map[int64]*user.User, err error) {
-	reg := regexp.MustCompile(`@\w+`)
-	matches := reg.FindAllString(text, -1)
+	matches := userMentionRegex.FindAllString(text, -1)
 	if matches == nil {
 		return
 	}
@@ -37,5 +65,58 @@ func FindMentionedUsersInText(s *xorm.Session, text string) (users map[int64]*us
 		usernames = append(usernames, strings.TrimPrefix(match, "@"))
 	}
 
-	return user.GetUsersByUsername(s, usernames, true)
+	users, err = user.GetUsersByUsername(s, usernames, true)
+	if err != nil {
+		return
+	}
+
+	teamMembers, err := findMentionedTeamMembersInText(s, text)
+	if err != nil {
+		return
+	}
+
+	if len(teamMembers) > 0 && users == nil {
+		users = make(map[int64]*user.User, len(teamMembers))
+	}
+	for id, u := range teamMembers {
+		users[id] = u
+	}
+
+	return
+}
+
+func findMentionedTeamMembersInText(s *xorm.Session, text string) (members map[int64]*user.User, err error) {
+	matches := teamMentionRegex.FindAllString(text, -1)
+	if matches == nil {
+		return
+	}
+
+	// In() would quote the expression as a column name
+	nameConds := []builder.Cond{}
+	for _, match := range matches {
+		name := strings.ToLower(strings.TrimPrefix(match, "@"))
+		nameConds = append(nameConds, builder.Expr("LOWER(name) = ?", name))
+		if strings.Contains(name, "-") {
+			nameConds = append(nameConds, builder.Expr("LOWER(name) = ?", strings.ReplaceAll(name, "-", " ")))
+		}
+	}
+
+	teamIDs := []int64{}
+	err = s.
+		Table("teams").
+		Cols("id").
+		Where(builder.Or(nameConds...)).
+		Find(&teamIDs)
+	if err != nil || len(teamIDs) == 0 {
+		return
+	}
+
+	members = make(map[int64]*user.User)
+	err = s.
+		Table("users").
+		Select("users.*").
+		Join("INNER", "team_members", "team_members.user_id = users.id").
+		In("team_members.team_id", teamIDs).
+		Find(&members)
+	return
 }
@@ -33,11 +33,15 @@ func TestFindMentionedUsersInText(t *testing.T) {
 	user2 := &user.User{
 		ID: 2,
 	}
+	user3 := &user.User{
+		ID: 3,
+	}
 
 	tests := []struct {
 		name      string
 		text      string
 		wantUsers []*user.User
+		wantCount int
 		wantErr   bool
 	}{
 		{
@@ -69,6 +73,30 @@ func TestFindMentionedUsersInText(t *testing.T) {
 			text:      "Lorem @user1 Ipsum @user2",
 			wantUsers: []*user.User{user1, user2},
 		},
+		{
+			name:      "team",
+			text:      "Lorem @testteam1 Ipsum",
+			wantUsers: []*user.User{user1, user2},
+			wantCount: 2,
+		},
+		{
+			name:      "team with different case",
+			text:      "Lorem @TestTeam1 Ipsum",
+			wantUsers: []*user.User{user1, user2},
+			wantCount: 2,
+		},
+		{
+			name:      "user and team",
+			text:      "Lorem @testteam10 Ipsum @user2",
+			wantUsers: []*user.User{user2, user3},
+			wantCount: 2,
+		},
+		{
+			name:      "user mentioned directly and through a team",
+			text:      "Lorem @testteam1 Ipsum @user2 @testteam9",
+			wantUsers: []*user.User{user1, user2},
+			wantCount: 2,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -87,6 +115,9 @@ func TestFindMentionedUsersInText(t *testing.T) {
 					t.Errorf("wanted user %d but did not get it", u.ID)
 				}
 			}
+			if tt.wantCount > 0 {
+				assert.Len(t, gotUsers, tt.wantCount)
+			}
 		})
 	}
 }
@@ -177,4 +208,109 @@ func TestSendingMentionNotification(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, dbNotifications, 1)
 	})
+	t.Run("should not send notifications multiple times with in-app notifications disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task, err := GetTaskByIDSimple(s, 32)
+		assert.NoError(t, err)
+		tc := &TaskComment{
+			Comment: "Lorem Ipsum @user2",
+			TaskID:  32,
+		}
+		err = tc.Create(s, u)
+		assert.NoError(t, err)
+		n := &TaskCommentNotification{
+			Doer:      u,
+			Task:      &task,
+			Comment:   tc,
+			Mentioned: true,
+		}
+		_, err = s.Insert(&notifications.NotificationPreference{
+			NotifiableID: 2,
+			Name:         n.Name(),
+			Mail:         true,
+			InApp:        false,
+			Push:         false,
+		})
+		assert.NoError(t, err)
+
+		_, err = notifyMentionedUsers(s, &task, tc.Comment, n)
+		assert.NoError(t, err)
+
+		_, err = notifyMentionedUsers(s, &task, tc.Comment, n)
+		assert.NoError(t, err)
+
+		sent, err := s.
+			Where("user_id = ? AND name = ? AND subject_id = ?", 2, n.Name(), tc.ID).
+			Count(&SentMention{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), sent)
+		db.AssertMissing(t, "notifications", map[string]interface{}{
+			"subject_id":    tc.ID,
+			"notifiable_id": 2,
+			"name":          n.Name(),
+		})
+	})
+	t.Run("should notify team members having access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task, err := GetTaskByIDSimple(s, 32)
+		assert.NoError(t, err)
+		tc := &TaskComment{
+			Comment: "Lorem Ipsum @testteam1 @testteam12",
+			TaskID:  32, // team 1 has access to the project that task belongs to
+		}
+		err = tc.Create(s, u)
+		assert.NoError(t, err)
+		n := &TaskCommentNotification{
+			Doer:    u,
+			Task:    &task,
+			Comment: tc,
+		}
+
+		_, err = notifyMentionedUsers(s, &task, tc.Comment, n)
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "notifications", map[string]interface{}{
+			"subject_id":    tc.ID,
+			"notifiable_id": 2,
+			"name":          n.Name(),
+		}, false)
+		// user 9 is the only member of team 12 and does not have access to the task
+		db.AssertMissing(t, "notifications", map[string]interface{}{
+			"subject_id":    tc.ID,
+			"notifiable_id": 9,
+			"name":          n.Name(),
+		})
+	})
+	t.Run("should notify users mentioned directly and through a team once", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task, err := GetTaskByIDSimple(s, 32)
+		assert.NoError(t, err)
+		tc := &TaskComment{
+			Comment: "Lorem Ipsum @user2 @testteam1",
+			TaskID:  32,
+		}
+		err = tc.Create(s, u)
+		assert.NoError(t, err)
+		n := &TaskCommentNotification{
+			Doer:    u,
+			Task:    &task,
+			Comment: tc,
+		}
+
+		_, err = notifyMentionedUsers(s, &task, tc.Comment, n)
+		assert.NoError(t, err)
+
+		dbNotifications, err := notifications.GetNotificationsForNameAndUser(s, 2, n.Name(), tc.ID)
+		assert.NoError(t, err)
+		assert.Len(t, dbNotifications, 1)
+	})
 }
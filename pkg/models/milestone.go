@@ -0,0 +1,472 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"math"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Milestone groups tasks, for example the ones which need to be done for a release. A milestone either belongs to a
+// project, in which case only tasks of that project can be assigned to it, or it spans across projects.
+type Milestone struct {
+	// The unique, numeric id of this milestone.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"milestone"`
+	// The title of the milestone.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The description of the milestone.
+	Description string `xorm:"longtext null" json:"description"`
+	// The date when all tasks of this milestone should be done.
+	TargetDate time.Time `xorm:"DATETIME null" json:"target_date"`
+	// The project this milestone belongs to. 0 if the milestone spans across projects. Cannot be changed after
+	// the milestone was created. When listing milestones, this can be passed as a query parameter to only get
+	// the milestones of one project.
+	ProjectID int64 `xorm:"bigint not null default 0 INDEX" json:"project_id" query:"project_id"`
+
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The user who created this milestone.
+	Owner *user.User `xorm:"-" json:"owner" valid:"-"`
+
+	// A timestamp when this milestone was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this milestone was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for milestones
+func (*Milestone) TableName() string {
+	return "milestones"
+}
+
+func getMilestoneByID(s *xorm.Session, id int64) (m *Milestone, err error) {
+	m = &Milestone{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(m)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrMilestoneDoesNotExist{MilestoneID: id}
+	}
+	return
+}
+
+// checkTaskMilestone checks if the milestone of a task exists, can be seen by the user and fits the project of
+// the task.
+func checkTaskMilestone(s *xorm.Session, t *Task, a web.Auth) error {
+	m := &Milestone{ID: t.MilestoneID}
+	can, _, err := m.CanRead(s, a)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+
+	if m.ProjectID != 0 && m.ProjectID != t.ProjectID {
+		return ErrMilestoneDoesNotBelongToProject{MilestoneID: m.ID, ProjectID: t.ProjectID}
+	}
+
+	return nil
+}
+
+// clearMilestoneIfNotInProject removes a task from its milestone if the milestone belongs to another project than
+// the task. This happens when a task is moved or copied into another project.
+func clearMilestoneIfNotInProject(s *xorm.Session, t *Task) error {
+	if t.MilestoneID == 0 {
+		return nil
+	}
+
+	m, err := getMilestoneByID(s, t.MilestoneID)
+	if err != nil && !IsErrMilestoneDoesNotExist(err) {
+		return err
+	}
+	if err != nil || (m.ProjectID != 0 && m.ProjectID != t.ProjectID) {
+		t.MilestoneID = 0
+	}
+
+	return nil
+}
+
+// deleteMilestones removes all milestones matching the condition and removes all tasks from them.
+func deleteMilestones(s *xorm.Session, cond builder.Cond) error {
+	milestoneIDs := []int64{}
+	err := s.
+		Table("milestones").
+		Where(cond).
+		Cols("id").
+		Find(&milestoneIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(milestoneIDs) == 0 {
+		return nil
+	}
+
+	_, err = s.
+		In("milestone_id", milestoneIDs).
+		Cols("milestone_id").
+		NoAutoTime().
+		Update(&Task{MilestoneID: 0})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		In("id", milestoneIDs).
+		Delete(&Milestone{})
+	return err
+}
+
+// Create creates a new milestone
+// @Summary Create a milestone
+// @Description Creates a new milestone. If a project id is provided, the milestone belongs to that project and the user needs write access to it. Otherwise the milestone spans across projects and only the user who created it can see it.
+// @tags milestone
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param milestone body models.Milestone true "The milestone"
+// @Success 201 {object} models.Milestone "The created milestone."
+// @Failure 400 {object} web.HTTPError "Invalid milestone object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /milestones [put]
+func (m *Milestone) Create(s *xorm.Session, a web.Auth) (err error) {
+	m.ID = 0
+
+	owner, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+	m.OwnerID = owner.ID
+
+	_, err = s.Insert(m)
+	if err != nil {
+		return err
+	}
+
+	m.Owner = owner
+	return nil
+}
+
+// ReadAll returns all milestones the user has access to
+// @Summary Get all milestones
+// @Description Returns all milestones of all projects the user has access to and all cross-project milestones the user created.
+// @tags milestone
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search milestones by title."
+// @Param project_id query int false "Only return the milestones of this project."
+// @Success 200 {array} models.Milestone "The milestones"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /milestones [get]
+func (m *Milestone) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	// Link shares only have access to the milestones of their project
+	if share, is := a.(*LinkSharing); is {
+		m.ProjectID = share.ProjectID
+	}
+
+	var where builder.Cond
+	if m.ProjectID != 0 {
+		project := &Project{ID: m.ProjectID}
+		can, _, err := project.CanRead(s, a)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if !can {
+			return nil, 0, 0, ErrUserDoesNotHaveAccessToProject{ProjectID: m.ProjectID, UserID: a.GetID()}
+		}
+		where = builder.Eq{"project_id": m.ProjectID}
+	} else {
+		projects, _, _, err := getRawProjectsForUser(
+			s,
+			&projectOptions{
+				user:        &user.User{ID: a.GetID()},
+				page:        -1,
+				getArchived: true,
+			},
+		)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		projectIDs := make([]int64, 0, len(projects))
+		for _, p := range projects {
+			projectIDs = append(projectIDs, p.ID)
+		}
+
+		where = builder.Or(
+			builder.In("project_id", projectIDs),
+			builder.And(
+				builder.Eq{"project_id": 0},
+				builder.Eq{"owner_id": a.GetID()},
+			),
+		)
+	}
+
+	if search != "" {
+		where = builder.And(
+			where,
+			db.ILIKE("title", search),
+		)
+	}
+
+	milestones := []*Milestone{}
+	err = s.
+		Where(where).
+		OrderBy("id asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&milestones)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	ownerIDs := make([]int64, 0, len(milestones))
+	for _, milestone := range milestones {
+		ownerIDs = append(ownerIDs, milestone.OwnerID)
+	}
+	owners, err := getUsersOrLinkSharesFromIDs(s, ownerIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, milestone := range milestones {
+		milestone.Owner = owners[milestone.OwnerID]
+	}
+
+	totalCount, err := s.Where(where).Count(&Milestone{})
+	return milestones, len(milestones), totalCount, err
+}
+
+// ReadOne returns one milestone
+// @Summary Get one milestone
+// @Description Returns a milestone by its id.
+// @tags milestone
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param milestone path int true "Milestone ID"
+// @Success 200 {object} models.Milestone "The milestone"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that milestone."
+// @Failure 404 {object} web.HTTPError "The milestone does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /milestones/{milestone} [get]
+func (m *Milestone) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	// m already contains the full milestone from the rights check, we only need to add the user
+	owners, err := getUsersOrLinkSharesFromIDs(s, []int64{m.OwnerID})
+	if err != nil {
+		return err
+	}
+	m.Owner = owners[m.OwnerID]
+	return nil
+}
+
+// Update changes a milestone
+// @Summary Update a milestone
+// @Description Updates the title, description and target date of a milestone. The project of a milestone cannot be changed.
+// @tags milestone
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param milestone path int true "Milestone ID"
+// @Param milestone body models.Milestone true "The milestone"
+// @Success 200 {object} models.Milestone "The updated milestone"
+// @Failure 400 {object} web.HTTPError "Invalid milestone object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that milestone."
+// @Failure 404 {object} web.HTTPError "The milestone does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /milestones/{milestone} [post]
+func (m *Milestone) Update(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.
+		Where("id = ?", m.ID).
+		Cols("title", "description", "target_date").
+		Update(m)
+	if err != nil {
+		return err
+	}
+
+	updated, err := getMilestoneByID(s, m.ID)
+	if err != nil {
+		return err
+	}
+	*m = *updated
+	return m.ReadOne(s, a)
+}
+
+// Delete removes a milestone
+// @Summary Delete a milestone
+// @Description Deletes a milestone. All tasks of the milestone are kept but are not part of any milestone anymore.
+// @tags milestone
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param milestone path int true "Milestone ID"
+// @Success 200 {object} models.Message "The milestone was deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that milestone."
+// @Failure 404 {object} web.HTTPError "The milestone does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /milestones/{milestone} [delete]
+func (m *Milestone) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	return deleteMilestones(s, builder.Eq{"id": m.ID})
+}
+
+// MilestoneBurndownPoint holds the state of all tasks of a milestone at the end of one day.
+type MilestoneBurndownPoint struct {
+	// The day of this point, at midnight in the configured time zone.
+	Date time.Time `json:"date"`
+	// The number of tasks of the milestone which were created until the end of this day.
+	Total int64 `json:"total"`
+	// The number of tasks of the milestone which were done until the end of this day.
+	Done int64 `json:"done"`
+	// The number of tasks which were still open at the end of this day.
+	Remaining int64 `json:"remaining"`
+	// The number of remaining tasks if all tasks were done at a steady pace until the target date. Only set if the
+	// milestone has a target date.
+	Ideal *float64 `json:"ideal"`
+}
+
+// MilestoneBurndown holds the burndown and burn-up series of a milestone.
+type MilestoneBurndown struct {
+	// The milestone to get the burndown for.
+	MilestoneID int64 `json:"milestone_id" param:"milestone"`
+	// The first day of the series. This is the day the first task of the milestone was created.
+	StartDate time.Time `json:"start_date"`
+	// The target date of the milestone.
+	TargetDate time.Time `json:"target_date"`
+	// One point per day from the start date until today.
+	Points []*MilestoneBurndownPoint `json:"points"`
+
+	milestone *Milestone
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(config.GetTimeZone())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween returns the number of days between two days, ignoring days which are shorter or longer because of
+// daylight saving time.
+func daysBetween(from, to time.Time) float64 {
+	return math.Round(to.Sub(from).Hours() / 24)
+}
+
+// calculateBurndown computes one point per day from the day the first task was created until now. Tasks are counted
+// from the day they were created and as done from the day they were marked done. Done tasks without a done date are
+// counted as done from the day they were last updated.
+func (mb *MilestoneBurndown) calculateBurndown(tasks []*Task, now time.Time) {
+	created := make(map[time.Time]int64)
+	done := make(map[time.Time]int64)
+
+	start := startOfDay(now)
+	for _, t := range tasks {
+		createdDay := startOfDay(t.Created)
+		created[createdDay]++
+		if createdDay.Before(start) {
+			start = createdDay
+		}
+
+		if !t.Done {
+			continue
+		}
+		doneAt := t.DoneAt
+		if doneAt.IsZero() {
+			doneAt = t.Updated
+		}
+		doneDay := startOfDay(doneAt)
+		if doneDay.Before(createdDay) {
+			doneDay = createdDay
+		}
+		done[doneDay]++
+	}
+
+	mb.StartDate = start
+	mb.Points = []*MilestoneBurndownPoint{}
+
+	var total, doneCount int64
+	end := startOfDay(now)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		total += created[day]
+		doneCount += done[day]
+		mb.Points = append(mb.Points, &MilestoneBurndownPoint{
+			Date:      day,
+			Total:     total,
+			Done:      doneCount,
+			Remaining: total - doneCount,
+		})
+	}
+
+	if mb.TargetDate.IsZero() {
+		return
+	}
+
+	// The ideal line goes from all tasks of the milestone on the first day to none on the target date
+	target := startOfDay(mb.TargetDate)
+	days := daysBetween(start, target)
+	for _, point := range mb.Points {
+		ideal := 0.0
+		if days > 0 && point.Date.Before(target) {
+			ideal = float64(total) * (1 - daysBetween(start, point.Date)/days)
+		}
+		point.Ideal = &ideal
+	}
+}
+
+// ReadOne returns the burndown of a milestone
+// @Summary Get the burndown of a milestone
+// @Description Returns one point per day from the creation of the first task of the milestone until today with the number of all, done and remaining tasks of the milestone at the end of that day. This can be used to draw burndown and burn-up charts. If the milestone has a target date, every point also contains the number of remaining tasks if all tasks were done at a steady pace until the target date.
+// @tags milestone
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param milestone path int true "Milestone ID"
+// @Success 200 {object} models.MilestoneBurndown "The burndown of the milestone"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that milestone."
+// @Failure 404 {object} web.HTTPError "The milestone does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /milestones/{milestone}/burndown [get]
+func (mb *MilestoneBurndown) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	tasks := []*Task{}
+	err = s.
+		Where("milestone_id = ?", mb.MilestoneID).
+		Find(&tasks)
+	if err != nil {
+		return err
+	}
+
+	mb.TargetDate = mb.milestone.TargetDate
+	mb.calculateBurndown(tasks, time.Now())
+	return nil
+}
@@ -0,0 +1,116 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if a user can create a milestone
+func (m *Milestone) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if m.ProjectID != 0 {
+		return (&Project{ID: m.ProjectID}).CanWrite(s, a)
+	}
+
+	// Link shares only have access to their project
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrMilestoneNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	return true, nil
+}
+
+// CanRead checks if a user can see a milestone
+func (m *Milestone) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	milestone, err := getMilestoneByID(s, m.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	can, maxRight := false, 0
+	if milestone.ProjectID != 0 {
+		can, maxRight, err = (&Project{ID: milestone.ProjectID}).CanRead(s, a)
+		if err != nil {
+			return false, 0, err
+		}
+	} else {
+		can, err = milestone.isOwner(a)
+		if err != nil {
+			return false, 0, err
+		}
+		maxRight = int(RightAdmin)
+	}
+
+	if can {
+		*m = *milestone
+	}
+	return can, maxRight, nil
+}
+
+// CanUpdate checks if a user can update a milestone
+func (m *Milestone) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	// A normal check would replace the passed struct which in our case would override the values we want to update.
+	mm := &Milestone{ID: m.ID}
+	can, err := mm.canWriteMilestone(s, a)
+	if err != nil || !can {
+		return can, err
+	}
+
+	m.ProjectID = mm.ProjectID
+	return true, nil
+}
+
+// CanDelete checks if a user can delete a milestone
+func (m *Milestone) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return m.canWriteMilestone(s, a)
+}
+
+// Milestones of a project can be changed by everyone with write access to the project, cross-project milestones
+// only by the user who created them.
+func (m *Milestone) canWriteMilestone(s *xorm.Session, a web.Auth) (bool, error) {
+	milestone, err := getMilestoneByID(s, m.ID)
+	if err != nil {
+		return false, err
+	}
+
+	var can bool
+	if milestone.ProjectID != 0 {
+		can, err = (&Project{ID: milestone.ProjectID}).CanWrite(s, a)
+	} else {
+		can, err = milestone.isOwner(a)
+	}
+	if err != nil || !can {
+		return false, err
+	}
+
+	*m = *milestone
+	return true, nil
+}
+
+func (m *Milestone) isOwner(a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrMilestoneNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+	return m.OwnerID == a.GetID(), nil
+}
+
+// CanRead checks if a user can see the burndown of a milestone
+func (mb *MilestoneBurndown) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	mb.milestone = &Milestone{ID: mb.MilestoneID}
+	return mb.milestone.CanRead(s, a)
+}
@@ -0,0 +1,494 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestMilestone_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{
+			Title:     "Release 2.0",
+			ProjectID: 1,
+		}
+		can, err := m.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), m.Owner.ID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "milestones", map[string]interface{}{
+			"id":         m.ID,
+			"title":      "Release 2.0",
+			"project_id": 1,
+			"owner_id":   1,
+		}, false)
+	})
+	t.Run("cross-project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{Title: "Release 2.0"}
+		can, err := m.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "milestones", map[string]interface{}{
+			"id":         m.ID,
+			"project_id": 0,
+			"owner_id":   1,
+		}, false)
+	})
+	t.Run("no write access to the project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{
+			Title:     "Release 2.0",
+			ProjectID: 9,
+		}
+		can, err := m.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("cross-project milestone as link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{Title: "Release 2.0"}
+		_, err := m.CanCreate(s, &LinkSharing{ID: 3, ProjectID: 3, Right: RightAdmin})
+		assert.Error(t, err)
+		assert.True(t, IsErrMilestoneNotAvailableForLinkShare(err))
+	})
+}
+
+func TestMilestone_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("all milestones", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{}
+		result, count, total, err := m.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, int64(2), total)
+		milestones := result.([]*Milestone)
+		require.Len(t, milestones, 2)
+		assert.Equal(t, int64(1), milestones[0].ID)
+		assert.Equal(t, int64(2), milestones[1].ID)
+		require.NotNil(t, milestones[0].Owner)
+		assert.Equal(t, int64(1), milestones[0].Owner.ID)
+	})
+	t.Run("of one project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ProjectID: 1}
+		result, count, _, err := m.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.Len(t, result, 1)
+		assert.Equal(t, int64(1), result.([]*Milestone)[0].ID)
+	})
+	t.Run("of a project without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ProjectID: 2}
+		_, _, _, err := m.ReadAll(s, u, "", 0, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToProject(err))
+	})
+	t.Run("search", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{}
+		result, count, _, err := m.ReadAll(s, u, "cross", 0, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.Len(t, result, 1)
+		assert.Equal(t, int64(2), result.([]*Milestone)[0].ID)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{}
+		result, count, _, err := m.ReadAll(s, &LinkSharing{ID: 1, ProjectID: 1, Right: RightRead}, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.Len(t, result, 1)
+		assert.Equal(t, int64(1), result.([]*Milestone)[0].ID)
+	})
+}
+
+func TestMilestone_CanRead(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ID: 1}
+		can, _, err := m.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		assert.Equal(t, "Release 1.0", m.Title)
+	})
+	t.Run("own cross-project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ID: 2}
+		can, _, err := m.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("cross-project milestone of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ID: 3}
+		can, _, err := m.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("milestone of a project without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ID: 4}
+		can, _, err := m.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{ID: 9999}
+		_, _, err := m.CanRead(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrMilestoneDoesNotExist(err))
+	})
+}
+
+func TestMilestone_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{
+			ID:        1,
+			Title:     "Release 1.1",
+			ProjectID: 10,
+		}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, "Release 1.1", m.Title)
+		assert.Equal(t, int64(1), m.ProjectID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "milestones", map[string]interface{}{
+			"id":         1,
+			"title":      "Release 1.1",
+			"project_id": 1,
+		}, false)
+	})
+	t.Run("cross-project milestone of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &Milestone{
+			ID:    3,
+			Title: "Release 1.1",
+		}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestMilestone_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.In("id", []int64{1, 2}).Cols("milestone_id").Update(&Task{MilestoneID: 1})
+	assert.NoError(t, err)
+
+	m := &Milestone{ID: 1}
+	can, err := m.CanDelete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = m.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "milestones", map[string]interface{}{
+		"id": 1,
+	})
+	db.AssertExists(t, "tasks", map[string]interface{}{
+		"id":           1,
+		"milestone_id": 0,
+	}, false)
+	db.AssertExists(t, "tasks", map[string]interface{}{
+		"id":           2,
+		"milestone_id": 0,
+	}, false)
+}
+
+func TestTask_Milestone(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	setMilestone := func(t *testing.T, s *xorm.Session, taskID, milestoneID int64) (*Task, error) {
+		task, err := GetTaskByIDSimple(s, taskID)
+		assert.NoError(t, err)
+		task.MilestoneID = milestoneID
+		err = task.Update(s, u)
+		return &task, err
+	}
+
+	t.Run("project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := setMilestone(t, s, 1, 1)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":           1,
+			"milestone_id": 1,
+		}, false)
+	})
+	t.Run("milestone of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := setMilestone(t, s, 19, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrMilestoneDoesNotBelongToProject(err))
+	})
+	t.Run("cross-project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := setMilestone(t, s, 19, 2)
+		assert.NoError(t, err)
+	})
+	t.Run("cross-project milestone of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := setMilestone(t, s, 19, 3)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+	t.Run("nonexisting milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := setMilestone(t, s, 1, 9999)
+		assert.Error(t, err)
+		assert.True(t, IsErrMilestoneDoesNotExist(err))
+	})
+	t.Run("on create", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			Title:       "Lorem",
+			ProjectID:   10,
+			MilestoneID: 1,
+		}
+		err := task.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrMilestoneDoesNotBelongToProject(err))
+	})
+	t.Run("moving the task removes it from the project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("milestone_id").Update(&Task{MilestoneID: 1})
+		assert.NoError(t, err)
+
+		task, err := GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+		task.ProjectID = 10
+		task.BucketID = 0
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), task.MilestoneID)
+	})
+	t.Run("moving the task keeps the cross-project milestone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("milestone_id").Update(&Task{MilestoneID: 2})
+		assert.NoError(t, err)
+
+		task, err := GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+		task.ProjectID = 10
+		task.BucketID = 0
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), task.MilestoneID)
+	})
+}
+
+func TestMilestoneBurndown_calculateBurndown(t *testing.T) {
+	day := func(d int) time.Time {
+		return startOfDay(time.Date(2023, 10, d, 12, 0, 0, 0, config.GetTimeZone()))
+	}
+
+	tasks := []*Task{
+		{Created: day(1)},
+		{Created: day(1), Done: true, DoneAt: day(2)},
+		{Created: day(2), Done: true, Updated: day(3)},
+		{Created: day(3)},
+	}
+
+	t.Run("without target date", func(t *testing.T) {
+		mb := &MilestoneBurndown{}
+		mb.calculateBurndown(tasks, day(4))
+
+		assert.Equal(t, day(1), mb.StartDate)
+		require.Len(t, mb.Points, 4)
+		expected := []struct{ total, done int64 }{{2, 0}, {3, 1}, {4, 2}, {4, 2}}
+		for i, e := range expected {
+			assert.Equal(t, day(i+1), mb.Points[i].Date)
+			assert.Equal(t, e.total, mb.Points[i].Total)
+			assert.Equal(t, e.done, mb.Points[i].Done)
+			assert.Equal(t, e.total-e.done, mb.Points[i].Remaining)
+			assert.Nil(t, mb.Points[i].Ideal)
+		}
+	})
+	t.Run("with target date", func(t *testing.T) {
+		mb := &MilestoneBurndown{TargetDate: day(3)}
+		mb.calculateBurndown(tasks, day(4))
+
+		require.Len(t, mb.Points, 4)
+		assert.Equal(t, 4.0, *mb.Points[0].Ideal)
+		assert.Equal(t, 2.0, *mb.Points[1].Ideal)
+		assert.Equal(t, 0.0, *mb.Points[2].Ideal)
+		assert.Equal(t, 0.0, *mb.Points[3].Ideal)
+	})
+	t.Run("without tasks", func(t *testing.T) {
+		mb := &MilestoneBurndown{}
+		mb.calculateBurndown([]*Task{}, day(4))
+
+		assert.Equal(t, day(4), mb.StartDate)
+		require.Len(t, mb.Points, 1)
+		assert.Equal(t, int64(0), mb.Points[0].Total)
+	})
+}
+
+func TestMilestoneBurndown_ReadOne(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.In("id", []int64{1, 2}).Cols("milestone_id").Update(&Task{MilestoneID: 1})
+		assert.NoError(t, err)
+
+		mb := &MilestoneBurndown{MilestoneID: 1}
+		can, _, err := mb.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = mb.ReadOne(s, u)
+		assert.NoError(t, err)
+
+		assert.Equal(t, startOfDay(time.Date(2018, 12, 1, 1, 12, 4, 0, time.UTC)), mb.StartDate)
+		last := mb.Points[len(mb.Points)-1]
+		assert.Equal(t, int64(2), last.Total)
+		assert.Equal(t, int64(1), last.Done)
+		assert.Equal(t, int64(1), last.Remaining)
+		assert.Equal(t, 0.0, *last.Ideal)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		mb := &MilestoneBurndown{MilestoneID: 3}
+		can, _, err := mb.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
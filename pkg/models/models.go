@@ -45,11 +45,13 @@ func GetTables() []interface{} {
 		&TeamMember{},
 		&TeamProject{},
 		&ProjectUser{},
+		&TaskUser{},
 		&TaskAssginee{},
 		&Label{},
 		&LabelTask{},
 		&TaskReminder{},
 		&LinkSharing{},
+		&LinkShareAccess{},
 		&TaskRelation{},
 		&TaskAttachment{},
 		&TaskComment{},
@@ -60,6 +62,15 @@ func GetTables() []interface{} {
 		&Favorite{},
 		&APIToken{},
 		&TypesenseSync{},
+		&DeferredReminder{},
+		&TaskChecklistItem{},
+		&ProjectTemplate{},
+		&TaskTemplate{},
+		&TrashedItem{},
+		&Milestone{},
+		&Sprint{},
+		&SprintTask{},
+		&SentMention{},
 	}
 }
 
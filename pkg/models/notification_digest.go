@@ -0,0 +1,367 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+type digestTask struct {
+	ID      int64
+	Title   string
+	Entries []*notifications.DigestEntry
+}
+
+type digestProject struct {
+	ID    int64
+	Title string
+	// Entries which are about the project itself and not a task in it.
+	Entries []*notifications.DigestEntry
+	Tasks   []*digestTask
+}
+
+// NotificationDigestNotification represents a NotificationDigestNotification notification
+type NotificationDigestNotification struct {
+	User          *user.User
+	Mode          string
+	Projects      []*digestProject
+	UpcomingTasks []*Task
+
+	entries []*notifications.DigestEntry
+}
+
+// ToMail returns the mail notification for NotificationDigestNotification
+func (n *NotificationDigestNotification) ToMail(lang string) *notifications.Mail {
+	subject := i18n.T(lang, "notifications.digest.daily_subject")
+	upcomingLine := i18n.T(lang, "notifications.digest.upcoming_daily")
+	if n.Mode == user.DigestModeWeekly {
+		subject = i18n.T(lang, "notifications.digest.weekly_subject")
+		upcomingLine = i18n.T(lang, "notifications.digest.upcoming_weekly")
+	}
+
+	mail := notifications.NewMail().
+		Subject(subject).
+		Greeting(i18n.T(lang, "notifications.common.greeting", n.User.GetName()))
+
+	if len(n.Projects) > 0 {
+		mail.Line(i18n.T(lang, "notifications.digest.since_last"))
+	}
+
+	for _, p := range n.Projects {
+		mail.Line("**" + p.Title + "**")
+
+		lines := ""
+		for _, e := range p.Entries {
+			lines += "* " + e.Line + "\n"
+		}
+		for _, t := range p.Tasks {
+			lines += "* [" + t.Title + "](" + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(t.ID, 10) + ")\n"
+			for _, e := range t.Entries {
+				lines += "    * " + e.Line + "\n"
+			}
+		}
+		mail.Line(lines)
+	}
+
+	if len(n.UpcomingTasks) > 0 {
+		timezone := n.User.Timezone
+		if timezone == "" {
+			timezone = config.GetTimeZone().String()
+		}
+
+		lines := ""
+		for _, t := range n.UpcomingTasks {
+			due := i18n.T(lang, "notifications.digest.due", i18n.FormatDateTime(lang, t.DueDate, timezone))
+			lines += "* [" + t.Title + "](" + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(t.ID, 10) + "), " + due + "\n"
+		}
+		mail.Line(upcomingLine).
+			Line(lines)
+	}
+
+	return mail.
+		Action(i18n.T(lang, "notifications.common.actions.open_vikunja"), config.ServiceFrontendurl.GetString()).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
+}
+
+// ToDB returns the NotificationDigestNotification notification in a format which can be saved in the db
+func (n *NotificationDigestNotification) ToDB() interface{} {
+	return nil
+}
+
+// Name returns the name of the notification
+func (n *NotificationDigestNotification) Name() string {
+	return "notification.digest"
+}
+
+func isTimeForDigest(u *user.User, now time.Time, tz *time.Location) (bool, error) {
+	digestTime := u.DigestTime
+	if digestTime == "" {
+		digestTime = user.DefaultDigestTime
+	}
+
+	tm, err := time.Parse("15:04", digestTime)
+	if err != nil {
+		return false, err
+	}
+
+	local := now.In(tz)
+	if local.Hour() != tm.Hour() || local.Minute() != tm.Minute() {
+		return false, nil
+	}
+
+	if u.DigestMode == user.DigestModeWeekly {
+		return local.Weekday() == time.Weekday(u.WeekStart%7), nil
+	}
+
+	return true, nil
+}
+
+func getDigestProjects(s *xorm.Session, entries []*notifications.DigestEntry) (projects []*digestProject, err error) {
+	projectIDs := []int64{}
+	for _, e := range entries {
+		projectIDs = append(projectIDs, e.ProjectID)
+	}
+
+	projectMap := make(map[int64]*Project)
+	err = s.In("id", projectIDs).Find(&projectMap)
+	if err != nil {
+		return
+	}
+
+	digestProjects := make(map[int64]*digestProject)
+	digestTasks := make(map[int64]*digestTask)
+	for _, e := range entries {
+		dp, has := digestProjects[e.ProjectID]
+		if !has {
+			dp = &digestProject{ID: e.ProjectID}
+			if p, exists := projectMap[e.ProjectID]; exists {
+				dp.Title = p.Title
+			}
+			digestProjects[e.ProjectID] = dp
+			projects = append(projects, dp)
+		}
+
+		if e.TaskID == 0 {
+			dp.Entries = append(dp.Entries, e)
+			continue
+		}
+
+		dt, has := digestTasks[e.TaskID]
+		if !has {
+			dt = &digestTask{ID: e.TaskID}
+			digestTasks[e.TaskID] = dt
+			dp.Tasks = append(dp.Tasks, dt)
+		}
+		// Use the most recent title of the task
+		dt.Title = e.TaskTitle
+		dt.Entries = append(dt.Entries, e)
+	}
+
+	return
+}
+
+// getUpcomingTasksForUsers returns all undone tasks due within the next week for the given users, grouped by user.
+func getUpcomingTasksForUsers(s *xorm.Session, now time.Time, userIDs []int64) (tasksByUser map[int64][]*Task, err error) {
+	tasksByUser = make(map[int64][]*Task)
+
+	var tasks []*Task
+	err = s.
+		Where("due_date is not null AND due_date >= ? AND due_date < ? AND projects.is_archived = false",
+			now.Format(dbTimeFormat),
+			now.Add(7*24*time.Hour).Format(dbTimeFormat)).
+		Join("LEFT", "projects", "projects.id = tasks.project_id").
+		And("done = false").
+		Find(&tasks)
+	if err != nil || len(tasks) == 0 {
+		return
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	taskUsers, err := getTaskUsersForTasks(s, taskIDs, builder.In("users.id", userIDs))
+	if err != nil {
+		return
+	}
+
+	seen := make(map[int64]map[int64]bool)
+	for _, tu := range taskUsers {
+		if seen[tu.User.ID] == nil {
+			seen[tu.User.ID] = make(map[int64]bool)
+		}
+		if seen[tu.User.ID][tu.Task.ID] {
+			continue
+		}
+		seen[tu.User.ID][tu.Task.ID] = true
+		tasksByUser[tu.User.ID] = append(tasksByUser[tu.User.ID], tu.Task)
+	}
+
+	for _, userTasks := range tasksByUser {
+		sort.Slice(userTasks, func(i, j int) bool {
+			return userTasks[i].DueDate.Before(userTasks[j].DueDate)
+		})
+	}
+
+	return
+}
+
+// getDueNotificationDigests returns the digests of all users who want to get their digest right now.
+func getDueNotificationDigests(s *xorm.Session, now time.Time) (digests []*NotificationDigestNotification, err error) {
+	now = utils.GetTimeWithoutSeconds(now)
+
+	users := []*user.User{}
+	err = s.
+		Where("digest_mode IS NOT NULL AND digest_mode != ?", user.DigestModeNone).
+		And("status != ?", user.StatusDisabled).
+		Find(&users)
+	if err != nil || len(users) == 0 {
+		return
+	}
+
+	dueUsers := []*user.User{}
+	userIDs := []int64{}
+	tzs := make(map[string]*time.Location)
+	for _, u := range users {
+		if u.Timezone == "" {
+			u.Timezone = config.GetTimeZone().String()
+		}
+
+		tz, exists := tzs[u.Timezone]
+		if !exists {
+			tz, err = time.LoadLocation(u.Timezone)
+			if err != nil {
+				return nil, err
+			}
+			tzs[u.Timezone] = tz
+		}
+
+		isTime, err := isTimeForDigest(u, now, tz)
+		if err != nil {
+			return nil, err
+		}
+		if !isTime {
+			continue
+		}
+
+		dueUsers = append(dueUsers, u)
+		userIDs = append(userIDs, u.ID)
+	}
+
+	if len(dueUsers) == 0 {
+		return
+	}
+
+	entries, err := notifications.GetPendingDigestEntries(s, userIDs)
+	if err != nil {
+		return
+	}
+
+	upcoming, err := getUpcomingTasksForUsers(s, now, userIDs)
+	if err != nil {
+		return
+	}
+
+	for _, u := range dueUsers {
+		period := 24 * time.Hour
+		if u.DigestMode == user.DigestModeWeekly {
+			period = 7 * 24 * time.Hour
+		}
+
+		upcomingTasks := []*Task{}
+		for _, t := range upcoming[u.ID] {
+			if t.DueDate.Before(now.Add(period)) {
+				upcomingTasks = append(upcomingTasks, t)
+			}
+		}
+
+		if len(entries[u.ID]) == 0 && len(upcomingTasks) == 0 {
+			continue
+		}
+
+		projects, err := getDigestProjects(s, entries[u.ID])
+		if err != nil {
+			return nil, err
+		}
+
+		digests = append(digests, &NotificationDigestNotification{
+			User:          u,
+			Mode:          u.DigestMode,
+			Projects:      projects,
+			UpcomingTasks: upcomingTasks,
+			entries:       entries[u.ID],
+		})
+	}
+
+	return
+}
+
+// RegisterNotificationDigestCron registers a function which checks every minute if it is time to send the
+// notification digest to users who want one.
+func RegisterNotificationDigestCron() {
+	if !config.MailerEnabled.GetBool() {
+		log.Info("Mailer is disabled, not sending notification digests")
+		return
+	}
+
+	err := cron.Schedule("* * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		digests, err := getDueNotificationDigests(s, time.Now())
+		if err != nil {
+			log.Errorf("[Notification Digest] Could not get due notification digests: %s", err)
+			return
+		}
+
+		log.Debugf("[Notification Digest] Sending digests to %d users", len(digests))
+
+		for _, d := range digests {
+			err = notifications.Notify(d.User, d)
+			if err != nil {
+				log.Errorf("[Notification Digest] Could not notify user %d: %s", d.User.ID, err)
+				continue
+			}
+
+			err = notifications.DeleteDigestEntries(s, d.entries)
+			if err != nil {
+				log.Errorf("[Notification Digest] Could not remove sent digest entries of user %d: %s", d.User.ID, err)
+				continue
+			}
+
+			log.Debugf("[Notification Digest] Sent digest with %d entries to user %d", len(d.entries), d.User.ID)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register notification digest cron: %s", err)
+	}
+}
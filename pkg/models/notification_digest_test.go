@@ -0,0 +1,136 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDueNotificationDigests(t *testing.T) {
+	t.Run("daily", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			Where("id = ?", 1).
+			Cols("digest_mode", "digest_time", "timezone").
+			Update(&user.User{DigestMode: user.DigestModeDaily, DigestTime: "09:00", Timezone: "GMT"})
+		assert.NoError(t, err)
+		_, err = s.Insert(&notifications.DigestEntry{NotifiableID: 1, Name: "task.comment", ProjectID: 1, TaskID: 1, TaskTitle: "task #1", Line: "user2 commented: hi"})
+		assert.NoError(t, err)
+		_, err = s.Insert(&notifications.DigestEntry{NotifiableID: 1, Name: "task.assigned", ProjectID: 1, TaskID: 1, TaskTitle: "task #1", Line: "user2 assigned this task to user1"})
+		assert.NoError(t, err)
+		_, err = s.Insert(&notifications.DigestEntry{NotifiableID: 1, Name: "project.created", ProjectID: 3, Line: `user2 created the project "Test3"`})
+		assert.NoError(t, err)
+
+		now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+		digests, err := getDueNotificationDigests(s, now)
+		assert.NoError(t, err)
+		assert.Len(t, digests, 1)
+		d := digests[0]
+		assert.Equal(t, int64(1), d.User.ID)
+		assert.Len(t, d.entries, 3)
+		assert.Len(t, d.Projects, 2)
+		assert.Equal(t, int64(1), d.Projects[0].ID)
+		assert.Equal(t, "Test1", d.Projects[0].Title)
+		assert.Len(t, d.Projects[0].Tasks, 1)
+		assert.Len(t, d.Projects[0].Tasks[0].Entries, 2)
+		assert.Len(t, d.Projects[1].Entries, 1)
+
+		// Task 6 and 5 are due within the next day
+		assert.Len(t, d.UpcomingTasks, 2)
+		assert.Equal(t, int64(6), d.UpcomingTasks[0].ID)
+		assert.Equal(t, int64(5), d.UpcomingTasks[1].ID)
+	})
+	t.Run("default time", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			Where("id = ?", 1).
+			Cols("digest_mode", "timezone").
+			Update(&user.User{DigestMode: user.DigestModeDaily, Timezone: "GMT"})
+		assert.NoError(t, err)
+		_, err = s.Insert(&notifications.DigestEntry{NotifiableID: 1, Name: "task.comment", ProjectID: 1, TaskID: 1, TaskTitle: "task #1", Line: "user2 commented: hi"})
+		assert.NoError(t, err)
+
+		now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+		digests, err := getDueNotificationDigests(s, now)
+		assert.NoError(t, err)
+		assert.Len(t, digests, 1)
+	})
+	t.Run("not yet time", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			Where("id = ?", 1).
+			Cols("digest_mode", "digest_time", "timezone").
+			Update(&user.User{DigestMode: user.DigestModeDaily, DigestTime: "10:00", Timezone: "GMT"})
+		assert.NoError(t, err)
+
+		now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+		digests, err := getDueNotificationDigests(s, now)
+		assert.NoError(t, err)
+		assert.Empty(t, digests)
+	})
+	t.Run("weekly on another day", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// 2018-11-30 is a friday, the week of the user starts on monday
+		_, err := s.
+			Where("id = ?", 1).
+			Cols("digest_mode", "digest_time", "timezone", "week_start").
+			Update(&user.User{DigestMode: user.DigestModeWeekly, DigestTime: "09:00", Timezone: "GMT", WeekStart: 1})
+		assert.NoError(t, err)
+
+		now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+		digests, err := getDueNotificationDigests(s, now)
+		assert.NoError(t, err)
+		assert.Empty(t, digests)
+	})
+	t.Run("nothing to send", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			Where("id = ?", 2).
+			Cols("digest_mode", "digest_time", "timezone").
+			Update(&user.User{DigestMode: user.DigestModeDaily, DigestTime: "09:00", Timezone: "GMT"})
+		assert.NoError(t, err)
+		_, err = s.Where("notifiable_id = ?", 2).Delete(&notifications.DigestEntry{})
+		assert.NoError(t, err)
+
+		now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+		digests, err := getDueNotificationDigests(s, now)
+		assert.NoError(t, err)
+		assert.Empty(t, digests)
+	})
+}
@@ -0,0 +1,176 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// ConfigurableNotifications holds the names of all notifications a user can configure preferences for.
+// Security related notifications like password resets are always sent and therefore not part of this.
+var ConfigurableNotifications = []string{
+	(&TaskCommentNotification{}).Name(),
+	(&TaskAssignedNotification{}).Name(),
+	(&TaskDeletedNotification{}).Name(),
+	(&UserMentionedInTaskNotification{}).Name(),
+	(&ReminderDueNotification{}).Name(),
+	(&UndoneTaskOverdueNotification{}).Name(),
+	(&ProjectCreatedNotification{}).Name(),
+	(&TeamMemberAddedNotification{}).Name(),
+}
+
+func isConfigurableNotification(name string) bool {
+	for _, n := range ConfigurableNotifications {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreferences is a wrapper around the crud operations that come with a notification preference.
+type NotificationPreferences struct {
+	notifications.NotificationPreference
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// ReadAll returns the notification preferences of the current user. It contains one global entry per configurable
+// notification, even if the user did not change it, followed by all project specific overrides.
+// @Summary Get all notification preferences of the current user
+// @Description Returns the global preference for every notification which can be configured and all project specific overrides. Notifications are sent via mail, shown in the app and sent to all push targets by default.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} models.NotificationPreferences "The notification preferences"
+// @Failure 403 {object} web.HTTPError "Link shares cannot have notification preferences."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications [get]
+func (np *NotificationPreferences) ReadAll(s *xorm.Session, a web.Auth, _ string, _ int, _ int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	stored, err := notifications.GetNotificationPreferencesForNotifiable(s, a.GetID())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	global := make(map[string]*notifications.NotificationPreference)
+	overrides := []*NotificationPreferences{}
+	for _, p := range stored {
+		if !isConfigurableNotification(p.Name) {
+			continue
+		}
+		if p.ProjectID == 0 {
+			global[p.Name] = p
+			continue
+		}
+		overrides = append(overrides, &NotificationPreferences{NotificationPreference: *p})
+	}
+
+	preferences := make([]*NotificationPreferences, 0, len(ConfigurableNotifications)+len(overrides))
+	for _, name := range ConfigurableNotifications {
+		p, has := global[name]
+		if !has {
+			p = &notifications.NotificationPreference{
+				Name:  name,
+				Mail:  true,
+				InApp: true,
+				Push:  true,
+			}
+		}
+		preferences = append(preferences, &NotificationPreferences{NotificationPreference: *p})
+	}
+	preferences = append(preferences, overrides...)
+
+	return preferences, len(preferences), int64(len(preferences)), nil
+}
+
+// Create creates or updates a notification preference
+// @Summary Set a notification preference
+// @Description Sets through which channels the current user wants to receive a notification. If a project id is provided, the preference only applies to notifications about that project and overrides the global one. Existing preferences for the same notification and project are updated.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param preference body models.NotificationPreferences true "The notification preference."
+// @Success 201 {object} models.NotificationPreferences "The saved preference."
+// @Failure 400 {object} web.HTTPError "The notification cannot be configured."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications [put]
+func (np *NotificationPreferences) Create(s *xorm.Session, a web.Auth) (err error) {
+	if !isConfigurableNotification(np.Name) {
+		return &ErrInvalidNotificationName{Name: np.Name}
+	}
+
+	np.NotifiableID = a.GetID()
+	return notifications.SaveNotificationPreference(s, &np.NotificationPreference)
+}
+
+// Delete removes a notification preference
+// @Summary Remove a notification preference
+// @Description Removes a notification preference. Afterwards, the global preference or the default is used again.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param preference path int true "The id of the preference"
+// @Success 200 {object} models.Message "The preference was removed."
+// @Failure 404 {object} web.HTTPError "The preference does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/{preference} [delete]
+func (np *NotificationPreferences) Delete(s *xorm.Session, a web.Auth) (err error) {
+	deleted, err := notifications.DeleteNotificationPreference(s, a.GetID(), np.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return &ErrNotificationPreferenceDoesNotExist{ID: np.ID}
+	}
+	return nil
+}
+
+// CanCreate checks if a user can set a notification preference
+func (np *NotificationPreferences) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	if np.ProjectID == 0 {
+		return true, nil
+	}
+
+	p := &Project{ID: np.ProjectID}
+	can, _, err := p.CanRead(s, a)
+	return can, err
+}
+
+// CanDelete checks if a user can remove a notification preference
+func (np *NotificationPreferences) CanDelete(_ *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	// Users can only delete their own preferences, which is checked in Delete
+	return true, nil
+}
@@ -0,0 +1,159 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferences_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("global", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		np := &NotificationPreferences{}
+		np.Name = "task.comment"
+		np.Mail = false
+		np.InApp = true
+		err := np.Create(s, u)
+		assert.NoError(t, err)
+		assert.NotEqual(t, int64(0), np.ID)
+
+		// Setting it again should update the existing one
+		np2 := &NotificationPreferences{}
+		np2.Name = "task.comment"
+		np2.Mail = true
+		np2.InApp = false
+		err = np2.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, np.ID, np2.ID)
+
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "notification_preferences", map[string]interface{}{
+			"id":            np.ID,
+			"notifiable_id": 1,
+			"name":          "task.comment",
+			"project_id":    0,
+			"mail":          true,
+			"in_app":        false,
+		}, false)
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		np := &NotificationPreferences{}
+		np.Name = "user.deletion"
+		err := np.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationName(err))
+	})
+	t.Run("project without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		np := &NotificationPreferences{}
+		np.Name = "task.comment"
+		np.ProjectID = 20
+		can, err := np.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		np := &NotificationPreferences{}
+		np.Name = "task.comment"
+		can, err := np.CanCreate(s, &LinkSharing{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestNotificationPreferences_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	u := &user.User{ID: 1}
+	np := &NotificationPreferences{}
+	np.Name = "task.assigned"
+	np.ProjectID = 1
+	np.Mail = false
+	err := np.Create(s, u)
+	assert.NoError(t, err)
+
+	res, count, _, err := (&NotificationPreferences{}).ReadAll(s, u, "", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, len(ConfigurableNotifications)+1, count)
+	preferences := res.([]*NotificationPreferences)
+	for i, name := range ConfigurableNotifications {
+		assert.Equal(t, name, preferences[i].Name)
+		assert.Equal(t, int64(0), preferences[i].ProjectID)
+		assert.True(t, preferences[i].Mail)
+		assert.True(t, preferences[i].InApp)
+		assert.True(t, preferences[i].Push)
+	}
+	override := preferences[len(preferences)-1]
+	assert.Equal(t, "task.assigned", override.Name)
+	assert.Equal(t, int64(1), override.ProjectID)
+	assert.False(t, override.Mail)
+}
+
+func TestNotificationPreferences_Delete(t *testing.T) {
+	t.Run("own", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		np := &NotificationPreferences{}
+		np.Name = "task.comment"
+		err := np.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+
+		err = (&NotificationPreferences{NotificationPreference: notifications.NotificationPreference{ID: np.ID}}).Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+	})
+	t.Run("someone else's", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		np := &NotificationPreferences{}
+		np.Name = "task.comment"
+		err := np.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+
+		err = (&NotificationPreferences{NotificationPreference: notifications.NotificationPreference{ID: np.ID}}).Delete(s, &user.User{ID: 2})
+		assert.Error(t, err)
+		assert.True(t, IsErrNotificationPreferenceDoesNotExist(err))
+	})
+}
@@ -0,0 +1,93 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+)
+
+// GetNotificationPreviews returns all notifications of this package filled with sample data.
+// They are used to preview how notification mails look like with the configured templates.
+func GetNotificationPreviews() map[string]notifications.Notification {
+	doer := &user.User{ID: 1, Username: "frederick", Name: "Frederick"}
+	recipient := &user.User{ID: 2, Username: "alice", Name: "Alice", Timezone: config.GetTimeZone().String()}
+
+	project := &Project{ID: 1, Title: "Groceries", Identifier: "GROC"}
+	task := &Task{
+		ID:          1,
+		Title:       "Buy milk",
+		Description: "Two liters, the good one from the farm.",
+		ProjectID:   project.ID,
+		Identifier:  "GROC-1",
+		Index:       1,
+		DueDate:     time.Now().Add(26 * time.Hour).Round(time.Hour),
+	}
+	overdueTask := &Task{
+		ID:         2,
+		Title:      "Bake bread",
+		ProjectID:  project.ID,
+		Identifier: "GROC-2",
+		Index:      2,
+		DueDate:    time.Now().Add(-50 * time.Hour).Round(time.Hour),
+	}
+	comment := &TaskComment{ID: 1, TaskID: task.ID, Comment: "Don't forget the oat milk!", Author: doer}
+	team := &Team{ID: 1, Name: "Household"}
+
+	return map[string]notifications.Notification{
+		"task.reminder":          &ReminderDueNotification{User: recipient, Task: task},
+		"task.comment":           &TaskCommentNotification{Doer: doer, Task: task, Comment: comment},
+		"task.comment.mentioned": &TaskCommentNotification{Doer: doer, Task: task, Comment: comment, Mentioned: true},
+		"task.assigned":          &TaskAssignedNotification{Doer: doer, Task: task, Assignee: recipient},
+		"task.deleted":           &TaskDeletedNotification{Doer: doer, Task: task},
+		"project.created":        &ProjectCreatedNotification{Doer: doer, Project: project},
+		"team.member.added":      &TeamMemberAddedNotification{Doer: doer, Member: recipient, Team: team},
+		"task.undone.overdue":    &UndoneTaskOverdueNotification{User: recipient, Task: overdueTask},
+		"task.undone.overdue.multiple": &UndoneTasksOverdueNotification{
+			User:  recipient,
+			Tasks: map[int64]*Task{task.ID: task, overdueTask.ID: overdueTask},
+		},
+		"task.mentioned":    &UserMentionedInTaskNotification{Doer: doer, Task: task, IsNew: true},
+		"data.export.ready": &DataExportReadyNotification{User: recipient},
+		"notification.digest": &NotificationDigestNotification{
+			User: recipient,
+			Mode: user.DigestModeDaily,
+			Projects: []*digestProject{
+				{
+					ID:    project.ID,
+					Title: project.Title,
+					Entries: []*notifications.DigestEntry{
+						{Line: doer.GetName() + ` created the project "` + project.Title + `"`},
+					},
+					Tasks: []*digestTask{
+						{
+							ID:    task.ID,
+							Title: task.Title,
+							Entries: []*notifications.DigestEntry{
+								{Line: doer.GetName() + " commented: " + comment.Comment},
+							},
+						},
+					},
+				},
+			},
+			UpcomingTasks: []*Task{task},
+		},
+	}
+}
@@ -23,9 +23,8 @@ import (
 	"strings"
 	"time"
 
-	"code.vikunja.io/api/pkg/utils"
-
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/user"
 )
@@ -36,25 +35,45 @@ type ReminderDueNotification struct {
 	Task *Task      `json:"task"`
 }
 
+// ProjectID returns the id of the project the task of this notification belongs to
+func (n *ReminderDueNotification) ProjectID() int64 {
+	return n.Task.ProjectID
+}
+
 // ToMail returns the mail notification for ReminderDueNotification
-func (n *ReminderDueNotification) ToMail() *notifications.Mail {
-	return notifications.NewMail().
+func (n *ReminderDueNotification) ToMail(lang string) *notifications.Mail {
+	mail := notifications.NewMail().
 		To(n.User.Email).
-		Subject(`Reminder for "`+n.Task.Title+`"`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line(`This is a friendly reminder of the task "`+n.Task.Title+`".`).
-		Action("Open Task", config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.task.reminder.subject", n.Task.Title)).
+		Greeting(i18n.T(lang, "notifications.common.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.task.reminder.message", n.Task.Title))
+
+	if !n.Task.DueDate.IsZero() {
+		mail.Line(i18n.T(lang, "notifications.task.reminder.due", i18n.FormatDateTime(lang, n.Task.DueDate, n.User.Timezone)))
+	}
+
+	return mail.
+		Action(i18n.T(lang, "notifications.common.actions.open_task"), config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the ReminderDueNotification notification in a format which can be saved in the db
 func (n *ReminderDueNotification) ToDB() interface{} {
-	return nil
+	return n
 }
 
 // Name returns the name of the notification
 func (n *ReminderDueNotification) Name() string {
-	return ""
+	return "task.reminder"
+}
+
+// ToPush returns the ReminderDueNotification as a push message
+func (n *ReminderDueNotification) ToPush(lang string) *notifications.PushMessage {
+	return &notifications.PushMessage{
+		Title: i18n.T(lang, "notifications.task.reminder.subject", n.Task.Title),
+		Body:  i18n.T(lang, "notifications.task.reminder.message", n.Task.Title),
+		URL:   n.Task.GetFrontendURL(),
+	}
 }
 
 // TaskCommentNotification represents a TaskCommentNotification notification
@@ -69,16 +88,21 @@ func (n *TaskCommentNotification) SubjectID() int64 {
 	return n.Comment.ID
 }
 
+// ProjectID returns the id of the project the task of this notification belongs to
+func (n *TaskCommentNotification) ProjectID() int64 {
+	return n.Task.ProjectID
+}
+
 // ToMail returns the mail notification for TaskCommentNotification
-func (n *TaskCommentNotification) ToMail() *notifications.Mail {
+func (n *TaskCommentNotification) ToMail(lang string) *notifications.Mail {
 
 	mail := notifications.NewMail().
 		From(n.Doer.GetNameAndFromEmail())
 
-	subject := "Re: " + n.Task.Title
+	subject := i18n.T(lang, "notifications.task.comment.subject", n.Task.Title)
 	if n.Mentioned {
-		subject = n.Doer.GetName() + ` mentioned you in a comment in "` + n.Task.Title + `"`
-		mail.Line("**" + n.Doer.GetName() + "** mentioned you in a comment:")
+		subject = i18n.T(lang, "notifications.task.comment.mentioned_subject", n.Doer.GetName(), n.Task.Title)
+		mail.Line(i18n.T(lang, "notifications.task.comment.mentioned_message", n.Doer.GetName()))
 	}
 
 	mail.Subject(subject)
@@ -89,7 +113,7 @@ func (n *TaskCommentNotification) ToMail() *notifications.Mail {
 	}
 
 	return mail.
-		Action("View Task", n.Task.GetFrontendURL())
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the TaskCommentNotification notification in a format which can be saved in the db
@@ -102,6 +126,44 @@ func (n *TaskCommentNotification) Name() string {
 	return "task.comment"
 }
 
+// ToDigest returns the TaskCommentNotification as part of a digest mail
+func (n *TaskCommentNotification) ToDigest(lang string) *notifications.DigestEntry {
+	line := i18n.T(lang, "notifications.task.comment.digest", n.Doer.GetName())
+	if n.Mentioned {
+		line = i18n.T(lang, "notifications.task.comment.mentioned_digest", n.Doer.GetName())
+	}
+
+	comment := strings.TrimSpace(n.Comment.Comment)
+	if comment != "" {
+		line += ": " + strings.SplitN(comment, "\n", 2)[0]
+	}
+
+	return &notifications.DigestEntry{
+		ProjectID: n.Task.ProjectID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Line:      line,
+	}
+}
+
+// ToPush returns the TaskCommentNotification as a push message. Only mentions are sent as push messages.
+func (n *TaskCommentNotification) ToPush(lang string) *notifications.PushMessage {
+	if !n.Mentioned {
+		return nil
+	}
+
+	return &notifications.PushMessage{
+		Title: i18n.T(lang, "notifications.task.comment.mentioned_subject", n.Doer.GetName(), n.Task.Title),
+		Body:  strings.TrimSpace(n.Comment.Comment),
+		URL:   n.Task.GetFrontendURL(),
+	}
+}
+
+// ReplyTo returns an address the notified user can reply to in order to comment on the task.
+func (n *TaskCommentNotification) ReplyTo(notifiable notifications.Notifiable) string {
+	return GetReplyAddress(notifiable.RouteForDB(), n.Task.ID)
+}
+
 // TaskAssignedNotification represents a TaskAssignedNotification notification
 type TaskAssignedNotification struct {
 	Doer     *user.User `json:"doer"`
@@ -109,12 +171,17 @@ type TaskAssignedNotification struct {
 	Assignee *user.User `json:"assignee"`
 }
 
+// ProjectID returns the id of the project the task of this notification belongs to
+func (n *TaskAssignedNotification) ProjectID() int64 {
+	return n.Task.ProjectID
+}
+
 // ToMail returns the mail notification for TaskAssignedNotification
-func (n *TaskAssignedNotification) ToMail() *notifications.Mail {
+func (n *TaskAssignedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Task.Title+"("+n.Task.GetFullIdentifier()+")"+" has been assigned to "+n.Assignee.GetName()).
-		Line(n.Doer.GetName()+" has assigned this task to "+n.Assignee.GetName()+".").
-		Action("View Task", n.Task.GetFrontendURL())
+		Subject(i18n.T(lang, "notifications.task.assigned.subject", n.Task.Title, n.Task.GetFullIdentifier(), n.Assignee.GetName())).
+		Line(i18n.T(lang, "notifications.task.assigned.message", n.Doer.GetName(), n.Assignee.GetName())).
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the TaskAssignedNotification notification in a format which can be saved in the db
@@ -127,17 +194,41 @@ func (n *TaskAssignedNotification) Name() string {
 	return "task.assigned"
 }
 
+// ToDigest returns the TaskAssignedNotification as part of a digest mail
+func (n *TaskAssignedNotification) ToDigest(lang string) *notifications.DigestEntry {
+	return &notifications.DigestEntry{
+		ProjectID: n.Task.ProjectID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Line:      i18n.T(lang, "notifications.task.assigned.digest", n.Doer.GetName(), n.Assignee.GetName()),
+	}
+}
+
+// ToPush returns the TaskAssignedNotification as a push message
+func (n *TaskAssignedNotification) ToPush(lang string) *notifications.PushMessage {
+	return &notifications.PushMessage{
+		Title: n.Task.Title + " (" + n.Task.GetFullIdentifier() + ")",
+		Body:  i18n.T(lang, "notifications.task.assigned.message", n.Doer.GetName(), n.Assignee.GetName()),
+		URL:   n.Task.GetFrontendURL(),
+	}
+}
+
 // TaskDeletedNotification represents a TaskDeletedNotification notification
 type TaskDeletedNotification struct {
 	Doer *user.User `json:"doer"`
 	Task *Task      `json:"task"`
 }
 
+// ProjectID returns the id of the project the task of this notification belongs to
+func (n *TaskDeletedNotification) ProjectID() int64 {
+	return n.Task.ProjectID
+}
+
 // ToMail returns the mail notification for TaskDeletedNotification
-func (n *TaskDeletedNotification) ToMail() *notifications.Mail {
+func (n *TaskDeletedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Task.Title + " (" + n.Task.GetFullIdentifier() + ")" + " has been deleted").
-		Line(n.Doer.GetName() + " has deleted the task " + n.Task.Title + " (" + n.Task.GetFullIdentifier() + ")")
+		Subject(i18n.T(lang, "notifications.task.deleted.subject", n.Task.Title, n.Task.GetFullIdentifier())).
+		Line(i18n.T(lang, "notifications.task.deleted.message", n.Doer.GetName(), n.Task.Title, n.Task.GetFullIdentifier()))
 }
 
 // ToDB returns the TaskDeletedNotification notification in a format which can be saved in the db
@@ -150,18 +241,33 @@ func (n *TaskDeletedNotification) Name() string {
 	return "task.deleted"
 }
 
+// ToDigest returns the TaskDeletedNotification as part of a digest mail
+func (n *TaskDeletedNotification) ToDigest(lang string) *notifications.DigestEntry {
+	return &notifications.DigestEntry{
+		ProjectID: n.Task.ProjectID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Line:      i18n.T(lang, "notifications.task.deleted.digest", n.Doer.GetName()),
+	}
+}
+
 // ProjectCreatedNotification represents a ProjectCreatedNotification notification
 type ProjectCreatedNotification struct {
 	Doer    *user.User `json:"doer"`
 	Project *Project   `json:"project"`
 }
 
+// ProjectID returns the id of the created project
+func (n *ProjectCreatedNotification) ProjectID() int64 {
+	return n.Project.ID
+}
+
 // ToMail returns the mail notification for ProjectCreatedNotification
-func (n *ProjectCreatedNotification) ToMail() *notifications.Mail {
+func (n *ProjectCreatedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Doer.GetName()+` created the project "`+n.Project.Title+`"`).
-		Line(n.Doer.GetName()+` created the project "`+n.Project.Title+`"`).
-		Action("View Project", config.ServiceFrontendurl.GetString()+"projects/")
+		Subject(i18n.T(lang, "notifications.project.created.subject", n.Doer.GetName(), n.Project.Title)).
+		Line(i18n.T(lang, "notifications.project.created.message", n.Doer.GetName(), n.Project.Title)).
+		Action(i18n.T(lang, "notifications.common.actions.view_project"), config.ServiceFrontendurl.GetString()+"projects/")
 }
 
 // ToDB returns the ProjectCreatedNotification notification in a format which can be saved in the db
@@ -174,6 +280,14 @@ func (n *ProjectCreatedNotification) Name() string {
 	return "project.created"
 }
 
+// ToDigest returns the ProjectCreatedNotification as part of a digest mail
+func (n *ProjectCreatedNotification) ToDigest(lang string) *notifications.DigestEntry {
+	return &notifications.DigestEntry{
+		ProjectID: n.Project.ID,
+		Line:      i18n.T(lang, "notifications.project.created.message", n.Doer.GetName(), n.Project.Title),
+	}
+}
+
 // TeamMemberAddedNotification represents a TeamMemberAddedNotification notification
 type TeamMemberAddedNotification struct {
 	Member *user.User `json:"member"`
@@ -182,13 +296,13 @@ type TeamMemberAddedNotification struct {
 }
 
 // ToMail returns the mail notification for TeamMemberAddedNotification
-func (n *TeamMemberAddedNotification) ToMail() *notifications.Mail {
+func (n *TeamMemberAddedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Doer.GetName()+" added you to the "+n.Team.Name+" team in Vikunja").
+		Subject(i18n.T(lang, "notifications.team.member_added.subject", n.Doer.GetName(), n.Team.Name)).
 		From(n.Doer.GetNameAndFromEmail()).
-		Greeting("Hi "+n.Member.GetName()+",").
-		Line(n.Doer.GetName()+" has just added you to the "+n.Team.Name+" team in Vikunja.").
-		Action("View Team", config.ServiceFrontendurl.GetString()+"teams/"+strconv.FormatInt(n.Team.ID, 10)+"/edit")
+		Greeting(i18n.T(lang, "notifications.common.greeting", n.Member.GetName())).
+		Line(i18n.T(lang, "notifications.team.member_added.message", n.Doer.GetName(), n.Team.Name)).
+		Action(i18n.T(lang, "notifications.common.actions.view_team"), config.ServiceFrontendurl.GetString()+"teams/"+strconv.FormatInt(n.Team.ID, 10)+"/edit")
 }
 
 // ToDB returns the TeamMemberAddedNotification notification in a format which can be saved in the db
@@ -207,15 +321,20 @@ type UndoneTaskOverdueNotification struct {
 	Task *Task
 }
 
+// ProjectID returns the id of the project the task of this notification belongs to
+func (n *UndoneTaskOverdueNotification) ProjectID() int64 {
+	return n.Task.ProjectID
+}
+
 // ToMail returns the mail notification for UndoneTaskOverdueNotification
-func (n *UndoneTaskOverdueNotification) ToMail() *notifications.Mail {
+func (n *UndoneTaskOverdueNotification) ToMail(lang string) *notifications.Mail {
 	until := time.Until(n.Task.DueDate).Round(1*time.Hour) * -1
 	return notifications.NewMail().
-		Subject(`Task "`+n.Task.Title+`" is overdue`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line(`This is a friendly reminder of the task "`+n.Task.Title+`" which is overdue since `+utils.HumanizeDuration(until)+` and not yet done.`).
-		Action("Open Task", config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.task.overdue.subject", n.Task.Title)).
+		Greeting(i18n.T(lang, "notifications.common.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.task.overdue.message", n.Task.Title, i18n.HumanizeDuration(lang, until))).
+		Action(i18n.T(lang, "notifications.common.actions.open_task"), config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the UndoneTaskOverdueNotification notification in a format which can be saved in the db
@@ -235,7 +354,7 @@ type UndoneTasksOverdueNotification struct {
 }
 
 // ToMail returns the mail notification for UndoneTasksOverdueNotification
-func (n *UndoneTasksOverdueNotification) ToMail() *notifications.Mail {
+func (n *UndoneTasksOverdueNotification) ToMail(lang string) *notifications.Mail {
 
 	sortedTasks := make([]*Task, 0, len(n.Tasks))
 	for _, task := range n.Tasks {
@@ -249,16 +368,16 @@ func (n *UndoneTasksOverdueNotification) ToMail() *notifications.Mail {
 	overdueLine := ""
 	for _, task := range sortedTasks {
 		until := time.Until(task.DueDate).Round(1*time.Hour) * -1
-		overdueLine += `* [` + task.Title + `](` + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10) + `), overdue since ` + utils.HumanizeDuration(until) + "\n"
+		overdueLine += `* [` + task.Title + `](` + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10) + `), ` + i18n.T(lang, "notifications.task.overdue.multiple_line", i18n.HumanizeDuration(lang, until)) + "\n"
 	}
 
 	return notifications.NewMail().
-		Subject(`Your overdue tasks`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("You have the following overdue tasks:").
+		Subject(i18n.T(lang, "notifications.task.overdue.multiple_subject")).
+		Greeting(i18n.T(lang, "notifications.common.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.task.overdue.multiple_message")).
 		Line(overdueLine).
-		Action("Open Vikunja", config.ServiceFrontendurl.GetString()).
-		Line("Have a nice day!")
+		Action(i18n.T(lang, "notifications.common.actions.open_vikunja"), config.ServiceFrontendurl.GetString()).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the UndoneTasksOverdueNotification notification in a format which can be saved in the db
@@ -282,17 +401,22 @@ func (n *UserMentionedInTaskNotification) SubjectID() int64 {
 	return n.Task.ID
 }
 
+// ProjectID returns the id of the project the task of this notification belongs to
+func (n *UserMentionedInTaskNotification) ProjectID() int64 {
+	return n.Task.ProjectID
+}
+
 // ToMail returns the mail notification for UserMentionedInTaskNotification
-func (n *UserMentionedInTaskNotification) ToMail() *notifications.Mail {
-	subject := n.Doer.GetName() + ` mentioned you in a new task "` + n.Task.Title + `"`
+func (n *UserMentionedInTaskNotification) ToMail(lang string) *notifications.Mail {
+	subject := i18n.T(lang, "notifications.task.mentioned.subject_new", n.Doer.GetName(), n.Task.Title)
 	if n.IsNew {
-		subject = n.Doer.GetName() + ` mentioned you in a task "` + n.Task.Title + `"`
+		subject = i18n.T(lang, "notifications.task.mentioned.subject", n.Doer.GetName(), n.Task.Title)
 	}
 
 	mail := notifications.NewMail().
 		From(n.Doer.GetNameAndFromEmail()).
 		Subject(subject).
-		Line("**" + n.Doer.GetName() + "** mentioned you in a task:")
+		Line(i18n.T(lang, "notifications.task.mentioned.message", n.Doer.GetName()))
 
 	lines := bufio.NewScanner(strings.NewReader(n.Task.Description))
 	for lines.Scan() {
@@ -300,7 +424,7 @@ func (n *UserMentionedInTaskNotification) ToMail() *notifications.Mail {
 	}
 
 	return mail.
-		Action("View Task", n.Task.GetFrontendURL())
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the UserMentionedInTaskNotification notification in a format which can be saved in the db
@@ -313,20 +437,39 @@ func (n *UserMentionedInTaskNotification) Name() string {
 	return "task.mentioned"
 }
 
+// ToDigest returns the UserMentionedInTaskNotification as part of a digest mail
+func (n *UserMentionedInTaskNotification) ToDigest(lang string) *notifications.DigestEntry {
+	return &notifications.DigestEntry{
+		ProjectID: n.Task.ProjectID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Line:      i18n.T(lang, "notifications.task.mentioned.digest", n.Doer.GetName()),
+	}
+}
+
+// ToPush returns the UserMentionedInTaskNotification as a push message
+func (n *UserMentionedInTaskNotification) ToPush(lang string) *notifications.PushMessage {
+	return &notifications.PushMessage{
+		Title: i18n.T(lang, "notifications.task.mentioned.subject", n.Doer.GetName(), n.Task.Title),
+		Body:  strings.TrimSpace(n.Task.Description),
+		URL:   n.Task.GetFrontendURL(),
+	}
+}
+
 // DataExportReadyNotification represents a DataExportReadyNotification notification
 type DataExportReadyNotification struct {
 	User *user.User `json:"user"`
 }
 
 // ToMail returns the mail notification for DataExportReadyNotification
-func (n *DataExportReadyNotification) ToMail() *notifications.Mail {
+func (n *DataExportReadyNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Your Vikunja Data Export is ready").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("Your Vikunja Data Export is ready for you to download. Click the button below to download it:").
-		Action("Download", config.ServiceFrontendurl.GetString()+"user/export/download").
-		Line("The download will be available for the next 7 days.").
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.data_export.ready.subject")).
+		Greeting(i18n.T(lang, "notifications.common.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.data_export.ready.message")).
+		Action(i18n.T(lang, "notifications.data_export.ready.action"), config.ServiceFrontendurl.GetString()+"user/export/download").
+		Line(i18n.T(lang, "notifications.data_export.ready.availability")).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the DataExportReadyNotification notification in a format which can be saved in the db
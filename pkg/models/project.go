@@ -55,6 +55,19 @@ type Project struct {
 	DefaultBucketID int64 `xorm:"bigint INDEX null" json:"default_bucket_id"`
 	// If tasks are moved to the done bucket, they are marked as done. If they are marked as done individually, they are moved into the done bucket.
 	DoneBucketID int64 `xorm:"bigint INDEX null" json:"done_bucket_id"`
+	// Reminders relative to the due, start or end date which are added to tasks in this project once they get that date.
+	// If set, they are used instead of the default reminders of the user creating or editing the task.
+	DefaultReminders []*user.DefaultReminder `xorm:"json null" json:"default_reminders"`
+	// If true, tasks in this project cannot be marked as done or moved into the done bucket while they are blocked by open tasks.
+	EnforceBlockingTasks bool `xorm:"not null default false" json:"enforce_blocking_tasks"`
+	// If true, all tasks following a task of this project through "precedes" relations are moved along when its dates change.
+	AutoScheduling bool `xorm:"not null default false" json:"auto_scheduling"`
+	// If true, automatically scheduled tasks are moved by working days, skipping saturdays and sundays.
+	SchedulingSkipWeekends bool `xorm:"not null default false" json:"scheduling_skip_weekends"`
+	// If true, the percent done of tasks with subtasks is computed from their subtasks, weighted by their estimate.
+	ProgressFromSubtasks bool `xorm:"not null default false" json:"progress_from_subtasks"`
+	// If true, tasks with subtasks are marked as done once all of their subtasks are done, and as undone again if one is reopened.
+	DoneFromSubtasks bool `xorm:"not null default false" json:"done_from_subtasks"`
 
 	// The user who created this project.
 	Owner *user.User `xorm:"-" json:"owner" valid:"-"`
@@ -62,6 +75,14 @@ type Project struct {
 	// Whether a project is archived.
 	IsArchived bool `xorm:"not null default false" json:"is_archived" query:"is_archived"`
 
+	// Whether a project is published. Published projects have a public, read-only roadmap page and an atom feed
+	// which can be accessed without authentication at /public/projects/{public_hash}.
+	IsPublished bool `xorm:"not null default false" json:"is_published"`
+	// The hash used in the public urls of this project. Only set if the project is published. You cannot change this value.
+	PublicHash string `xorm:"varchar(40) INDEX null" json:"public_hash"`
+	// The secret part of the address emails can be sent to in order to create tasks in this project.
+	InboundEmailHash string `xorm:"varchar(40) INDEX null" json:"-"`
+
 	// The id of the file this project has set as background
 	BackgroundFileID int64 `xorm:"null" json:"-"`
 	// Holds extra information about the background set since some background providers require attribution or similar. If not null, the background can be accessed at /projects/{projectID}/background
@@ -142,6 +163,11 @@ func (p *Project) ReadAll(s *xorm.Session, a web.Auth, search string, page int,
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
+		// Link shares of a single task don't give access to the project
+		if shareAuth.TaskID != 0 {
+			return []*Project{}, 0, 0, nil
+		}
+
 		project, err := GetProjectSimpleByID(s, shareAuth.ProjectID)
 		if err != nil {
 			return nil, 0, 0, err
@@ -628,6 +654,11 @@ func (p *Project) CheckIsArchived(s *xorm.Session) (err error) {
 }
 
 func checkProjectBeforeUpdateOrDelete(s *xorm.Session, project *Project) (err error) {
+	err = user.ValidateDefaultReminders(project.DefaultReminders)
+	if err != nil {
+		return err
+	}
+
 	if project.ParentProjectID < 0 {
 		return &ErrProjectCannotBelongToAPseudoParentProject{ProjectID: project.ID, ParentProjectID: project.ParentProjectID}
 	}
@@ -700,6 +731,7 @@ func CreateProject(s *xorm.Session, project *Project, auth web.Auth, createBackl
 
 	project.OwnerID = doer.ID
 	project.Owner = doer
+	project.setPublicHash("")
 
 	err = checkProjectBeforeUpdateOrDelete(s, project)
 	if err != nil {
@@ -777,16 +809,30 @@ func UpdateProject(s *xorm.Session, project *Project, auth web.Auth, updateProje
 		}
 	}
 
+	originalProject, err := GetProjectSimpleByID(s, project.ID)
+	if err != nil {
+		return err
+	}
+	project.setPublicHash(originalProject.PublicHash)
+
 	// We need to specify the cols we want to update here to be able to un-archive projects
 	colsToUpdate := []string{
 		"title",
 		"is_archived",
+		"is_published",
+		"public_hash",
 		"identifier",
 		"hex_color",
 		"parent_project_id",
 		"position",
 		"done_bucket_id",
 		"default_bucket_id",
+		"default_reminders",
+		"enforce_blocking_tasks",
+		"auto_scheduling",
+		"scheduling_skip_weekends",
+		"progress_from_subtasks",
+		"done_from_subtasks",
 	}
 	if project.Description != "" {
 		colsToUpdate = append(colsToUpdate, "description")
@@ -955,7 +1001,7 @@ func (p *Project) isDefaultProject(s *xorm.Session) (is bool, err error) {
 
 // Delete implements the delete method of CRUDable
 // @Summary Deletes a project
-// @Description Delets a project
+// @Description Moves a project and all its tasks to the trash. It can be restored from there until it is removed permanently.
 // @tags project
 // @Produce json
 // @Security JWTKeyAuth
@@ -976,26 +1022,32 @@ func (p *Project) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return &ErrCannotDeleteDefaultProject{ProjectID: p.ID}
 	}
 
-	// Delete all tasks on that project
-	// Using the loop to make sure all related entities to all tasks are properly deleted as well.
 	tasks, _, _, err := getRawTasksForProjects(s, []*Project{p}, a, &taskSearchOptions{})
 	if err != nil {
 		return
 	}
 
+	fullProject, err := GetProjectSimpleByID(s, p.ID)
+	if err != nil {
+		return
+	}
+
+	// Tasks in other projects might have subtasks in this one
+	parentIDs := []int64{}
 	for _, task := range tasks {
-		err = task.Delete(s, a)
+		ids, err := getParentTaskIDs(s, task.ID)
 		if err != nil {
 			return err
 		}
+		parentIDs = append(parentIDs, ids...)
 	}
 
-	fullProject, err := GetProjectSimpleByID(s, p.ID)
+	err = trashProject(s, fullProject, tasks, a)
 	if err != nil {
 		return
 	}
 
-	err = fullProject.DeleteBackgroundFileIfExists()
+	err = updateProgressFromSubtasks(s, parentIDs...)
 	if err != nil {
 		return
 	}
@@ -1010,10 +1062,15 @@ func (p *Project) Delete(s *xorm.Session, a web.Auth) (err error) {
 		}
 	}
 
-	// Delete the project
-	_, err = s.ID(p.ID).Delete(&Project{})
-	if err != nil {
-		return
+	doer, _ := user.GetFromAuth(a)
+	for _, task := range tasks {
+		err = events.Dispatch(&TaskDeletedEvent{
+			Task: task,
+			Doer: doer,
+		})
+		if err != nil {
+			return
+		}
 	}
 
 	return events.Dispatch(&ProjectDeletedEvent{
@@ -19,6 +19,7 @@ package models
 import (
 	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/utils"
 	"code.vikunja.io/web"
 	"xorm.io/xorm"
@@ -78,6 +79,8 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 
 	pd.Project.ID = 0
 	pd.Project.Identifier = "" // Reset the identifier to trigger regenerating a new one
+	pd.Project.IsPublished = false
+	pd.Project.InboundEmailHash = ""
 	pd.Project.ParentProjectID = pd.ParentProjectID
 	// Set the owner to the current user
 	pd.Project.OwnerID = doer.GetID()
@@ -154,8 +157,9 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 	}
 
 	// Generate new link shares if any are available
+	// Link shares of single tasks are not duplicated since they reference the old tasks.
 	linkShares := []*LinkSharing{}
-	err = s.Where("project_id = ?", pd.ProjectID).Find(&linkShares)
+	err = s.Where("project_id = ? AND task_id = 0", pd.ProjectID).Find(&linkShares)
 	if err != nil {
 		return
 	}
@@ -163,6 +167,7 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 		share.ID = 0
 		share.ProjectID = pd.Project.ID
 		share.Hash = utils.MakeRandomString(40)
+		share.Uses = 0
 		if _, err := s.Insert(share); err != nil {
 			return err
 		}
@@ -243,6 +248,11 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate, bucket
 		t.ProjectID = ld.Project.ID
 		t.BucketID = bucketMap[t.BucketID]
 		t.UID = ""
+		if err := clearMilestoneIfNotInProject(s, t); err != nil {
+			return err
+		}
+		// Sprints are not duplicated
+		t.SprintID = 0
 		err := createTask(s, t, doer, false)
 		if err != nil {
 			return err
@@ -263,34 +273,16 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate, bucket
 
 	for _, attachment := range attachments {
 		oldAttachmentID := attachment.ID
-		attachment.ID = 0
-		var exists bool
-		attachment.TaskID, exists = taskMap[attachment.TaskID]
+		newTaskID, exists := taskMap[attachment.TaskID]
 		if !exists {
 			log.Debugf("Error duplicating attachment %d from old task %d to new task: Old task <-> new task does not seem to exist.", oldAttachmentID, attachment.TaskID)
 			continue
 		}
-		attachment.File = &files.File{ID: attachment.FileID}
-		if err := attachment.File.LoadFileMetaByID(); err != nil {
-			if files.IsErrFileDoesNotExist(err) {
-				log.Debugf("Not duplicating attachment %d (file %d) because it does not exist from project %d into %d", oldAttachmentID, attachment.FileID, ld.ProjectID, ld.Project.ID)
-				continue
-			}
-			return err
-		}
-		if err := attachment.File.LoadFileByID(); err != nil {
-			return err
-		}
 
-		err := attachment.NewAttachment(s, attachment.File.File, attachment.File.Name, attachment.File.Size, doer)
-		if err != nil {
+		if err := duplicateTaskAttachment(s, attachment, newTaskID, doer); err != nil {
 			return err
 		}
 
-		if attachment.File.File != nil {
-			_ = attachment.File.File.Close()
-		}
-
 		log.Debugf("Duplicated attachment %d into %d from project %d into %d", oldAttachmentID, attachment.ID, ld.ProjectID, ld.Project.ID)
 	}
 
@@ -351,6 +343,30 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate, bucket
 
 	log.Debugf("Duplicated all comments from project %d into %d", ld.ProjectID, ld.Project.ID)
 
+	// Checklists
+	// Only keep those assignees who have access to the new project
+	checklistItems, err := getChecklistItemsForTasks(s, oldTaskIDs)
+	if err != nil {
+		return
+	}
+	for _, item := range checklistItems {
+		item.ID = 0
+		item.TaskID = taskMap[item.TaskID]
+		err = checkChecklistItemAssignee(s, &Task{ProjectID: ld.Project.ID}, item.AssigneeID)
+		if IsErrUserDoesNotHaveAccessToProject(err) || user.IsErrUserDoesNotExist(err) {
+			item.AssigneeID = 0
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := s.Insert(item); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Duplicated all checklists from project %d into %d", ld.ProjectID, ld.Project.ID)
+
 	// Relations in that project
 	// Low-Effort: Only copy those relations which are between tasks in the same project
 	// because we can do that without a lot of hassle
@@ -0,0 +1,183 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/xorm"
+)
+
+// PublicProject holds everything shown on the public page and in the feed of a published project.
+// It deliberately only contains data which is safe to show to anonymous visitors.
+type PublicProject struct {
+	Project *Project
+	Buckets []*PublicProjectBucket
+	// All tasks of the project, most recently updated first.
+	Tasks []*PublicTask
+}
+
+// PublicProjectBucket is a kanban bucket with its tasks as shown on the public page of a project.
+type PublicProjectBucket struct {
+	Title string
+	Tasks []*PublicTask
+}
+
+// PublicTask is the public representation of a task in a published project.
+type PublicTask struct {
+	ID          int64
+	Identifier  string
+	Title       string
+	Description string
+	Done        bool
+	DoneAt      time.Time
+	DueDate     time.Time
+	// The display names of all assignees. Does not include usernames or emails.
+	Assignees []string
+	Created   time.Time
+	Updated   time.Time
+}
+
+// setPublicHash makes sure a project has a public hash if and only if it is published.
+// If the project is already published, the existing hash is kept so the public urls don't change.
+func (p *Project) setPublicHash(existingHash string) {
+	if !p.IsPublished {
+		p.PublicHash = ""
+		return
+	}
+
+	if existingHash != "" {
+		p.PublicHash = existingHash
+		return
+	}
+
+	p.PublicHash = utils.MakeRandomString(40)
+}
+
+// GetPublishedProjectByHash returns a published project by its public hash.
+func GetPublishedProjectByHash(s *xorm.Session, hash string) (project *Project, err error) {
+	if hash == "" {
+		return nil, ErrProjectDoesNotExist{}
+	}
+
+	project = &Project{}
+	exists, err := s.
+		Where("public_hash = ? AND is_published = ?", hash, true).
+		Get(project)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProjectDoesNotExist{}
+	}
+
+	return project, nil
+}
+
+// GetPublicProject returns the data for the public page of a published project.
+func GetPublicProject(s *xorm.Session, project *Project) (pp *PublicProject, err error) {
+	pp = &PublicProject{
+		Project: project,
+		Buckets: []*PublicProjectBucket{},
+		Tasks:   []*PublicTask{},
+	}
+
+	buckets := []*Bucket{}
+	err = s.
+		Where("project_id = ?", project.ID).
+		OrderBy("position asc").
+		Find(&buckets)
+	if err != nil {
+		return
+	}
+
+	tasks := []*Task{}
+	err = s.
+		Where("project_id = ?", project.ID).
+		OrderBy("kanban_position asc, id asc").
+		Find(&tasks)
+	if err != nil {
+		return
+	}
+
+	if len(tasks) == 0 {
+		return
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	assignees, err := getRawTaskAssigneesForTasks(s, taskIDs)
+	if err != nil {
+		return
+	}
+	assigneeNames := make(map[int64][]string)
+	for _, a := range assignees {
+		assigneeNames[a.TaskID] = append(assigneeNames[a.TaskID], a.GetName())
+	}
+
+	bucketMap := make(map[int64]*PublicProjectBucket, len(buckets))
+	for _, b := range buckets {
+		pb := &PublicProjectBucket{
+			Title: b.Title,
+			Tasks: []*PublicTask{},
+		}
+		bucketMap[b.ID] = pb
+		pp.Buckets = append(pp.Buckets, pb)
+	}
+
+	// Tasks without a bucket should not happen, but if they do we still want to show them.
+	var unsorted *PublicProjectBucket
+
+	for _, t := range tasks {
+		t.setIdentifier(project)
+		pt := &PublicTask{
+			ID:          t.ID,
+			Identifier:  t.GetFullIdentifier(),
+			Title:       t.Title,
+			Description: t.Description,
+			Done:        t.Done,
+			DoneAt:      t.DoneAt,
+			DueDate:     t.DueDate,
+			Assignees:   assigneeNames[t.ID],
+			Created:     t.Created,
+			Updated:     t.Updated,
+		}
+		pp.Tasks = append(pp.Tasks, pt)
+
+		b, has := bucketMap[t.BucketID]
+		if !has {
+			if unsorted == nil {
+				unsorted = &PublicProjectBucket{Tasks: []*PublicTask{}}
+				pp.Buckets = append(pp.Buckets, unsorted)
+			}
+			b = unsorted
+		}
+		b.Tasks = append(b.Tasks, pt)
+	}
+
+	sort.SliceStable(pp.Tasks, func(i, j int) bool {
+		return pp.Tasks[i].Updated.After(pp.Tasks[j].Updated)
+	})
+
+	return
+}
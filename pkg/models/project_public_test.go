@@ -0,0 +1,125 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProject_Publish(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("publish and unpublish", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		project := &Project{ID: 1}
+		can, _, err := project.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		project.IsPublished = true
+		project.PublicHash = "chosen by the user"
+		err = project.Update(s, u)
+		assert.NoError(t, err)
+		assert.True(t, project.IsPublished)
+		assert.Len(t, project.PublicHash, 40)
+		hash := project.PublicHash
+
+		// Updating the project again should keep the hash
+		err = project.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, hash, project.PublicHash)
+
+		published, err := GetPublishedProjectByHash(s, hash)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), published.ID)
+
+		project.IsPublished = false
+		err = project.Update(s, u)
+		assert.NoError(t, err)
+		assert.Empty(t, project.PublicHash)
+
+		_, err = GetPublishedProjectByHash(s, hash)
+		assert.Error(t, err)
+		assert.True(t, IsErrProjectDoesNotExist(err))
+	})
+	t.Run("only admins can publish", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{ID: 2, ProjectID: 2, Right: RightWrite}
+		project := &Project{ID: 2, Title: "Test2", IsPublished: true}
+		can, err := project.CanUpdate(s, share)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		project.IsPublished = false
+		can, err = project.CanUpdate(s, share)
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("not published", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := GetPublishedProjectByHash(s, "")
+		assert.Error(t, err)
+		assert.True(t, IsErrProjectDoesNotExist(err))
+	})
+}
+
+func TestGetPublicProject(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	project, err := GetProjectSimpleByID(s, 1)
+	assert.NoError(t, err)
+
+	pp, err := GetPublicProject(s, project)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pp.Buckets)
+	assert.Equal(t, "testbucket1", pp.Buckets[0].Title)
+
+	var taskCount int
+	for _, b := range pp.Buckets {
+		taskCount += len(b.Tasks)
+	}
+	assert.Equal(t, len(pp.Tasks), taskCount)
+
+	for i := 1; i < len(pp.Tasks); i++ {
+		assert.False(t, pp.Tasks[i].Updated.After(pp.Tasks[i-1].Updated))
+	}
+
+	for _, task := range pp.Tasks {
+		if task.ID == 30 {
+			assert.Len(t, task.Assignees, 2)
+			for _, name := range task.Assignees {
+				assert.NotContains(t, name, "@")
+			}
+		}
+	}
+}
@@ -48,7 +48,7 @@ func (p *Project) CanWrite(s *xorm.Session, a web.Auth) (bool, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return originalProject.ID == shareAuth.ProjectID &&
+		return originalProject.ID == shareAuth.ProjectID && shareAuth.TaskID == 0 &&
 			(shareAuth.Right == RightWrite || shareAuth.Right == RightAdmin), errIsArchived
 	}
 
@@ -101,14 +101,43 @@ func (p *Project) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return p.ID == shareAuth.ProjectID &&
-			(shareAuth.Right == RightRead || shareAuth.Right == RightWrite || shareAuth.Right == RightAdmin), int(shareAuth.Right), nil
+		return p.ID == shareAuth.ProjectID && shareAuth.TaskID == 0 &&
+			(shareAuth.Right == RightRead || shareAuth.Right == RightComment || shareAuth.Right == RightWrite || shareAuth.Right == RightAdmin), shareAuth.Right.maxRight(), nil
 	}
 
 	if p.isOwner(&user.User{ID: a.GetID()}) {
 		return true, int(RightAdmin), nil
 	}
-	return p.checkRight(s, a, RightRead, RightWrite, RightAdmin)
+	can, maxRight, err := p.checkRight(s, a, RightRead, RightComment, RightWrite, RightAdmin)
+	return can, Right(maxRight).maxRight(), err
+}
+
+// CanComment checks if a user can comment on tasks in a project and upload attachments to them.
+// Everyone who has write access to a project can also comment.
+func (p *Project) CanComment(s *xorm.Session, a web.Auth) (bool, error) {
+	canWrite, err := p.CanWrite(s, a)
+	if err != nil || canWrite {
+		return canWrite, err
+	}
+
+	originalProject, err := GetProjectSimpleByID(s, p.ID)
+	if err != nil {
+		return false, err
+	}
+
+	errIsArchived := originalProject.CheckIsArchived(s)
+
+	shareAuth, ok := a.(*LinkSharing)
+	if ok {
+		return originalProject.ID == shareAuth.ProjectID && shareAuth.TaskID == 0 &&
+			shareAuth.Right == RightComment, errIsArchived
+	}
+
+	canComment, _, err := originalProject.checkRight(s, a, RightComment)
+	if err != nil {
+		return false, err
+	}
+	return canComment, errIsArchived
 }
 
 // CanUpdate checks if the user can update a project
@@ -147,6 +176,14 @@ func (p *Project) CanUpdate(s *xorm.Session, a web.Auth) (canUpdate bool, err er
 		return sf.CanUpdate(s, a)
 	}
 
+	// Only admins may publish or unpublish a project
+	if p.IsPublished != ol.IsPublished {
+		isAdmin, err := ol.IsAdmin(s, a)
+		if err != nil || !isAdmin {
+			return false, err
+		}
+	}
+
 	canUpdate, err = p.CanWrite(s, a)
 	// If the project is archived and the user tries to un-archive it, let the request through
 	archivedErr := ErrProjectIsArchived{}
@@ -191,7 +228,7 @@ func (p *Project) IsAdmin(s *xorm.Session, a web.Auth) (bool, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return originalProject.ID == shareAuth.ProjectID && shareAuth.Right == RightAdmin, nil
+		return originalProject.ID == shareAuth.ProjectID && shareAuth.TaskID == 0 && shareAuth.Right == RightAdmin, nil
 	}
 
 	// Check all the things
@@ -265,10 +302,10 @@ func (p *Project) checkRight(s *xorm.Session, a web.Auth, rights ...Right) (bool
 	}
 
 	// Figure out the max right and return it
-	if int(r.UserProject.Right) > maxRight {
+	if r.UserProject.Right.rank() > Right(maxRight).rank() {
 		maxRight = int(r.UserProject.Right)
 	}
-	if int(r.TeamProject.Right) > maxRight {
+	if r.TeamProject.Right.rank() > Right(maxRight).rank() {
 		maxRight = int(r.TeamProject.Right)
 	}
 
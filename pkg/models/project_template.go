@@ -0,0 +1,715 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TemplateBucket is a kanban bucket saved in a project template.
+type TemplateBucket struct {
+	// An id unique within the template, used to put the tasks of the template into their buckets.
+	Key      int64   `json:"key"`
+	Title    string  `json:"title"`
+	Limit    int64   `json:"limit"`
+	Position float64 `json:"position"`
+}
+
+// TemplateReminder is a reminder saved in a template. Absolute reminders are saved as an offset in seconds
+// relative to the anchor of the template.
+type TemplateReminder struct {
+	Reminder       *int64           `json:"reminder"`
+	RelativePeriod int64            `json:"relative_period"`
+	RelativeTo     ReminderRelation `json:"relative_to"`
+}
+
+// TemplateRelation is a relation between two tasks of a project template.
+type TemplateRelation struct {
+	OtherTaskKey int64        `json:"other_task_key"`
+	RelationKind RelationKind `json:"relation_kind"`
+}
+
+// TemplateChecklistItem is a checklist item saved in a template.
+type TemplateChecklistItem struct {
+	Title      string  `json:"title"`
+	Position   float64 `json:"position"`
+	AssigneeID int64   `json:"assignee_id"`
+	// The due date as an offset in seconds relative to the anchor of the template.
+	DueDate *int64 `json:"due_date"`
+}
+
+// TemplateTask is a task saved in a template. All dates are saved as an offset in seconds relative to the
+// anchor of the template, which is the start of the day of the earliest date of all tasks in the template.
+type TemplateTask struct {
+	// An id unique within the template, used to recreate the relations between the tasks of a template.
+	Key            int64          `json:"key"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Priority       int64          `json:"priority"`
+	HexColor       string         `json:"hex_color"`
+	Estimate       float64        `json:"estimate"`
+	RepeatAfter    int64          `json:"repeat_after"`
+	RepeatMode     TaskRepeatMode `json:"repeat_mode"`
+	Position       float64        `json:"position"`
+	KanbanPosition float64        `json:"kanban_position"`
+	// The key of the template bucket this task belongs to.
+	BucketKey int64 `json:"bucket_key"`
+
+	DueDate   *int64 `json:"due_date"`
+	StartDate *int64 `json:"start_date"`
+	EndDate   *int64 `json:"end_date"`
+
+	Reminders      []*TemplateReminder      `json:"reminders"`
+	LabelIDs       []int64                  `json:"label_ids"`
+	AssigneeIDs    []int64                  `json:"assignee_ids"`
+	Relations      []*TemplateRelation      `json:"relations"`
+	ChecklistItems []*TemplateChecklistItem `json:"checklist_items"`
+}
+
+// ProjectTemplate holds a project with all its buckets and tasks which can be used to create new projects.
+type ProjectTemplate struct {
+	// The unique, numeric id of this template.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"template"`
+	// The title of the template. Used as the title of projects created from it unless another one is provided.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The description of the template.
+	Description string `xorm:"longtext null" json:"description"`
+	// The project to create the template from. Only used when creating a template.
+	ProjectID int64 `xorm:"-" json:"project_id"`
+
+	// The settings of the project saved in this template.
+	HexColor               string                  `xorm:"varchar(6) null" json:"hex_color"`
+	DefaultReminders       []*user.DefaultReminder `xorm:"json null" json:"default_reminders"`
+	EnforceBlockingTasks   bool                    `xorm:"not null default false" json:"enforce_blocking_tasks"`
+	AutoScheduling         bool                    `xorm:"not null default false" json:"auto_scheduling"`
+	SchedulingSkipWeekends bool                    `xorm:"not null default false" json:"scheduling_skip_weekends"`
+	ProgressFromSubtasks   bool                    `xorm:"not null default false" json:"progress_from_subtasks"`
+	DoneFromSubtasks       bool                    `xorm:"not null default false" json:"done_from_subtasks"`
+
+	// The kanban buckets of the template.
+	Buckets []*TemplateBucket `xorm:"json null" json:"buckets"`
+	// The key of the bucket new tasks are put in.
+	DefaultBucketKey int64 `xorm:"bigint null" json:"default_bucket_key"`
+	// The key of the done bucket.
+	DoneBucketKey int64 `xorm:"bigint null" json:"done_bucket_key"`
+	// The tasks of the template.
+	Tasks []*TemplateTask `xorm:"json null" json:"tasks"`
+
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The user who owns this template.
+	Owner *user.User `xorm:"-" json:"owner" valid:"-"`
+
+	// A timestamp when this template was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this template was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for project templates
+func (*ProjectTemplate) TableName() string {
+	return "project_templates"
+}
+
+// getTemplateAnchor returns the start of the day of the earliest date of all tasks. If none of the tasks has a date,
+// the start of the current day is used.
+func getTemplateAnchor(tasks []*Task) time.Time {
+	var anchor time.Time
+	for _, t := range tasks {
+		for _, date := range []time.Time{t.DueDate, t.StartDate, t.EndDate} {
+			if !date.IsZero() && (anchor.IsZero() || date.Before(anchor)) {
+				anchor = date
+			}
+		}
+	}
+
+	if anchor.IsZero() {
+		anchor = time.Now()
+	}
+
+	anchor = anchor.In(config.GetTimeZone())
+	return time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, anchor.Location())
+}
+
+func toTemplateOffset(date, anchor time.Time) *int64 {
+	if date.IsZero() {
+		return nil
+	}
+	offset := int64(date.Sub(anchor).Seconds())
+	return &offset
+}
+
+func fromTemplateOffset(offset *int64, start time.Time) time.Time {
+	if offset == nil {
+		return time.Time{}
+	}
+	return start.Add(time.Duration(*offset) * time.Second)
+}
+
+// newTemplateTask saves a task with all its details in a template task. Relations are not included.
+func newTemplateTask(t *Task, anchor time.Time, checklistItems []*TaskChecklistItem) *TemplateTask {
+	tt := &TemplateTask{
+		Key:            t.ID,
+		Title:          t.Title,
+		Description:    t.Description,
+		Priority:       t.Priority,
+		HexColor:       t.HexColor,
+		Estimate:       t.Estimate,
+		RepeatAfter:    t.RepeatAfter,
+		RepeatMode:     t.RepeatMode,
+		Position:       t.Position,
+		KanbanPosition: t.KanbanPosition,
+		BucketKey:      t.BucketID,
+		DueDate:        toTemplateOffset(t.DueDate, anchor),
+		StartDate:      toTemplateOffset(t.StartDate, anchor),
+		EndDate:        toTemplateOffset(t.EndDate, anchor),
+	}
+
+	for _, r := range t.Reminders {
+		reminder := &TemplateReminder{
+			RelativePeriod: r.RelativePeriod,
+			RelativeTo:     r.RelativeTo,
+		}
+		if r.RelativeTo == "" {
+			reminder.Reminder = toTemplateOffset(r.Reminder, anchor)
+		}
+		tt.Reminders = append(tt.Reminders, reminder)
+	}
+	for _, l := range t.Labels {
+		tt.LabelIDs = append(tt.LabelIDs, l.ID)
+	}
+	for _, a := range t.Assignees {
+		tt.AssigneeIDs = append(tt.AssigneeIDs, a.ID)
+	}
+	for _, item := range checklistItems {
+		tt.ChecklistItems = append(tt.ChecklistItems, &TemplateChecklistItem{
+			Title:      item.Title,
+			Position:   item.Position,
+			AssigneeID: item.AssigneeID,
+			DueDate:    toTemplateOffset(item.DueDate, anchor),
+		})
+	}
+
+	return tt
+}
+
+// toTask returns a new task from the template task with all dates relative to start.
+func (tt *TemplateTask) toTask(projectID int64, start time.Time) *Task {
+	t := &Task{
+		Title:          tt.Title,
+		Description:    tt.Description,
+		ProjectID:      projectID,
+		Priority:       tt.Priority,
+		HexColor:       tt.HexColor,
+		Estimate:       tt.Estimate,
+		RepeatAfter:    tt.RepeatAfter,
+		RepeatMode:     tt.RepeatMode,
+		Position:       tt.Position,
+		KanbanPosition: tt.KanbanPosition,
+		DueDate:        fromTemplateOffset(tt.DueDate, start),
+		StartDate:      fromTemplateOffset(tt.StartDate, start),
+		EndDate:        fromTemplateOffset(tt.EndDate, start),
+	}
+	for _, r := range tt.Reminders {
+		t.Reminders = append(t.Reminders, &TaskReminder{
+			Reminder:       fromTemplateOffset(r.Reminder, start),
+			RelativePeriod: r.RelativePeriod,
+			RelativeTo:     r.RelativeTo,
+		})
+	}
+	return t
+}
+
+// addDetailsToTask adds the labels, assignees and checklist items of a template task to a task created from it.
+// Labels the user has no access to and assignees without access to the project are skipped.
+func (tt *TemplateTask) addDetailsToTask(s *xorm.Session, t *Task, project *Project, start time.Time, a web.Auth, labelAccess map[int64]bool) (err error) {
+	for _, labelID := range tt.LabelIDs {
+		has, checked := labelAccess[labelID]
+		if !checked {
+			has, _, err = (&Label{ID: labelID}).hasAccessToLabel(s, a)
+			if err != nil {
+				return err
+			}
+			labelAccess[labelID] = has
+		}
+		if !has {
+			continue
+		}
+		if _, err = s.Insert(&LabelTask{TaskID: t.ID, LabelID: labelID}); err != nil {
+			return err
+		}
+	}
+
+	for _, assigneeID := range tt.AssigneeIDs {
+		err = t.addNewAssigneeByID(s, assigneeID, project, a)
+		if IsErrUserDoesNotHaveAccessToProject(err) || user.IsErrUserDoesNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ti := range tt.ChecklistItems {
+		item := &TaskChecklistItem{
+			TaskID:      t.ID,
+			Title:       ti.Title,
+			Position:    ti.Position,
+			AssigneeID:  ti.AssigneeID,
+			DueDate:     fromTemplateOffset(ti.DueDate, start),
+			CreatedByID: a.GetID(),
+		}
+		err = checkChecklistItemAssignee(s, t, item.AssigneeID)
+		if IsErrUserDoesNotHaveAccessToProject(err) || user.IsErrUserDoesNotExist(err) {
+			item.AssigneeID = 0
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = s.Insert(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getProjectTemplateByID(s *xorm.Session, id int64) (pt *ProjectTemplate, err error) {
+	pt = &ProjectTemplate{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(pt)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProjectTemplateDoesNotExist{TemplateID: id}
+	}
+	return
+}
+
+// Create saves a project as a template
+// @Summary Create a project template
+// @Description Saves a project with its settings, kanban buckets and tasks including their labels, assignees, reminders, checklists and relations as a template. All dates are saved relative to the earliest date of all tasks. The user needs read access to the project.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template body models.ProjectTemplate true "The template with the id of the project to create it from."
+// @Success 201 {object} models.ProjectTemplate "The created template."
+// @Failure 400 {object} web.HTTPError "Invalid template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/projects [put]
+func (pt *ProjectTemplate) Create(s *xorm.Session, a web.Auth) (err error) {
+	project, err := GetProjectSimpleByID(s, pt.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	pt.ID = 0
+	pt.OwnerID = a.GetID()
+	if pt.Description == "" {
+		pt.Description = project.Description
+	}
+	pt.HexColor = project.HexColor
+	pt.DefaultReminders = project.DefaultReminders
+	pt.EnforceBlockingTasks = project.EnforceBlockingTasks
+	pt.AutoScheduling = project.AutoScheduling
+	pt.SchedulingSkipWeekends = project.SchedulingSkipWeekends
+	pt.ProgressFromSubtasks = project.ProgressFromSubtasks
+	pt.DoneFromSubtasks = project.DoneFromSubtasks
+	pt.DefaultBucketKey = project.DefaultBucketID
+	pt.DoneBucketKey = project.DoneBucketID
+
+	buckets := []*Bucket{}
+	err = s.Where("project_id = ?", project.ID).OrderBy("position asc").Find(&buckets)
+	if err != nil {
+		return err
+	}
+	pt.Buckets = make([]*TemplateBucket, 0, len(buckets))
+	for _, b := range buckets {
+		pt.Buckets = append(pt.Buckets, &TemplateBucket{
+			Key:      b.ID,
+			Title:    b.Title,
+			Limit:    b.Limit,
+			Position: b.Position,
+		})
+	}
+
+	tasks, _, _, err := getTasksForProjects(s, []*Project{project}, a, &taskSearchOptions{})
+	if err != nil {
+		return err
+	}
+
+	anchor := getTemplateAnchor(tasks)
+	pt.Tasks = make([]*TemplateTask, 0, len(tasks))
+	templateTasks := make(map[int64]*TemplateTask, len(tasks))
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		tt := newTemplateTask(t, anchor, t.ChecklistItems)
+		pt.Tasks = append(pt.Tasks, tt)
+		templateTasks[t.ID] = tt
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	// Only relations between tasks of the project are saved
+	if len(taskIDs) > 0 {
+		relations := []*TaskRelation{}
+		err = s.In("task_id", taskIDs).Find(&relations)
+		if err != nil {
+			return err
+		}
+		for _, r := range relations {
+			if _, exists := templateTasks[r.OtherTaskID]; !exists {
+				continue
+			}
+			templateTasks[r.TaskID].Relations = append(templateTasks[r.TaskID].Relations, &TemplateRelation{
+				OtherTaskKey: r.OtherTaskID,
+				RelationKind: r.RelationKind,
+			})
+		}
+	}
+
+	_, err = s.Insert(pt)
+	if err != nil {
+		return err
+	}
+
+	pt.Owner, err = user.GetUserByID(s, pt.OwnerID)
+	return err
+}
+
+// ReadAll returns all project templates of the current user
+// @Summary Get all project templates
+// @Description Returns all project templates the current user created.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search templates by title."
+// @Success 200 {array} models.ProjectTemplate "The templates"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/projects [get]
+func (pt *ProjectTemplate) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	templates := []*ProjectTemplate{}
+
+	var where builder.Cond = builder.Eq{"owner_id": a.GetID()}
+	if search != "" {
+		where = builder.And(
+			where,
+			db.ILIKE("title", search),
+		)
+	}
+
+	err = s.
+		Where(where).
+		OrderBy("title asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&templates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalCount, err := s.Where(where).Count(&ProjectTemplate{})
+	return templates, len(templates), totalCount, err
+}
+
+// ReadOne returns one project template
+// @Summary Get one project template
+// @Description Returns a project template by its id.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Success 200 {object} models.ProjectTemplate "The template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/projects/{template} [get]
+func (pt *ProjectTemplate) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	// pt already contains the full template from the rights check, we only need to add the user
+	pt.Owner, err = user.GetUserByID(s, pt.OwnerID)
+	return err
+}
+
+// Update changes the title and description of a project template
+// @Summary Update a project template
+// @Description Updates the title and description of a project template. To change its content, create a new template from a project.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Param template body models.ProjectTemplate true "The template"
+// @Success 200 {object} models.ProjectTemplate "The updated template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/projects/{template} [post]
+func (pt *ProjectTemplate) Update(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = s.
+		Where("id = ?", pt.ID).
+		Cols("title", "description").
+		Update(pt)
+	if err != nil {
+		return err
+	}
+
+	updated, err := getProjectTemplateByID(s, pt.ID)
+	if err != nil {
+		return err
+	}
+	*pt = *updated
+	return nil
+}
+
+// Delete removes a project template
+// @Summary Delete a project template
+// @Description Deletes a project template. Projects created from it are not affected.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Success 200 {object} models.Message "The template was deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/projects/{template} [delete]
+func (pt *ProjectTemplate) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = s.
+		Where("id = ?", pt.ID).
+		Delete(&ProjectTemplate{})
+	return err
+}
+
+// CanCreate checks if a user can create a template from a project
+func (pt *ProjectTemplate) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	project := &Project{ID: pt.ProjectID}
+	canRead, _, err := project.CanRead(s, a)
+	return canRead, err
+}
+
+// CanRead checks if a user can see a project template
+func (pt *ProjectTemplate) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := pt.canDoTemplate(s, a)
+	return can, int(RightAdmin), err
+}
+
+// CanUpdate checks if a user can update a project template
+func (pt *ProjectTemplate) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	// A normal check would replace the passed struct which in our case would override the values we want to update.
+	ptt := &ProjectTemplate{ID: pt.ID}
+	return ptt.canDoTemplate(s, a)
+}
+
+// CanDelete checks if a user can delete a project template
+func (pt *ProjectTemplate) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return pt.canDoTemplate(s, a)
+}
+
+// Only owners are allowed to do something with a template
+func (pt *ProjectTemplate) canDoTemplate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	ptt, err := getProjectTemplateByID(s, pt.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if ptt.OwnerID != a.GetID() {
+		return false, nil
+	}
+
+	*pt = *ptt
+	return true, nil
+}
+
+// ProjectTemplateInstantiation creates a new project from a template
+type ProjectTemplateInstantiation struct {
+	// The template to create the project from.
+	TemplateID int64 `json:"-" param:"template"`
+	// The parent project of the new project.
+	ParentProjectID int64 `json:"parent_project_id"`
+	// The title of the new project. Defaults to the title of the template.
+	Title string `json:"title" valid:"runelength(0|250)" maxLength:"250"`
+	// The date all dates of the template are relative to. Defaults to the start of the current day.
+	StartDate time.Time `json:"start_date"`
+
+	// The created project.
+	Project *Project `json:"project,omitempty"`
+
+	template *ProjectTemplate
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanCreate checks if a user can create a project from a template
+func (pti *ProjectTemplateInstantiation) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	pti.template = &ProjectTemplate{ID: pti.TemplateID}
+	can, err := pti.template.canDoTemplate(s, a)
+	if err != nil || !can {
+		return can, err
+	}
+
+	if pti.ParentProjectID == 0 {
+		return true, nil
+	}
+
+	parent := &Project{ID: pti.ParentProjectID}
+	return parent.CanCreate(s, a)
+}
+
+// Create creates a new project from a template
+// @Summary Create a project from a template
+// @Description Creates a new project with all buckets and tasks of the template. All dates are moved so that the earliest date of the template falls on the provided start date. Labels the user has no access to and assignees without access to the new project are skipped.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Param instantiation body models.ProjectTemplateInstantiation true "The parent project, title and start date of the new project."
+// @Success 201 {object} models.ProjectTemplateInstantiation "The created project."
+// @Failure 400 {object} web.HTTPError "Invalid object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the template or the parent project."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/projects/{template}/instantiate [put]
+func (pti *ProjectTemplateInstantiation) Create(s *xorm.Session, a web.Auth) (err error) {
+	pt := pti.template
+
+	start := pti.StartDate
+	if start.IsZero() {
+		start = getTemplateAnchor(nil)
+	}
+
+	pti.Project = &Project{
+		Title:                  pti.Title,
+		Description:            pt.Description,
+		HexColor:               pt.HexColor,
+		ParentProjectID:        pti.ParentProjectID,
+		DefaultReminders:       pt.DefaultReminders,
+		EnforceBlockingTasks:   pt.EnforceBlockingTasks,
+		AutoScheduling:         pt.AutoScheduling,
+		SchedulingSkipWeekends: pt.SchedulingSkipWeekends,
+		ProgressFromSubtasks:   pt.ProgressFromSubtasks,
+		DoneFromSubtasks:       pt.DoneFromSubtasks,
+	}
+	if pti.Project.Title == "" {
+		pti.Project.Title = pt.Title
+	}
+	err = CreateProject(s, pti.Project, a, len(pt.Buckets) == 0)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Created project %d from template %d", pti.Project.ID, pt.ID)
+
+	// Template bucket key as key, new bucket id as value
+	bucketMap := make(map[int64]int64, len(pt.Buckets))
+	for _, tb := range pt.Buckets {
+		b := &Bucket{
+			ProjectID: pti.Project.ID,
+			Title:     tb.Title,
+			Limit:     tb.Limit,
+			Position:  tb.Position,
+		}
+		if err := b.Create(s, a); err != nil {
+			return err
+		}
+		bucketMap[tb.Key] = b.ID
+	}
+
+	pti.Project.DefaultBucketID = bucketMap[pt.DefaultBucketKey]
+	pti.Project.DoneBucketID = bucketMap[pt.DoneBucketKey]
+	_, err = s.
+		Where("id = ?", pti.Project.ID).
+		Cols("default_bucket_id", "done_bucket_id").
+		Update(pti.Project)
+	if err != nil {
+		return err
+	}
+
+	// Template task key as key, new task id as value
+	taskMap := make(map[int64]int64, len(pt.Tasks))
+	labelAccess := make(map[int64]bool)
+	for _, tt := range pt.Tasks {
+		t := tt.toTask(pti.Project.ID, start)
+		t.BucketID = bucketMap[tt.BucketKey]
+		err = createTask(s, t, a, false)
+		if err != nil {
+			return err
+		}
+		taskMap[tt.Key] = t.ID
+
+		err = tt.addDetailsToTask(s, t, pti.Project, start, a, labelAccess)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Created all tasks of template %d in project %d", pt.ID, pti.Project.ID)
+
+	for _, tt := range pt.Tasks {
+		for _, tr := range tt.Relations {
+			otherTaskID, exists := taskMap[tr.OtherTaskKey]
+			if !exists {
+				continue
+			}
+			_, err = s.Insert(&TaskRelation{
+				TaskID:       taskMap[tt.Key],
+				OtherTaskID:  otherTaskID,
+				RelationKind: tr.RelationKind,
+				CreatedByID:  a.GetID(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Debugf("Created all task relations of template %d in project %d", pt.ID, pti.Project.ID)
+
+	return nil
+}
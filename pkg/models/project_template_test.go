@@ -0,0 +1,182 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTemplateAnchor(t *testing.T) {
+	loc := config.GetTimeZone()
+
+	t.Run("earliest date", func(t *testing.T) {
+		anchor := getTemplateAnchor([]*Task{
+			{DueDate: time.Date(2023, 10, 12, 15, 0, 0, 0, loc)},
+			{StartDate: time.Date(2023, 10, 10, 9, 30, 0, 0, loc), EndDate: time.Date(2023, 10, 11, 9, 30, 0, 0, loc)},
+		})
+		assert.Equal(t, time.Date(2023, 10, 10, 0, 0, 0, 0, loc), anchor)
+	})
+	t.Run("no dates", func(t *testing.T) {
+		anchor := getTemplateAnchor([]*Task{{}})
+		now := time.Now().In(loc)
+		assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), anchor)
+	})
+}
+
+func TestTemplateOffset(t *testing.T) {
+	anchor := time.Date(2023, 10, 10, 0, 0, 0, 0, config.GetTimeZone())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, config.GetTimeZone())
+
+	assert.Nil(t, toTemplateOffset(time.Time{}, anchor))
+	assert.True(t, fromTemplateOffset(nil, start).IsZero())
+
+	offset := toTemplateOffset(anchor.Add(36*time.Hour), anchor)
+	assert.Equal(t, int64(36*60*60), *offset)
+	assert.Equal(t, start.Add(36*time.Hour), fromTemplateOffset(offset, start))
+}
+
+func TestProjectTemplate_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &ProjectTemplate{Title: "template", ProjectID: 1}
+		can, err := pt.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = pt.Create(s, u)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, pt.Buckets)
+		assert.NotEmpty(t, pt.Tasks)
+		assert.Equal(t, int64(3), pt.DoneBucketKey)
+
+		var task1 *TemplateTask
+		for _, tt := range pt.Tasks {
+			if tt.Key == 1 {
+				task1 = tt
+			}
+		}
+		assert.NotNil(t, task1)
+		assert.Equal(t, "task #1", task1.Title)
+		assert.Equal(t, []int64{4}, task1.LabelIDs)
+		assert.Contains(t, task1.Relations, &TemplateRelation{OtherTaskKey: 29, RelationKind: RelationKindSubtask})
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "project_templates", map[string]interface{}{
+			"id":       pt.ID,
+			"title":    "template",
+			"owner_id": 1,
+		}, false)
+	})
+	t.Run("no access to project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &ProjectTemplate{Title: "template", ProjectID: 20}
+		can, err := pt.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &ProjectTemplate{Title: "template", ProjectID: 1}
+		_, err := pt.CanCreate(s, &LinkSharing{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrTemplateNotAvailableForLinkShare(err))
+	})
+}
+
+func TestProjectTemplate_Rights(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	pt := &ProjectTemplate{Title: "template", ProjectID: 1}
+	err := pt.Create(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+
+	can, _, err := (&ProjectTemplate{ID: pt.ID}).CanRead(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+	can, _, err = (&ProjectTemplate{ID: pt.ID}).CanRead(s, &user.User{ID: 2})
+	assert.NoError(t, err)
+	assert.False(t, can)
+	_, _, err = (&ProjectTemplate{ID: 9999}).CanRead(s, &user.User{ID: 1})
+	assert.Error(t, err)
+	assert.True(t, IsErrProjectTemplateDoesNotExist(err))
+}
+
+func TestProjectTemplateInstantiation_Create(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	u := &user.User{ID: 1}
+
+	pt := &ProjectTemplate{Title: "template", ProjectID: 1}
+	err := pt.Create(s, u)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, config.GetTimeZone())
+	pti := &ProjectTemplateInstantiation{TemplateID: pt.ID, Title: "from template", StartDate: start}
+	can, err := pti.CanCreate(s, u)
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = pti.Create(s, u)
+	assert.NoError(t, err)
+	assert.Equal(t, "from template", pti.Project.Title)
+	assert.NotEqual(t, int64(0), pti.Project.DoneBucketID)
+
+	tasks := []*Task{}
+	err = s.Where("project_id = ?", pti.Project.ID).OrderBy("id asc").Find(&tasks)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, len(pt.Tasks))
+
+	taskMap := make(map[int64]*Task, len(tasks))
+	for i, tt := range pt.Tasks {
+		assert.Equal(t, tt.Title, tasks[i].Title)
+		assert.Equal(t, fromTemplateOffset(tt.DueDate, start).Unix(), tasks[i].DueDate.Unix())
+		taskMap[tt.Key] = tasks[i]
+	}
+
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       taskMap[29].ID,
+		"other_task_id": taskMap[1].ID,
+		"relation_kind": RelationKindParenttask,
+	}, false)
+	db.AssertExists(t, "label_tasks", map[string]interface{}{
+		"task_id":  taskMap[1].ID,
+		"label_id": 4,
+	}, false)
+}
@@ -279,9 +279,10 @@ func TestProject_Delete(t *testing.T) {
 		db.AssertMissing(t, "projects", map[string]interface{}{
 			"id": 35,
 		})
-		db.AssertMissing(t, "files", map[string]interface{}{
+		// The background is kept while the project is in the trash
+		db.AssertExists(t, "files", map[string]interface{}{
 			"id": 1,
-		})
+		}, false)
 	})
 	t.Run("default project of the same user", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
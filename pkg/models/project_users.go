@@ -38,8 +38,8 @@ type ProjectUser struct {
 	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
 	// The project id.
 	ProjectID int64 `xorm:"bigint not null INDEX" json:"-" param:"project"`
-	// The right this user has. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
-	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// The right this user has. 0 = Read only, 1 = Read & Write, 2 = Admin, 3 = Read & Comment. See the docs for more details.
+	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|3)" maximum:"3" default:"0"`
 
 	// A timestamp when this relation was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
@@ -0,0 +1,133 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// PushTargets is a wrapper around the crud operations that come with a push target.
+type PushTargets struct {
+	notifications.PushTarget
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// ReadAll returns all push targets of the current user
+// @Summary Get all push targets of the current user
+// @Description Returns all devices and services the current user receives push notifications on. Tokens and web push secrets are never returned.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} models.PushTargets "The push targets"
+// @Failure 403 {object} web.HTTPError "Link shares cannot have push targets."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/push [get]
+func (pt *PushTargets) ReadAll(s *xorm.Session, a web.Auth, _ string, _ int, _ int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	stored, err := notifications.GetPushTargetsForNotifiable(s, a.GetID())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	targets := make([]*PushTargets, 0, len(stored))
+	for _, t := range stored {
+		t.Token = ""
+		t.Auth = ""
+		targets = append(targets, &PushTargets{PushTarget: *t})
+	}
+
+	return targets, len(targets), int64(len(targets)), nil
+}
+
+// Create registers a new push target
+// @Summary Register a push target
+// @Description Registers a device or service to receive push notifications on. For web push, provide the endpoint of the subscription as url together with its p256dh key and auth secret. For ntfy, provide a topic and optionally a server url and access token. For gotify, provide the server url and an application token.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param target body models.PushTargets true "The push target."
+// @Success 201 {object} models.PushTargets "The created push target."
+// @Failure 400 {object} web.HTTPError "The push target is invalid."
+// @Failure 403 {object} web.HTTPError "Link shares cannot have push targets."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/push [put]
+func (pt *PushTargets) Create(s *xorm.Session, a web.Auth) (err error) {
+	channel := notifications.GetPushChannel(pt.Type)
+	if channel == nil {
+		return &ErrInvalidPushTarget{Type: pt.Type, Reason: "push notifications of type " + pt.Type + " are not available"}
+	}
+
+	if err := channel.Validate(&pt.PushTarget); err != nil {
+		return &ErrInvalidPushTarget{Type: pt.Type, Reason: err.Error()}
+	}
+
+	pt.ID = 0
+	pt.NotifiableID = a.GetID()
+	_, err = s.Insert(&pt.PushTarget)
+	if err != nil {
+		return err
+	}
+
+	pt.Token = ""
+	pt.Auth = ""
+	return nil
+}
+
+// Delete removes a push target
+// @Summary Remove a push target
+// @Description Removes a push target. No push notifications will be sent to it afterwards.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param pushtarget path int true "The id of the push target"
+// @Success 200 {object} models.Message "The push target was removed."
+// @Failure 404 {object} web.HTTPError "The push target does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/push/{pushtarget} [delete]
+func (pt *PushTargets) Delete(s *xorm.Session, a web.Auth) (err error) {
+	deleted, err := notifications.DeletePushTarget(s, a.GetID(), pt.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return &ErrPushTargetDoesNotExist{ID: pt.ID}
+	}
+	return nil
+}
+
+// CanCreate checks if a user can register a push target
+func (pt *PushTargets) CanCreate(_ *xorm.Session, a web.Auth) (bool, error) {
+	_, is := a.(*LinkSharing)
+	return !is, nil
+}
+
+// CanDelete checks if a user can remove a push target
+func (pt *PushTargets) CanDelete(_ *xorm.Session, a web.Auth) (bool, error) {
+	// Users can only delete their own push targets, which is checked in Delete
+	_, is := a.(*LinkSharing)
+	return !is, nil
+}
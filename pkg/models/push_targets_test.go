@@ -0,0 +1,127 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushTargets_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("ntfy", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &PushTargets{}
+		pt.Type = notifications.PushTargetTypeNtfy
+		pt.Topic = "my-topic"
+		pt.Token = "tk_token"
+		err := pt.Create(s, u)
+		assert.NoError(t, err)
+		assert.NotEqual(t, int64(0), pt.ID)
+		assert.Empty(t, pt.Token)
+
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "push_targets", map[string]interface{}{
+			"id":            pt.ID,
+			"notifiable_id": 1,
+			"type":          "ntfy",
+			"topic":         "my-topic",
+			"token":         "tk_token",
+		}, false)
+	})
+	t.Run("missing gotify token", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &PushTargets{}
+		pt.Type = notifications.PushTargetTypeGotify
+		pt.URL = "https://gotify.example.com"
+		err := pt.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidPushTarget(err))
+	})
+	t.Run("unavailable type", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Web push is not configured in tests
+		pt := &PushTargets{}
+		pt.Type = notifications.PushTargetTypeWebPush
+		pt.URL = "https://push.example.com/abc"
+		err := pt.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidPushTarget(err))
+	})
+	t.Run("link share", func(t *testing.T) {
+		can, err := (&PushTargets{}).CanCreate(nil, &LinkSharing{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestPushTargets_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	res, count, _, err := (&PushTargets{}).ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	targets := res.([]*PushTargets)
+	assert.Equal(t, int64(1), targets[0].ID)
+	assert.Equal(t, "vikunja-user1", targets[0].Topic)
+	assert.Empty(t, targets[0].Token)
+}
+
+func TestPushTargets_Delete(t *testing.T) {
+	t.Run("own", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &PushTargets{PushTarget: notifications.PushTarget{ID: 1}}
+		err := pt.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "push_targets", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("someone else's", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pt := &PushTargets{PushTarget: notifications.PushTarget{ID: 2}}
+		err := pt.Delete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrPushTargetDoesNotExist(err))
+	})
+}
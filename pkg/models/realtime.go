@@ -0,0 +1,143 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/realtime"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// realtimeEvents holds the names of all events which are streamed to clients as they happen.
+var realtimeEvents = []string{
+	(&TaskCreatedEvent{}).Name(),
+	(&TaskUpdatedEvent{}).Name(),
+	(&TaskDeletedEvent{}).Name(),
+	(&TaskRestoredEvent{}).Name(),
+	(&TaskAssigneeCreatedEvent{}).Name(),
+	(&TaskAssigneeDeletedEvent{}).Name(),
+	(&TaskCommentCreatedEvent{}).Name(),
+	(&TaskCommentUpdatedEvent{}).Name(),
+	(&TaskCommentDeletedEvent{}).Name(),
+	(&TaskAttachmentCreatedEvent{}).Name(),
+	(&TaskAttachmentDeletedEvent{}).Name(),
+	(&TaskRelationCreatedEvent{}).Name(),
+	(&TaskRelationDeletedEvent{}).Name(),
+	(&BucketCreatedEvent{}).Name(),
+	(&BucketUpdatedEvent{}).Name(),
+	(&BucketDeletedEvent{}).Name(),
+}
+
+// realtimeEventData holds everything a task or bucket event can contain. Event payloads don't have json tags, but
+// decoding them into this works since encoding/json matches field names case-insensitively.
+type realtimeEventData struct {
+	Task       *Task           `json:"task,omitempty"`
+	Bucket     *Bucket         `json:"bucket,omitempty"`
+	Comment    *TaskComment    `json:"comment,omitempty"`
+	Attachment *TaskAttachment `json:"attachment,omitempty"`
+	Relation   *TaskRelation   `json:"relation,omitempty"`
+	Assignee   *user.User      `json:"assignee,omitempty"`
+	Doer       *user.User      `json:"doer,omitempty"`
+}
+
+// obfuscateEmails removes the email addresses of all users in the event because it will be sent to everyone
+// who can read the project.
+func (d *realtimeEventData) obfuscateEmails() {
+	users := []*user.User{d.Assignee, d.Doer}
+	if d.Task != nil {
+		users = append(users, d.Task.CreatedBy)
+		users = append(users, d.Task.Assignees...)
+	}
+	if d.Bucket != nil {
+		users = append(users, d.Bucket.CreatedBy)
+	}
+	if d.Comment != nil {
+		users = append(users, d.Comment.Author)
+	}
+	if d.Attachment != nil {
+		users = append(users, d.Attachment.CreatedBy)
+	}
+	if d.Relation != nil {
+		users = append(users, d.Relation.CreatedBy)
+	}
+
+	for _, u := range users {
+		if u != nil {
+			u.Email = ""
+		}
+	}
+}
+
+// PublishRealtimeEvent represents a listener
+type PublishRealtimeEvent struct {
+	event string
+}
+
+// Name defines the name for the PublishRealtimeEvent listener
+func (s *PublishRealtimeEvent) Name() string {
+	return "realtime.publish"
+}
+
+// Handle is executed when the event PublishRealtimeEvent listens on is fired
+func (s *PublishRealtimeEvent) Handle(msg *message.Message) (err error) {
+	if !realtime.Enabled() {
+		return nil
+	}
+
+	data := &realtimeEventData{}
+	err = json.Unmarshal(msg.Payload, data)
+	if err != nil {
+		return err
+	}
+
+	var projectID int64
+	switch {
+	case data.Bucket != nil:
+		projectID = data.Bucket.ProjectID
+	case data.Task != nil:
+		projectID = data.Task.ProjectID
+		if projectID != 0 {
+			break
+		}
+
+		// Some events only contain the id of the task
+		sess := db.NewSession()
+		defer sess.Close()
+		task, err := GetTaskByIDSimple(sess, data.Task.ID)
+		if IsErrTaskDoesNotExist(err) {
+			// The task was deleted in the meantime, the task.deleted event covers that
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		projectID = task.ProjectID
+		data.Task.ProjectID = task.ProjectID
+	}
+
+	if projectID == 0 {
+		return nil
+	}
+
+	data.obfuscateEmails()
+
+	return realtime.Publish(s.event, projectID, 0, data)
+}
@@ -0,0 +1,76 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/realtime"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishRealtimeEvent_Handle(t *testing.T) {
+	realtime.InitRealtime()
+
+	t.Run("looks up the project of the task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		sub := realtime.Subscribe()
+		defer sub.Unsubscribe()
+
+		event := &TaskCommentDeletedEvent{
+			Task:    &Task{ID: 1},
+			Comment: &TaskComment{ID: 1, TaskID: 1, Author: &user.User{ID: 1, Email: "user1@example.com"}},
+			Doer:    &user.User{ID: 1, Email: "user1@example.com"},
+		}
+		payload, err := json.Marshal(event)
+		assert.NoError(t, err)
+
+		listener := &PublishRealtimeEvent{event: event.Name()}
+		err = listener.Handle(message.NewMessage(watermill.NewUUID(), payload))
+		assert.NoError(t, err)
+
+		published := <-sub.Events()
+		assert.Equal(t, "task.comment.deleted", published.Name)
+		assert.Equal(t, int64(1), published.ProjectID)
+		assert.NotContains(t, string(published.Data), "user1@example.com")
+	})
+	t.Run("bucket", func(t *testing.T) {
+		sub := realtime.Subscribe()
+		defer sub.Unsubscribe()
+
+		event := &BucketUpdatedEvent{
+			Bucket: &Bucket{ID: 1, ProjectID: 1, Title: "testbucket1"},
+		}
+		payload, err := json.Marshal(event)
+		assert.NoError(t, err)
+
+		listener := &PublishRealtimeEvent{event: event.Name()}
+		err = listener.Handle(message.NewMessage(watermill.NewUUID(), payload))
+		assert.NoError(t, err)
+
+		published := <-sub.Events()
+		assert.Equal(t, "bucket.updated", published.Name)
+		assert.Equal(t, int64(1), published.ProjectID)
+	})
+}
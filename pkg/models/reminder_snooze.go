@@ -0,0 +1,227 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// The hour a reminder snoozed until tomorrow morning is sent in the user's time zone
+const reminderSnoozeMorningHour = 9
+
+// DeferredReminder is a reminder of a task which is sent to one user at a later time, either because they snoozed
+// it or because it was due during their quiet hours.
+type DeferredReminder struct {
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	TaskID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// When the reminder will be sent to the user.
+	RemindAt time.Time `xorm:"DATETIME not null INDEX" json:"remind_at"`
+	Created  time.Time `xorm:"created not null" json:"-"`
+}
+
+// TableName returns the table name for deferred reminders
+func (DeferredReminder) TableName() string {
+	return "deferred_reminders"
+}
+
+// ReminderSnooze snoozes a reminder notification so that it is sent again later.
+type ReminderSnooze struct {
+	// The id of the reminder notification to snooze.
+	NotificationID int64 `json:"-" param:"notificationid"`
+	// Snooze the reminder for this many minutes.
+	Minutes int64 `json:"minutes" valid:"range(0|43200)"`
+	// If true, the reminder is snoozed until tomorrow morning in the time zone of the user. Takes precedence over
+	// minutes.
+	UntilTomorrow bool `json:"until_tomorrow"`
+	// When the reminder will be sent again.
+	RemindAt time.Time `json:"remind_at"`
+
+	notification *notifications.DatabaseNotification
+	taskID       int64
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanCreate checks if a user can snooze a notification. Only reminders of tasks the user still has access to
+// can be snoozed.
+func (rs *ReminderSnooze) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	rs.notification = &notifications.DatabaseNotification{ID: rs.NotificationID}
+	can, err := notifications.CanMarkNotificationAsRead(s, rs.notification, a.GetID())
+	if err != nil || !can {
+		return false, err
+	}
+
+	if rs.notification.Name != (&ReminderDueNotification{}).Name() {
+		return false, &ErrNotificationCannotBeSnoozed{ID: rs.NotificationID}
+	}
+
+	raw, err := json.Marshal(rs.notification.Notification)
+	if err != nil {
+		return false, err
+	}
+	content := &struct {
+		Task struct {
+			ID int64 `json:"id"`
+		} `json:"task"`
+	}{}
+	err = json.Unmarshal(raw, content)
+	if err != nil {
+		return false, err
+	}
+	rs.taskID = content.Task.ID
+
+	task := &Task{ID: rs.taskID}
+	can, _, err = task.CanRead(s, a)
+	return can, err
+}
+
+// Create snoozes a reminder notification
+// @Summary Snooze a reminder
+// @Description Sends a reminder notification again after a number of minutes or tomorrow morning. The notification is marked as read. If the snoozed reminder is due during the quiet hours of the user, it is sent when they end.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Notification ID"
+// @Param snooze body models.ReminderSnooze true "How long to snooze the reminder."
+// @Success 201 {object} models.ReminderSnooze "The snoozed reminder."
+// @Failure 400 {object} web.HTTPError "The notification is not a reminder or no snooze duration was given."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that notification or its task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /notifications/{id}/snooze [post]
+func (rs *ReminderSnooze) Create(s *xorm.Session, a web.Auth) (err error) {
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch {
+	case rs.UntilTomorrow:
+		tz, err := u.GetTimezone()
+		if err != nil {
+			return err
+		}
+		local := now.In(tz)
+		rs.RemindAt = time.Date(local.Year(), local.Month(), local.Day()+1, reminderSnoozeMorningHour, 0, 0, 0, tz)
+	case rs.Minutes > 0:
+		rs.RemindAt = now.Add(time.Duration(rs.Minutes) * time.Minute)
+	default:
+		return &ErrInvalidReminderSnooze{}
+	}
+
+	_, err = s.Insert(&DeferredReminder{
+		UserID:   u.ID,
+		TaskID:   rs.taskID,
+		RemindAt: rs.RemindAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return notifications.MarkNotificationAsRead(s, rs.notification, true)
+}
+
+// deferReminderIfQuiet saves a reminder for later if it is due during the quiet hours of the user. Returns true if
+// the reminder was deferred and should not be sent now.
+func deferReminderIfQuiet(s *xorm.Session, n *ReminderDueNotification, now time.Time) (deferred bool, err error) {
+	end, quiet, err := n.User.GetQuietHoursEnd(now)
+	if err != nil || !quiet {
+		return false, err
+	}
+
+	_, err = s.Insert(&DeferredReminder{
+		UserID:   n.User.ID,
+		TaskID:   n.Task.ID,
+		RemindAt: end,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	log.Debugf("[Task Reminder Cron] Deferred reminder for task %d to user %d until %s because of quiet hours", n.Task.ID, n.User.ID, end)
+	return true, nil
+}
+
+// getDueDeferredReminders returns all snoozed or deferred reminders which are due now and removes them. Reminders
+// of tasks which are done in the meantime or which the user cannot access anymore are dropped.
+func getDueDeferredReminders(s *xorm.Session, now time.Time) (reminderNotifications []*ReminderDueNotification, err error) {
+	deferred := []*DeferredReminder{}
+	err = s.
+		Where("remind_at <= ?", now.Format(dbTimeFormat)).
+		OrderBy("remind_at asc").
+		Find(&deferred)
+	if err != nil || len(deferred) == 0 {
+		return
+	}
+
+	reminderNotifications = []*ReminderDueNotification{}
+	ids := make([]int64, 0, len(deferred))
+	for _, d := range deferred {
+		ids = append(ids, d.ID)
+
+		u, err := user.GetUserWithEmail(s, &user.User{ID: d.UserID})
+		if err != nil {
+			if user.IsErrUserDoesNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		task, err := GetTaskByIDSimple(s, d.TaskID)
+		if err != nil {
+			if IsErrTaskDoesNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if task.Done {
+			continue
+		}
+
+		can, _, err := task.CanRead(s, u)
+		if err != nil {
+			return nil, err
+		}
+		if !can {
+			continue
+		}
+
+		reminderNotifications = append(reminderNotifications, &ReminderDueNotification{
+			User: u,
+			Task: &task,
+		})
+	}
+
+	_, err = s.In("id", ids).Delete(&DeferredReminder{})
+	return
+}
@@ -32,12 +32,40 @@ const (
 	RightWrite
 	// Can manage a project, can do everything
 	RightAdmin
+	// Can read projects and tasks and can add comments and attachments to tasks.
+	// Sits between read and write, see rank().
+	RightComment
 )
 
 func (r Right) isValid() error {
-	if r != RightAdmin && r != RightRead && r != RightWrite {
+	if r != RightAdmin && r != RightRead && r != RightWrite && r != RightComment {
 		return ErrInvalidRight{r}
 	}
 
 	return nil
 }
+
+// rank returns the position of a right in the hierarchy of rights. Because the comment right was added after
+// the others, its numeric value does not reflect its place between read and write.
+func (r Right) rank() int {
+	switch r {
+	case RightRead:
+		return 0
+	case RightComment:
+		return 1
+	case RightWrite:
+		return 2
+	case RightAdmin:
+		return 3
+	}
+	return -1
+}
+
+// maxRight returns the right as it is reported to clients as the maximum right. Clients compare it numerically
+// against read, write and admin, so the comment right is reported as read.
+func (r Right) maxRight() int {
+	if r == RightComment {
+		return int(RightRead)
+	}
+	return int(r)
+}
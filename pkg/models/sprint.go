@@ -0,0 +1,577 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Sprint is a time-boxed iteration of a project. Tasks are added to a sprint by setting their sprint id.
+type Sprint struct {
+	// The unique, numeric id of this sprint.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"sprint"`
+	// The title of this sprint.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// What the team wants to achieve in this sprint.
+	Goal string `xorm:"longtext null" json:"goal"`
+	// The project this sprint belongs to.
+	ProjectID int64 `xorm:"bigint not null INDEX" json:"project_id" param:"project"`
+	// When this sprint starts. Tasks added to the sprint until then count as planned, all others as added.
+	StartDate time.Time `xorm:"DATETIME not null" json:"start_date"`
+	// When this sprint ends.
+	EndDate time.Time `xorm:"DATETIME not null" json:"end_date"`
+	// When this sprint was closed. Closed sprints can't get new tasks. Use the close endpoint to close a sprint.
+	ClosedAt time.Time `xorm:"DATETIME null" json:"closed_at"`
+
+	// A timestamp when this sprint was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this sprint was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for sprints
+func (*Sprint) TableName() string {
+	return "sprints"
+}
+
+// SprintTask records that a task was part of a sprint. The entries are kept when a sprint is closed so that its
+// report still knows which tasks were part of it.
+type SprintTask struct {
+	ID       int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	SprintID int64 `xorm:"bigint not null INDEX" json:"sprint_id"`
+	TaskID   int64 `xorm:"bigint not null INDEX" json:"task_id"`
+	// Whether the task was moved to the next sprint when this sprint was closed.
+	CarriedOver bool `xorm:"not null default false" json:"carried_over"`
+	// Whether the task was done when this sprint was closed.
+	Completed bool `xorm:"not null default false" json:"completed"`
+	// When the task was added to the sprint.
+	Added time.Time `xorm:"created not null" json:"added"`
+}
+
+// TableName returns the table name for tasks in sprints
+func (*SprintTask) TableName() string {
+	return "sprint_tasks"
+}
+
+func getSprintByID(s *xorm.Session, id int64) (sprint *Sprint, err error) {
+	sprint = &Sprint{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(sprint)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrSprintDoesNotExist{SprintID: id}
+	}
+	return
+}
+
+func (sp *Sprint) validateDates() error {
+	if sp.StartDate.IsZero() || sp.EndDate.IsZero() || !sp.EndDate.After(sp.StartDate) {
+		return ErrInvalidSprintDates{StartDate: sp.StartDate, EndDate: sp.EndDate}
+	}
+	return nil
+}
+
+// checkTaskSprint checks if the sprint of a task exists, belongs to the project of the task and is still open.
+func checkTaskSprint(s *xorm.Session, t *Task) error {
+	sprint, err := getSprintByID(s, t.SprintID)
+	if err != nil {
+		return err
+	}
+
+	if sprint.ProjectID != t.ProjectID {
+		return ErrSprintDoesNotBelongToProject{SprintID: sprint.ID, ProjectID: t.ProjectID}
+	}
+
+	if !sprint.ClosedAt.IsZero() {
+		return ErrSprintIsClosed{SprintID: sprint.ID}
+	}
+
+	return nil
+}
+
+// clearSprintIfNotInProject removes a task from its sprint if the task can't be part of it, for example because it
+// was copied into another project.
+func clearSprintIfNotInProject(s *xorm.Session, t *Task) error {
+	if t.SprintID == 0 {
+		return nil
+	}
+
+	err := checkTaskSprint(s, t)
+	if IsErrSprintDoesNotExist(err) || IsErrSprintDoesNotBelongToProject(err) || IsErrSprintIsClosed(err) {
+		t.SprintID = 0
+		return nil
+	}
+	return err
+}
+
+func addTaskToSprint(s *xorm.Session, taskID, sprintID int64) (err error) {
+	_, err = s.Insert(&SprintTask{
+		SprintID: sprintID,
+		TaskID:   taskID,
+	})
+	return
+}
+
+// moveTaskToSprint checks the new sprint of a task and records that it left its old sprint and is now part of the
+// new one.
+func moveTaskToSprint(s *xorm.Session, t *Task, oldSprintID int64) (err error) {
+	if t.SprintID != 0 {
+		err = checkTaskSprint(s, t)
+		if err != nil {
+			return err
+		}
+	}
+
+	if oldSprintID != 0 {
+		err = removeTaskFromOpenSprint(s, t.ID, oldSprintID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.SprintID == 0 {
+		return nil
+	}
+
+	return addTaskToSprint(s, t.ID, t.SprintID)
+}
+
+// removeTaskFromOpenSprint removes a task from a sprint. Closed sprints keep their tasks so their report does not change.
+func removeTaskFromOpenSprint(s *xorm.Session, taskID, sprintID int64) error {
+	sprint, err := getSprintByID(s, sprintID)
+	if err != nil && !IsErrSprintDoesNotExist(err) {
+		return err
+	}
+	if sprint != nil && !sprint.ClosedAt.IsZero() {
+		return nil
+	}
+
+	_, err = s.
+		Where("sprint_id = ? AND task_id = ?", sprintID, taskID).
+		Delete(&SprintTask{})
+	return err
+}
+
+// deleteSprints removes all sprints matching the condition and removes all tasks from them.
+func deleteSprints(s *xorm.Session, cond builder.Cond) error {
+	sprintIDs := []int64{}
+	err := s.
+		Table("sprints").
+		Where(cond).
+		Cols("id").
+		Find(&sprintIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(sprintIDs) == 0 {
+		return nil
+	}
+
+	_, err = s.
+		In("sprint_id", sprintIDs).
+		Cols("sprint_id").
+		NoAutoTime().
+		Update(&Task{SprintID: 0})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		In("sprint_id", sprintIDs).
+		Delete(&SprintTask{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		In("id", sprintIDs).
+		Delete(&Sprint{})
+	return err
+}
+
+// Create creates a new sprint
+// @Summary Create a sprint
+// @Description Creates a new sprint in a project. The end date has to be after the start date.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param sprint body models.Sprint true "The sprint"
+// @Success 201 {object} models.Sprint "The created sprint."
+// @Failure 400 {object} web.HTTPError "Invalid sprint object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints [put]
+func (sp *Sprint) Create(s *xorm.Session, _ web.Auth) (err error) {
+	if err := sp.validateDates(); err != nil {
+		return err
+	}
+
+	sp.ID = 0
+	sp.ClosedAt = time.Time{}
+	_, err = s.Insert(sp)
+	return err
+}
+
+// ReadAll returns all sprints of a project
+// @Summary Get all sprints of a project
+// @Description Returns all sprints of a project, ordered by their start date.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search sprints by title."
+// @Success 200 {array} models.Sprint "The sprints"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints [get]
+func (sp *Sprint) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	project := &Project{ID: sp.ProjectID}
+	can, _, err := project.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrUserDoesNotHaveAccessToProject{ProjectID: sp.ProjectID, UserID: a.GetID()}
+	}
+
+	var where builder.Cond = builder.Eq{"project_id": sp.ProjectID}
+	if search != "" {
+		where = builder.And(
+			where,
+			db.ILIKE("title", search),
+		)
+	}
+
+	sprints := []*Sprint{}
+	err = s.
+		Where(where).
+		OrderBy("start_date asc, id asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&sprints)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalCount, err := s.Where(where).Count(&Sprint{})
+	return sprints, len(sprints), totalCount, err
+}
+
+// ReadOne returns one sprint
+// @Summary Get one sprint
+// @Description Returns a sprint by its id.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param sprint path int true "Sprint ID"
+// @Success 200 {object} models.Sprint "The sprint"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The sprint does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints/{sprint} [get]
+func (sp *Sprint) ReadOne(_ *xorm.Session, _ web.Auth) (err error) {
+	// sp already contains the full sprint from the rights check
+	return nil
+}
+
+// Update changes a sprint
+// @Summary Update a sprint
+// @Description Updates the title, goal and dates of a sprint.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param sprint path int true "Sprint ID"
+// @Param sprint body models.Sprint true "The sprint"
+// @Success 200 {object} models.Sprint "The updated sprint"
+// @Failure 400 {object} web.HTTPError "Invalid sprint object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The sprint does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints/{sprint} [post]
+func (sp *Sprint) Update(s *xorm.Session, _ web.Auth) (err error) {
+	if err := sp.validateDates(); err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("id = ?", sp.ID).
+		Cols("title", "goal", "start_date", "end_date").
+		Update(sp)
+	if err != nil {
+		return err
+	}
+
+	updated, err := getSprintByID(s, sp.ID)
+	if err != nil {
+		return err
+	}
+	*sp = *updated
+	return nil
+}
+
+// Delete removes a sprint
+// @Summary Delete a sprint
+// @Description Deletes a sprint. All tasks of the sprint are kept but are not part of any sprint anymore.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param sprint path int true "Sprint ID"
+// @Success 200 {object} models.Message "The sprint was deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The sprint does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints/{sprint} [delete]
+func (sp *Sprint) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	return deleteSprints(s, builder.Eq{"id": sp.ID})
+}
+
+// SprintClose closes a sprint and moves its unfinished tasks into the next sprint.
+type SprintClose struct {
+	// The sprint to close.
+	SprintID int64 `json:"-" param:"sprint"`
+	// The project of the sprint.
+	ProjectID int64 `json:"-" param:"project"`
+	// The sprint all unfinished tasks are moved to. If not provided, unfinished tasks are removed from the sprint.
+	NextSprintID int64 `json:"next_sprint_id"`
+
+	// The closed sprint.
+	Sprint *Sprint `json:"sprint,omitempty"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// getSprintTasks returns all entries of tasks in a sprint together with the tasks. Entries of tasks which are in the
+// trash are skipped.
+func getSprintTasks(s *xorm.Session, sprintID int64) (entries []*SprintTask, tasks map[int64]*Task, err error) {
+	entries = []*SprintTask{}
+	err = s.
+		Where("sprint_id = ?", sprintID).
+		OrderBy("id asc").
+		Find(&entries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskIDs := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		taskIDs = append(taskIDs, entry.TaskID)
+	}
+
+	tasks = make(map[int64]*Task, len(entries))
+	if len(taskIDs) > 0 {
+		err = s.In("id", taskIDs).Find(&tasks)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	existing := make([]*SprintTask, 0, len(entries))
+	for _, entry := range entries {
+		if _, has := tasks[entry.TaskID]; has {
+			existing = append(existing, entry)
+		}
+	}
+
+	return existing, tasks, nil
+}
+
+// Create closes a sprint
+// @Summary Close a sprint
+// @Description Closes a sprint. Done tasks are marked as completed in the sprint. If a next sprint is provided, all unfinished tasks are carried over into it, otherwise they are removed from the sprint. Closed sprints can't be reopened.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param sprint path int true "Sprint ID"
+// @Param close body models.SprintClose true "The sprint to carry unfinished tasks over to."
+// @Success 201 {object} models.SprintClose "The closed sprint."
+// @Failure 400 {object} web.HTTPError "The next sprint belongs to another project."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The sprint does not exist."
+// @Failure 412 {object} web.HTTPError "The sprint or the next sprint is already closed."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints/{sprint}/close [post]
+func (sc *SprintClose) Create(s *xorm.Session, _ web.Auth) (err error) {
+	if !sc.Sprint.ClosedAt.IsZero() {
+		return ErrSprintIsClosed{SprintID: sc.Sprint.ID}
+	}
+
+	if sc.NextSprintID != 0 {
+		next, err := getSprintByID(s, sc.NextSprintID)
+		if err != nil {
+			return err
+		}
+		if next.ProjectID != sc.Sprint.ProjectID {
+			return ErrSprintDoesNotBelongToProject{SprintID: next.ID, ProjectID: sc.Sprint.ProjectID}
+		}
+		if next.ID == sc.Sprint.ID || !next.ClosedAt.IsZero() {
+			return ErrSprintIsClosed{SprintID: next.ID}
+		}
+	}
+
+	entries, tasks, err := getSprintTasks(s, sc.Sprint.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		task := tasks[entry.TaskID]
+		if task.Done {
+			entry.Completed = true
+		} else {
+			entry.CarriedOver = sc.NextSprintID != 0
+			task.SprintID = sc.NextSprintID
+
+			_, err = s.
+				Where("id = ?", task.ID).
+				Cols("sprint_id").
+				Update(task)
+			if err != nil {
+				return err
+			}
+
+			if sc.NextSprintID != 0 {
+				err = addTaskToSprint(s, task.ID, sc.NextSprintID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = s.
+			Where("id = ?", entry.ID).
+			Cols("completed", "carried_over").
+			Update(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	sc.Sprint.ClosedAt = time.Now()
+	_, err = s.
+		Where("id = ?", sc.Sprint.ID).
+		Cols("closed_at").
+		Update(sc.Sprint)
+	return err
+}
+
+// SprintReportValue holds the number of tasks and the sum of their estimates for one part of a sprint report.
+type SprintReportValue struct {
+	Tasks    int64   `json:"tasks"`
+	Estimate float64 `json:"estimate"`
+}
+
+func (v *SprintReportValue) add(t *Task) {
+	v.Tasks++
+	v.Estimate += t.Estimate
+}
+
+// SprintReport summarizes a sprint.
+type SprintReport struct {
+	// The sprint of this report.
+	SprintID int64 `json:"-" param:"sprint"`
+	// The project of the sprint.
+	ProjectID int64 `json:"-" param:"project"`
+
+	// The sprint of this report.
+	Sprint *Sprint `json:"sprint"`
+	// All tasks which were added to the sprint until its start date.
+	Committed SprintReportValue `json:"committed"`
+	// All tasks which were added to the sprint after its start date.
+	Added SprintReportValue `json:"added"`
+	// All tasks which were done. For closed sprints, these are the tasks which were done when the sprint was closed.
+	Completed SprintReportValue `json:"completed"`
+	// All tasks which are not done yet or were not done when the sprint was closed.
+	Unfinished SprintReportValue `json:"unfinished"`
+	// All tasks which were moved to the next sprint when the sprint was closed.
+	CarriedOver SprintReportValue `json:"carried_over"`
+	// The sum of the estimates of all completed tasks.
+	Velocity float64 `json:"velocity"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// ReadOne returns the report of a sprint
+// @Summary Get the report of a sprint
+// @Description Returns how many tasks were planned for a sprint, added to it after it started, completed, unfinished and carried over into the next sprint, together with the sum of their estimates. The velocity of the sprint is the sum of the estimates of all completed tasks.
+// @tags sprint
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param sprint path int true "Sprint ID"
+// @Success 200 {object} models.SprintReport "The report of the sprint"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The sprint does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/sprints/{sprint}/report [get]
+func (sr *SprintReport) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	entries, tasks, err := getSprintTasks(s, sr.Sprint.ID)
+	if err != nil {
+		return err
+	}
+
+	closed := !sr.Sprint.ClosedAt.IsZero()
+	for _, entry := range entries {
+		task := tasks[entry.TaskID]
+
+		if entry.Added.After(sr.Sprint.StartDate) {
+			sr.Added.add(task)
+		} else {
+			sr.Committed.add(task)
+		}
+
+		if (closed && entry.Completed) || (!closed && task.Done) {
+			sr.Completed.add(task)
+		} else {
+			sr.Unfinished.add(task)
+		}
+
+		if entry.CarriedOver {
+			sr.CarriedOver.add(task)
+		}
+	}
+
+	sr.Velocity = sr.Completed.Estimate
+	return nil
+}
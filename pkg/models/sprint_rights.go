@@ -0,0 +1,97 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if a user can create a sprint in a project
+func (sp *Sprint) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return (&Project{ID: sp.ProjectID}).CanWrite(s, a)
+}
+
+// CanRead checks if a user can see a sprint
+func (sp *Sprint) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	sprint, err := sp.getSprintOfProject(s)
+	if err != nil {
+		return false, 0, err
+	}
+
+	can, maxRight, err := (&Project{ID: sprint.ProjectID}).CanRead(s, a)
+	if err != nil || !can {
+		return false, 0, err
+	}
+
+	*sp = *sprint
+	return true, maxRight, nil
+}
+
+// CanUpdate checks if a user can update a sprint
+func (sp *Sprint) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	// A normal check would replace the passed struct which in our case would override the values we want to update.
+	spp := &Sprint{ID: sp.ID, ProjectID: sp.ProjectID}
+	return spp.canDoSprint(s, a)
+}
+
+// CanDelete checks if a user can delete a sprint
+func (sp *Sprint) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return sp.canDoSprint(s, a)
+}
+
+// getSprintOfProject returns the sprint and makes sure it belongs to the project it was requested with.
+func (sp *Sprint) getSprintOfProject(s *xorm.Session) (*Sprint, error) {
+	sprint, err := getSprintByID(s, sp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sp.ProjectID != 0 && sprint.ProjectID != sp.ProjectID {
+		return nil, ErrSprintDoesNotExist{SprintID: sp.ID}
+	}
+
+	return sprint, nil
+}
+
+// canDoSprint checks if the sprint exists and if the user has the right to change it
+func (sp *Sprint) canDoSprint(s *xorm.Session, a web.Auth) (bool, error) {
+	sprint, err := sp.getSprintOfProject(s)
+	if err != nil {
+		return false, err
+	}
+
+	can, err := (&Project{ID: sprint.ProjectID}).CanWrite(s, a)
+	if err != nil || !can {
+		return false, err
+	}
+
+	*sp = *sprint
+	return true, nil
+}
+
+// CanCreate checks if a user can close a sprint
+func (sc *SprintClose) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	sc.Sprint = &Sprint{ID: sc.SprintID, ProjectID: sc.ProjectID}
+	return sc.Sprint.canDoSprint(s, a)
+}
+
+// CanRead checks if a user can see the report of a sprint
+func (sr *SprintReport) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	sr.Sprint = &Sprint{ID: sr.SprintID, ProjectID: sr.ProjectID}
+	return sr.Sprint.CanRead(s, a)
+}
@@ -0,0 +1,573 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func setTaskSprint(t *testing.T, s *xorm.Session, taskID, sprintID int64) error {
+	task, err := GetTaskByIDSimple(s, taskID)
+	assert.NoError(t, err)
+	task.SprintID = sprintID
+	return task.Update(s, &user.User{ID: 1})
+}
+
+func TestSprint_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{
+			Title:     "Sprint 3",
+			ProjectID: 1,
+			StartDate: time.Date(2018, 12, 29, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+		}
+		can, err := sprint.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sprint.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "sprints", map[string]interface{}{
+			"id":         sprint.ID,
+			"title":      "Sprint 3",
+			"project_id": 1,
+		}, false)
+	})
+	t.Run("end before start", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{
+			Title:     "Sprint 3",
+			ProjectID: 1,
+			StartDate: time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2018, 12, 29, 0, 0, 0, 0, time.UTC),
+		}
+		err := sprint.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidSprintDates(err))
+	})
+	t.Run("no write access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{ProjectID: 9}
+		can, err := sprint.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestSprint_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{ProjectID: 1}
+		result, count, total, err := sprint.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, int64(2), total)
+		sprints := result.([]*Sprint)
+		require.Len(t, sprints, 2)
+		assert.Equal(t, int64(1), sprints[0].ID)
+		assert.Equal(t, int64(2), sprints[1].ID)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{ProjectID: 2}
+		_, _, _, err := sprint.ReadAll(s, u, "", 0, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToProject(err))
+	})
+}
+
+func TestSprint_CanRead(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{ID: 1, ProjectID: 1}
+		can, _, err := sprint.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		assert.Equal(t, "Sprint 1", sprint.Title)
+	})
+	t.Run("sprint of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{ID: 3, ProjectID: 1}
+		_, _, err := sprint.CanRead(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrSprintDoesNotExist(err))
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{ID: 3, ProjectID: 2}
+		can, _, err := sprint.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestSprint_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{
+			ID:        2,
+			ProjectID: 1,
+			Title:     "Sprint 2 (extended)",
+			StartDate: time.Date(2018, 12, 15, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC),
+		}
+		can, err := sprint.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sprint.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "sprints", map[string]interface{}{
+			"id":    2,
+			"title": "Sprint 2 (extended)",
+		}, false)
+	})
+	t.Run("without end date", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sprint := &Sprint{
+			ID:        2,
+			ProjectID: 1,
+			Title:     "Sprint 2",
+			StartDate: time.Date(2018, 12, 15, 0, 0, 0, 0, time.UTC),
+		}
+		err := sprint.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidSprintDates(err))
+	})
+}
+
+func TestSprint_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	err := setTaskSprint(t, s, 1, 2)
+	assert.NoError(t, err)
+
+	sprint := &Sprint{ID: 2, ProjectID: 1}
+	can, err := sprint.CanDelete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = sprint.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "sprints", map[string]interface{}{
+		"id": 2,
+	})
+	db.AssertMissing(t, "sprint_tasks", map[string]interface{}{
+		"sprint_id": 2,
+	})
+	db.AssertExists(t, "tasks", map[string]interface{}{
+		"id":        1,
+		"sprint_id": 0,
+	}, false)
+}
+
+func TestTask_Sprint(t *testing.T) {
+	t.Run("add to sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":        1,
+			"sprint_id": 2,
+		}, false)
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": 2,
+			"task_id":   1,
+		}, false)
+	})
+	t.Run("remove from sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+		err = setTaskSprint(t, s, 1, 0)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": 2,
+			"task_id":   1,
+		})
+	})
+	t.Run("remove from a closed sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 10 was not finished in the closed sprint 1
+		_, err := s.Where("id = ?", 10).Cols("sprint_id").NoAutoTime().Update(&Task{SprintID: 1})
+		assert.NoError(t, err)
+
+		err = setTaskSprint(t, s, 10, 2)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": 1,
+			"task_id":   10,
+		}, false)
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": 2,
+			"task_id":   10,
+		}, false)
+	})
+	t.Run("closed sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrSprintIsClosed(err))
+	})
+	t.Run("sprint of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 3)
+		assert.Error(t, err)
+		assert.True(t, IsErrSprintDoesNotBelongToProject(err))
+	})
+	t.Run("on create", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			Title:     "Lorem",
+			ProjectID: 1,
+			SprintID:  2,
+		}
+		err := task.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": 2,
+			"task_id":   task.ID,
+		}, false)
+	})
+	t.Run("moving the task to another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+
+		task, err := GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+		task.ProjectID = 10
+		task.BucketID = 0
+		err = task.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), task.SprintID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": 2,
+			"task_id":   1,
+		})
+	})
+	t.Run("filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+		err = setTaskSprint(t, s, 2, 2)
+		assert.NoError(t, err)
+
+		tc := &TaskCollection{
+			ProjectID:        1,
+			FilterBy:         []string{"sprint_id"},
+			FilterValue:      []string{"2"},
+			FilterComparator: []string{"equals"},
+		}
+		result, _, _, err := tc.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		assert.NoError(t, err)
+		tasks := result.([]*Task)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, int64(1), tasks[0].ID)
+		assert.Equal(t, int64(2), tasks[1].ID)
+	})
+}
+
+func TestSprintClose_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	createNextSprint := func(t *testing.T, s *xorm.Session) *Sprint {
+		next := &Sprint{
+			Title:     "Sprint 3",
+			ProjectID: 1,
+			StartDate: time.Date(2018, 12, 29, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+		}
+		err := next.Create(s, u)
+		assert.NoError(t, err)
+		return next
+	}
+
+	t.Run("carry over into the next sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		next := createNextSprint(t, s)
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+		err = setTaskSprint(t, s, 2, 2)
+		assert.NoError(t, err)
+
+		sc := &SprintClose{
+			SprintID:     2,
+			ProjectID:    1,
+			NextSprintID: next.ID,
+		}
+		can, err := sc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sc.Create(s, u)
+		assert.NoError(t, err)
+		assert.False(t, sc.Sprint.ClosedAt.IsZero())
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id":    2,
+			"task_id":      1,
+			"carried_over": true,
+			"completed":    false,
+		}, false)
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id":    2,
+			"task_id":      2,
+			"carried_over": false,
+			"completed":    true,
+		}, false)
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id": next.ID,
+			"task_id":   1,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":        1,
+			"sprint_id": next.ID,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":        2,
+			"sprint_id": 2,
+		}, false)
+	})
+	t.Run("without next sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+
+		sc := &SprintClose{SprintID: 2, ProjectID: 1}
+		can, err := sc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sc.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":        1,
+			"sprint_id": 0,
+		}, false)
+		db.AssertExists(t, "sprint_tasks", map[string]interface{}{
+			"sprint_id":    2,
+			"task_id":      1,
+			"carried_over": false,
+			"completed":    false,
+		}, false)
+	})
+	t.Run("already closed", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sc := &SprintClose{SprintID: 1, ProjectID: 1}
+		can, err := sc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sc.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrSprintIsClosed(err))
+	})
+	t.Run("closed next sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sc := &SprintClose{SprintID: 2, ProjectID: 1, NextSprintID: 1}
+		can, err := sc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sc.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrSprintIsClosed(err))
+	})
+	t.Run("next sprint of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sc := &SprintClose{SprintID: 2, ProjectID: 1, NextSprintID: 3}
+		can, err := sc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sc.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrSprintDoesNotBelongToProject(err))
+	})
+}
+
+func TestSprintReport_ReadOne(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("closed sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 2).Cols("estimate").Update(&Task{Estimate: 3})
+		assert.NoError(t, err)
+		_, err = s.In("id", []int64{10, 11}).Cols("estimate").Update(&Task{Estimate: 2})
+		assert.NoError(t, err)
+
+		sr := &SprintReport{SprintID: 1, ProjectID: 1}
+		can, _, err := sr.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sr.ReadOne(s, u)
+		assert.NoError(t, err)
+
+		assert.Equal(t, SprintReportValue{Tasks: 2, Estimate: 5}, sr.Committed)
+		assert.Equal(t, SprintReportValue{Tasks: 1, Estimate: 2}, sr.Added)
+		assert.Equal(t, SprintReportValue{Tasks: 1, Estimate: 3}, sr.Completed)
+		assert.Equal(t, SprintReportValue{Tasks: 2, Estimate: 4}, sr.Unfinished)
+		assert.Equal(t, SprintReportValue{}, sr.CarriedOver)
+		assert.Equal(t, 3.0, sr.Velocity)
+	})
+	t.Run("open sprint", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := setTaskSprint(t, s, 1, 2)
+		assert.NoError(t, err)
+		err = setTaskSprint(t, s, 2, 2)
+		assert.NoError(t, err)
+
+		sr := &SprintReport{SprintID: 2, ProjectID: 1}
+		can, _, err := sr.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = sr.ReadOne(s, u)
+		assert.NoError(t, err)
+
+		// Both tasks were added after the start date of the sprint
+		assert.Equal(t, int64(0), sr.Committed.Tasks)
+		assert.Equal(t, int64(2), sr.Added.Tasks)
+		assert.Equal(t, int64(1), sr.Completed.Tasks)
+		assert.Equal(t, int64(1), sr.Unfinished.Tasks)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sr := &SprintReport{SprintID: 3, ProjectID: 2}
+		can, _, err := sr.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -28,16 +28,37 @@ func (ta *TaskAttachment) CanRead(s *xorm.Session, a web.Auth) (bool, int, error
 }
 
 // CanDelete checks if the user can delete an attachment
+// Users who can only comment on a task can delete the attachments they uploaded themselves.
 func (ta *TaskAttachment) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
 	t := &Task{ID: ta.TaskID}
-	return t.CanWrite(s, a)
-}
+	canWrite, err := t.CanWrite(s, a)
+	if err != nil || canWrite {
+		return canWrite, err
+	}
 
-// CanCreate checks if the user can create an attachment
-func (ta *TaskAttachment) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	t, err := GetTaskByIDSimple(s, ta.TaskID)
+	canComment, err := t.CanComment(s, a)
+	if err != nil || !canComment {
+		return false, err
+	}
+
+	existing := &TaskAttachment{}
+	exists, err := s.
+		Where("id = ? AND task_id = ?", ta.ID, ta.TaskID).
+		Get(existing)
 	if err != nil {
 		return false, err
 	}
-	return t.CanCreate(s, a)
+	if !exists {
+		return false, ErrTaskAttachmentDoesNotExist{
+			TaskID:       ta.TaskID,
+			AttachmentID: ta.ID,
+		}
+	}
+	return existing.CreatedByID == a.GetID(), nil
+}
+
+// CanCreate checks if the user can create an attachment
+func (ta *TaskAttachment) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: ta.TaskID}
+	return t.CanComment(s, a)
 }
@@ -0,0 +1,532 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"math"
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// TaskChecklistItem is a single item of the checklist of a task
+type TaskChecklistItem struct {
+	// The unique, numeric id of this checklist item.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"checklistitem"`
+	// The task this item belongs to.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+	// The title of the item.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// Whether the item is done.
+	Done bool `xorm:"not null default false" json:"done"`
+	// When the item was marked as done.
+	DoneAt time.Time `xorm:"DATETIME null" json:"done_at"`
+	// The position of the item in the checklist. Items are sorted by position in ascending order.
+	Position float64 `xorm:"double null" json:"position"`
+	// The id of the user who is responsible for this item. Needs to have access to the project of the task.
+	AssigneeID int64 `xorm:"bigint null" json:"assignee_id"`
+	// The user who is responsible for this item.
+	Assignee *user.User `xorm:"-" json:"assignee"`
+	// When this item is due.
+	DueDate time.Time `xorm:"DATETIME null" json:"due_date"`
+
+	CreatedByID int64 `xorm:"bigint not null" json:"-"`
+	// The user who created this item.
+	CreatedBy *user.User `xorm:"-" json:"created_by"`
+
+	// A timestamp when this item was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this item was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for checklist items
+func (*TaskChecklistItem) TableName() string {
+	return "task_checklist_items"
+}
+
+// TaskChecklistProgress holds how many items of the checklist of a task are done.
+type TaskChecklistProgress struct {
+	// The number of items in the checklist.
+	Total int64 `json:"total"`
+	// The number of done items in the checklist.
+	Done int64 `json:"done"`
+}
+
+func getChecklistItem(s *xorm.Session, taskID, itemID int64) (item *TaskChecklistItem, err error) {
+	item = &TaskChecklistItem{}
+	exists, err := s.
+		Where("id = ? AND task_id = ?", itemID, taskID).
+		Get(item)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrChecklistItemDoesNotExist{ID: itemID, TaskID: taskID}
+	}
+	return item, nil
+}
+
+func getChecklistItemsForTasks(s *xorm.Session, taskIDs []int64) (items []*TaskChecklistItem, err error) {
+	items = []*TaskChecklistItem{}
+	err = s.
+		In("task_id", taskIDs).
+		OrderBy("position asc, id asc").
+		Find(&items)
+	return
+}
+
+// addChecklistItemsToTasks adds the checklist items and the checklist progress to all tasks in the map.
+func addChecklistItemsToTasks(s *xorm.Session, taskIDs []int64, taskMap map[int64]*Task) error {
+	items, err := getChecklistItemsForTasks(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		task, has := taskMap[item.TaskID]
+		if !has {
+			continue
+		}
+
+		if task.ChecklistProgress == nil {
+			task.ChecklistProgress = &TaskChecklistProgress{}
+		}
+		task.ChecklistProgress.Total++
+		if item.Done {
+			task.ChecklistProgress.Done++
+		}
+		task.ChecklistItems = append(task.ChecklistItems, item)
+	}
+
+	return nil
+}
+
+// checkChecklistItemAssignee makes sure the assignee of a checklist item can see the task.
+func checkChecklistItemAssignee(s *xorm.Session, task *Task, assigneeID int64) error {
+	if assigneeID == 0 {
+		return nil
+	}
+
+	assignee, err := user.GetUserByID(s, assigneeID)
+	if err != nil {
+		return err
+	}
+	project := &Project{ID: task.ProjectID}
+	canRead, _, err := project.CanRead(s, assignee)
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return ErrUserDoesNotHaveAccessToProject{ProjectID: task.ProjectID, UserID: assigneeID}
+	}
+	return nil
+}
+
+// recalculateChecklistPositions spreads the positions of all items of a checklist evenly while keeping their order.
+func recalculateChecklistPositions(s *xorm.Session, taskID int64) error {
+	items, err := getChecklistItemsForTasks(s, []int64{taskID})
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		item.Position = float64(i+1) * math.Pow(2, 16)
+		_, err = s.ID(item.ID).Cols("position").NoAutoTime().Update(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create adds a new item to the checklist of a task
+// @Summary Add a checklist item
+// @Description Adds a new item to the checklist of a task. The user needs write access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param item body models.TaskChecklistItem true "The checklist item"
+// @Success 201 {object} models.TaskChecklistItem "The created checklist item."
+// @Failure 400 {object} web.HTTPError "Invalid checklist item provided."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist [put]
+func (item *TaskChecklistItem) Create(s *xorm.Session, a web.Auth) (err error) {
+	task, err := GetTaskByIDSimple(s, item.TaskID)
+	if err != nil {
+		return err
+	}
+
+	err = checkChecklistItemAssignee(s, &task, item.AssigneeID)
+	if err != nil {
+		return err
+	}
+
+	item.CreatedBy, err = GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+	item.CreatedByID = item.CreatedBy.ID
+
+	item.ID = 0
+	item.DoneAt = time.Time{}
+	if item.Done {
+		item.DoneAt = time.Now()
+	}
+
+	_, err = s.Insert(item)
+	if err != nil {
+		return err
+	}
+
+	if item.Position == 0 {
+		item.Position = calculateDefaultPosition(item.ID, item.Position)
+		_, err = s.ID(item.ID).Cols("position").Update(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	if item.AssigneeID != 0 {
+		item.Assignee, err = user.GetUserByID(s, item.AssigneeID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return updateTaskLastUpdated(s, &task)
+}
+
+// ReadAll returns all items of the checklist of a task
+// @Summary Get the checklist of a task
+// @Description Returns all items of the checklist of a task, sorted by their position.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Success 200 {array} models.TaskChecklistItem "The checklist items."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist [get]
+func (item *TaskChecklistItem) ReadAll(s *xorm.Session, _ web.Auth, _ string, _ int, _ int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	items, err := getChecklistItemsForTasks(s, []int64{item.TaskID})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(items)*2)
+	for _, i := range items {
+		userIDs = append(userIDs, i.CreatedByID)
+		if i.AssigneeID != 0 {
+			userIDs = append(userIDs, i.AssigneeID)
+		}
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, i := range items {
+		i.CreatedBy = users[i.CreatedByID]
+		i.Assignee = users[i.AssigneeID]
+	}
+
+	return items, len(items), int64(len(items)), nil
+}
+
+// Update changes a checklist item
+// @Summary Update a checklist item
+// @Description Updates an item of the checklist of a task. Set a position to move the item within the checklist. The user needs write access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Param item body models.TaskChecklistItem true "The checklist item"
+// @Success 200 {object} models.TaskChecklistItem "The updated checklist item."
+// @Failure 400 {object} web.HTTPError "Invalid checklist item provided."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem} [post]
+func (item *TaskChecklistItem) Update(s *xorm.Session, _ web.Auth) (err error) {
+	old, err := getChecklistItem(s, item.TaskID, item.ID)
+	if err != nil {
+		return err
+	}
+
+	task, err := GetTaskByIDSimple(s, item.TaskID)
+	if err != nil {
+		return err
+	}
+
+	if item.AssigneeID != old.AssigneeID {
+		err = checkChecklistItemAssignee(s, &task, item.AssigneeID)
+		if err != nil {
+			return err
+		}
+	}
+
+	item.DoneAt = old.DoneAt
+	if item.Done && !old.Done {
+		item.DoneAt = time.Now()
+	}
+	if !item.Done {
+		item.DoneAt = time.Time{}
+	}
+	item.CreatedByID = old.CreatedByID
+	item.Created = old.Created
+
+	_, err = s.
+		ID(item.ID).
+		Cols("title", "done", "done_at", "position", "assignee_id", "due_date").
+		Update(item)
+	if err != nil {
+		return err
+	}
+
+	if item.Position < 0.1 {
+		err = recalculateChecklistPositions(s, item.TaskID)
+		if err != nil {
+			return err
+		}
+		updated, err := getChecklistItem(s, item.TaskID, item.ID)
+		if err != nil {
+			return err
+		}
+		item.Position = updated.Position
+	}
+
+	if item.AssigneeID != 0 {
+		item.Assignee, err = user.GetUserByID(s, item.AssigneeID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return updateTaskLastUpdated(s, &task)
+}
+
+// Delete removes a checklist item
+// @Summary Remove a checklist item
+// @Description Removes an item from the checklist of a task. The user needs write access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Success 200 {object} models.Message "The checklist item was deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem} [delete]
+func (item *TaskChecklistItem) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = getChecklistItem(s, item.TaskID, item.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ID(item.ID).Delete(&TaskChecklistItem{})
+	if err != nil {
+		return err
+	}
+
+	return updateTaskLastUpdated(s, &Task{ID: item.TaskID})
+}
+
+// CanRead checks if a user can see the checklist of a task
+func (item *TaskChecklistItem) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	t := &Task{ID: item.TaskID}
+	return t.CanRead(s, a)
+}
+
+// CanCreate checks if a user can add items to the checklist of a task
+func (item *TaskChecklistItem) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: item.TaskID}
+	return t.CanUpdate(s, a)
+}
+
+// CanUpdate checks if a user can change an item of the checklist of a task
+func (item *TaskChecklistItem) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: item.TaskID}
+	return t.CanUpdate(s, a)
+}
+
+// CanDelete checks if a user can remove an item from the checklist of a task
+func (item *TaskChecklistItem) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: item.TaskID}
+	return t.CanUpdate(s, a)
+}
+
+// TaskChecklistOrder sorts the checklist of a task
+type TaskChecklistOrder struct {
+	// The task the checklist belongs to.
+	TaskID int64 `json:"-" param:"task"`
+	// The ids of the checklist items in their new order. Items which are not part of the list keep their order
+	// and are moved after all listed items.
+	ItemIDs []int64 `json:"item_ids"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanCreate checks if a user can sort the checklist of a task
+func (tco *TaskChecklistOrder) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: tco.TaskID}
+	return t.CanUpdate(s, a)
+}
+
+// Create sorts the checklist of a task
+// @Summary Reorder a checklist
+// @Description Moves the items of the checklist of a task into the provided order.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param order body models.TaskChecklistOrder true "The new order of the items"
+// @Success 201 {object} models.TaskChecklistOrder "The new order of the items."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the task."
+// @Failure 404 {object} web.HTTPError "One of the items does not belong to the checklist of the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/reorder [post]
+func (tco *TaskChecklistOrder) Create(s *xorm.Session, _ web.Auth) (err error) {
+	items, err := getChecklistItemsForTasks(s, []int64{tco.TaskID})
+	if err != nil {
+		return err
+	}
+
+	itemMap := make(map[int64]*TaskChecklistItem, len(items))
+	for _, item := range items {
+		itemMap[item.ID] = item
+	}
+
+	ordered := make([]*TaskChecklistItem, 0, len(items))
+	listed := make(map[int64]bool, len(tco.ItemIDs))
+	for _, id := range tco.ItemIDs {
+		item, has := itemMap[id]
+		if !has {
+			return ErrChecklistItemDoesNotExist{ID: id, TaskID: tco.TaskID}
+		}
+		if listed[id] {
+			continue
+		}
+		listed[id] = true
+		ordered = append(ordered, item)
+	}
+	for _, item := range items {
+		if !listed[item.ID] {
+			ordered = append(ordered, item)
+		}
+	}
+
+	tco.ItemIDs = make([]int64, 0, len(ordered))
+	for i, item := range ordered {
+		item.Position = float64(i+1) * math.Pow(2, 16)
+		_, err = s.ID(item.ID).Cols("position").Update(item)
+		if err != nil {
+			return err
+		}
+		tco.ItemIDs = append(tco.ItemIDs, item.ID)
+	}
+
+	return updateTaskLastUpdated(s, &Task{ID: tco.TaskID})
+}
+
+// TaskChecklistItemConversion turns a checklist item into a task
+type TaskChecklistItemConversion struct {
+	// The task the checklist belongs to.
+	TaskID int64 `json:"-" param:"task"`
+	// The item to convert.
+	ItemID int64 `json:"-" param:"checklistitem"`
+	// The new task.
+	Task *Task `json:"task"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanCreate checks if a user can turn a checklist item into a task
+func (tcc *TaskChecklistItemConversion) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: tcc.TaskID}
+	return t.CanUpdate(s, a)
+}
+
+// Create turns a checklist item into a task
+// @Summary Convert a checklist item into a task
+// @Description Creates a new task from a checklist item in the same project and makes it a subtask of the task the checklist belongs to. Title, done state, due date and assignee are taken over. The checklist item is removed.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Success 201 {object} models.TaskChecklistItemConversion "The new task."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem}/convert [put]
+func (tcc *TaskChecklistItemConversion) Create(s *xorm.Session, a web.Auth) (err error) {
+	item, err := getChecklistItem(s, tcc.TaskID, tcc.ItemID)
+	if err != nil {
+		return err
+	}
+
+	parent, err := GetTaskByIDSimple(s, tcc.TaskID)
+	if err != nil {
+		return err
+	}
+
+	tcc.Task = &Task{
+		Title:     item.Title,
+		ProjectID: parent.ProjectID,
+		Done:      item.Done,
+		DueDate:   item.DueDate,
+	}
+	if item.AssigneeID != 0 {
+		tcc.Task.Assignees = []*user.User{{ID: item.AssigneeID}}
+	}
+	err = createTask(s, tcc.Task, a, true)
+	if err != nil {
+		return err
+	}
+
+	relation := &TaskRelation{
+		TaskID:       tcc.Task.ID,
+		OtherTaskID:  parent.ID,
+		RelationKind: RelationKindSubtask,
+	}
+	err = relation.Create(s, a)
+	if err != nil {
+		return err
+	}
+
+	return item.Delete(s, a)
+}
@@ -0,0 +1,218 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskChecklistItem_Create(t *testing.T) {
+	u := &user.User{ID: 13}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{TaskID: 34, Title: "new item", AssigneeID: 13}
+		err := item.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(13), item.CreatedByID)
+		assert.Equal(t, int64(13), item.Assignee.ID)
+		assert.NotEqual(t, float64(0), item.Position)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+			"id":          item.ID,
+			"task_id":     34,
+			"title":       "new item",
+			"assignee_id": 13,
+		}, false)
+	})
+	t.Run("assignee without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{TaskID: 34, Title: "new item", AssigneeID: 1}
+		err := item.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToProject(err))
+	})
+	t.Run("nonexisting task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{TaskID: 9999, Title: "new item"}
+		err := item.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+	})
+}
+
+func TestTaskChecklistItem_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	item := &TaskChecklistItem{TaskID: 34}
+	result, resultCount, _, err := item.ReadAll(s, &user.User{ID: 13}, "", 0, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, resultCount)
+	items := result.([]*TaskChecklistItem)
+	require.Len(t, items, 3)
+	assert.Equal(t, int64(1), items[0].ID)
+	assert.Equal(t, int64(13), items[1].Assignee.ID)
+	assert.Equal(t, int64(13), items[2].CreatedBy.ID)
+}
+
+func TestTaskChecklistItem_Update(t *testing.T) {
+	u := &user.User{ID: 13}
+
+	t.Run("mark as done", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{ID: 2, TaskID: 34, Title: "item #2", Done: true, Position: 131072}
+		err := item.Update(s, u)
+		assert.NoError(t, err)
+		assert.False(t, item.DoneAt.IsZero())
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+			"id":          2,
+			"done":        true,
+			"assignee_id": 0,
+		}, false)
+	})
+	t.Run("wrong task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{ID: 2, TaskID: 1, Title: "item #2"}
+		err := item.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrChecklistItemDoesNotExist(err))
+	})
+}
+
+func TestTaskChecklistItem_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	item := &TaskChecklistItem{ID: 3, TaskID: 34}
+	err := item.Delete(s, &user.User{ID: 13})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "task_checklist_items", map[string]interface{}{
+		"id": 3,
+	})
+}
+
+func TestTaskChecklistItem_Rights(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	item := &TaskChecklistItem{TaskID: 34}
+	can, err := item.CanCreate(s, &user.User{ID: 13})
+	assert.NoError(t, err)
+	assert.True(t, can)
+	can, err = item.CanCreate(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.False(t, can)
+}
+
+func TestTaskChecklistOrder_Create(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	order := &TaskChecklistOrder{TaskID: 34, ItemIDs: []int64{3, 1}}
+	err := order.Create(s, &user.User{ID: 13})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 1, 2}, order.ItemIDs)
+
+	items, err := getChecklistItemsForTasks(s, []int64{34})
+	assert.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, int64(3), items[0].ID)
+	assert.Equal(t, int64(1), items[1].ID)
+	assert.Equal(t, int64(2), items[2].ID)
+
+	order = &TaskChecklistOrder{TaskID: 34, ItemIDs: []int64{9999}}
+	err = order.Create(s, &user.User{ID: 13})
+	assert.Error(t, err)
+	assert.True(t, IsErrChecklistItemDoesNotExist(err))
+}
+
+func TestTaskChecklistItemConversion_Create(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	conversion := &TaskChecklistItemConversion{TaskID: 34, ItemID: 2}
+	err := conversion.Create(s, &user.User{ID: 13})
+	assert.NoError(t, err)
+	assert.Equal(t, "item #2", conversion.Task.Title)
+	assert.Equal(t, int64(20), conversion.Task.ProjectID)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "task_checklist_items", map[string]interface{}{
+		"id": 2,
+	})
+	db.AssertExists(t, "task_assignees", map[string]interface{}{
+		"task_id": conversion.Task.ID,
+		"user_id": 13,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       conversion.Task.ID,
+		"other_task_id": 34,
+		"relation_kind": RelationKindSubtask,
+	}, false)
+}
+
+func TestAddChecklistItemsToTasks(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	taskMap := map[int64]*Task{
+		1:  {ID: 1},
+		34: {ID: 34},
+	}
+	err := addChecklistItemsToTasks(s, []int64{1, 34}, taskMap)
+	assert.NoError(t, err)
+	assert.Nil(t, taskMap[1].ChecklistProgress)
+	assert.Equal(t, &TaskChecklistProgress{Total: 3, Done: 1}, taskMap[34].ChecklistProgress)
+	assert.Len(t, taskMap[34].ChecklistItems, 3)
+}
@@ -74,7 +74,9 @@ func validateTaskField(fieldName string) error {
 		taskPropertyPosition,
 		taskPropertyKanbanPosition,
 		taskPropertyBucketID,
-		taskPropertyIndex:
+		taskPropertyIndex,
+		taskPropertyMilestoneID,
+		taskPropertySprintID:
 		return nil
 	}
 	return ErrInvalidTaskField{TaskField: fieldName}
@@ -179,6 +181,12 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 
 	shareAuth, is := a.(*LinkSharing)
 	if is {
+		// A link share of a single task only ever sees that task
+		if shareAuth.TaskID != 0 {
+			taskopts.sharedTaskIDs = []int64{shareAuth.TaskID}
+			return getTasksForProjects(s, []*Project{}, a, taskopts)
+		}
+
 		project, err := GetProjectSimpleByID(s, shareAuth.ProjectID)
 		if err != nil {
 			return nil, 0, 0, err
@@ -200,6 +208,12 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 		if err != nil {
 			return nil, 0, 0, err
 		}
+
+		// Tasks shared directly with the user show up as well
+		taskopts.sharedTaskIDs, err = getTaskIDsSharedWithUser(s, a.GetID())
+		if err != nil {
+			return nil, 0, 0, err
+		}
 	} else {
 		// Check the project exists and the user has access on it
 		project := &Project{ID: tf.ProjectID}
@@ -47,6 +47,8 @@ const (
 	taskPropertyKanbanPosition string = "kanban_position"
 	taskPropertyBucketID       string = "bucket_id"
 	taskPropertyIndex          string = "index"
+	taskPropertyMilestoneID    string = "milestone_id"
+	taskPropertySprintID       string = "sprint_id"
 )
 
 const (
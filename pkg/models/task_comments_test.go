@@ -24,6 +24,7 @@ import (
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/user"
 	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
 )
 
 func TestTaskComment_Create(t *testing.T) {
@@ -266,3 +267,127 @@ func TestTaskComment_ReadAll(t *testing.T) {
 		assert.Equal(t, int64(15), resultComment[0].ID)
 	})
 }
+
+func TestTaskComment_CommentRight(t *testing.T) {
+	u := &user.User{ID: 14}
+	shareProjectWithCommentRight := func(t *testing.T, s *xorm.Session) {
+		_, err := s.Insert(&ProjectUser{UserID: u.ID, ProjectID: 1, Right: RightComment})
+		assert.NoError(t, err)
+	}
+
+	t.Run("can read", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		shareProjectWithCommentRight(t, s)
+
+		task := &Task{ID: 2}
+		can, maxRight, err := task.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		// The comment right is reported as read so clients don't mistake it for more than admin
+		assert.Equal(t, int(RightRead), maxRight)
+	})
+	t.Run("can comment", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		shareProjectWithCommentRight(t, s)
+
+		tc := &TaskComment{TaskID: 2}
+		can, err := tc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		ta := &TaskAttachment{TaskID: 2}
+		can, err = ta.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("cannot modify other comments", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		shareProjectWithCommentRight(t, s)
+
+		tc := &TaskComment{ID: 1, TaskID: 1}
+		can, err := tc.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("cannot write", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		shareProjectWithCommentRight(t, s)
+
+		task := &Task{ID: 2}
+		can, err := task.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		bt := &BulkTask{IDs: []int64{2, 3}}
+		can, err = bt.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		ta := &TaskAttachment{TaskID: 2, FileID: 1, CreatedByID: 1}
+		_, err = s.Insert(ta)
+		assert.NoError(t, err)
+		can, err = ta.CanDelete(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("can delete own attachments", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		shareProjectWithCommentRight(t, s)
+
+		ta := &TaskAttachment{TaskID: 2, FileID: 1, CreatedByID: u.ID}
+		_, err := s.Insert(ta)
+		assert.NoError(t, err)
+		can, err := ta.CanDelete(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{ID: 1, ProjectID: 1, Right: RightComment}
+		tc := &TaskComment{TaskID: 2}
+		can, err := tc.CanCreate(s, share)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		task := &Task{ID: 2}
+		can, err = task.CanUpdate(s, share)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		project := &Project{ID: 1}
+		can, maxRight, err := project.CanRead(s, share)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		assert.Equal(t, int(RightRead), maxRight)
+	})
+	t.Run("task share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Insert(&TaskUser{UserID: u.ID, TaskID: 2, Right: RightComment})
+		assert.NoError(t, err)
+
+		tc := &TaskComment{TaskID: 2}
+		can, err := tc.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		task := &Task{ID: 2}
+		can, err = task.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
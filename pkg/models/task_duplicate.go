@@ -0,0 +1,305 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// TaskDuplicate holds everything needed to duplicate a task
+type TaskDuplicate struct {
+	// The id of the task to duplicate
+	TaskID int64 `json:"-" param:"projecttask"`
+	// The project to put the copy in. Defaults to the project of the task.
+	ProjectID int64 `json:"project_id,omitempty"`
+
+	// Whether to copy the labels of the task.
+	Labels bool `json:"labels"`
+	// Whether to copy the assignees of the task. Assignees without access to the target project are skipped.
+	Assignees bool `json:"assignees"`
+	// Whether to copy the reminders of the task.
+	Reminders bool `json:"reminders"`
+	// Whether to copy the attachments of the task, including their files.
+	Attachments bool `json:"attachments"`
+	// Whether to copy the comments of the task.
+	Comments bool `json:"comments"`
+	// Whether to copy all subtasks of the task as well. They are copied with the same options and become subtasks of the copy.
+	Subtasks bool `json:"subtasks"`
+
+	// The copied task
+	Task *Task `json:"duplicated_task,omitempty"`
+
+	web.Rights   `json:"-"`
+	web.CRUDable `json:"-"`
+}
+
+// CanCreate checks if a user has the right to duplicate a task
+func (td *TaskDuplicate) CanCreate(s *xorm.Session, a web.Auth) (canCreate bool, err error) {
+	// Task exists + user has read access to it
+	task := &Task{ID: td.TaskID}
+	canRead, _, err := task.CanRead(s, a)
+	if err != nil || !canRead {
+		return canRead, err
+	}
+
+	if td.ProjectID == 0 {
+		td.ProjectID = task.ProjectID
+	}
+
+	// Target project exists + user has write access to it
+	project := &Project{ID: td.ProjectID}
+	return project.CanWrite(s, a)
+}
+
+// Create duplicates a task
+// @Summary Duplicate an existing task
+// @Description Copies a task into the same or another project. Labels, assignees, reminders, attachments, comments and subtasks are only copied if requested, the checklist is always copied. The copy is linked to the original with a `copiedfrom` relation. The user needs read access to the task and write access to the target project.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param projecttask path int true "The task ID to duplicate"
+// @Param task body models.TaskDuplicate true "The target project and what should be copied."
+// @Success 201 {object} models.TaskDuplicate "The created task."
+// @Failure 400 {object} web.HTTPError "Invalid task duplicate object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task or the target project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{projecttask}/duplicate [put]
+func (td *TaskDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
+	project, err := GetProjectSimpleByID(s, td.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	td.Task, err = td.duplicateTask(s, td.TaskID, project, doer, map[int64]bool{})
+	return err
+}
+
+func (td *TaskDuplicate) duplicateTask(s *xorm.Session, taskID int64, project *Project, doer web.Auth, visited map[int64]bool) (task *Task, err error) {
+	visited[taskID] = true
+
+	original, err := GetTaskByIDSimple(s, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the task so that original still holds the values of the source task
+	duplicate := original
+	task = &duplicate
+	task.UID = ""
+	task.ProjectID = project.ID
+	task.Position = 0
+	task.KanbanPosition = 0
+	if original.ProjectID != project.ID {
+		task.BucketID = 0
+	}
+	if err := clearMilestoneIfNotInProject(s, task); err != nil {
+		return nil, err
+	}
+	if err := clearSprintIfNotInProject(s, task); err != nil {
+		return nil, err
+	}
+
+	if td.Reminders {
+		task.Reminders, err = getRemindersForTasks(s, []int64{taskID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = createTask(s, task, doer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Duplicated task %d into new task %d", taskID, task.ID)
+
+	relation := &TaskRelation{
+		TaskID:       task.ID,
+		OtherTaskID:  taskID,
+		RelationKind: RelationKindCopiedFrom,
+	}
+	err = relation.Create(s, doer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = td.duplicateTaskDetails(s, taskID, task, project, doer)
+	if err != nil {
+		return nil, err
+	}
+
+	if !td.Subtasks {
+		return task, nil
+	}
+
+	subtaskIDs, err := getSubtaskIDs(s, taskID)
+	if err != nil {
+		return nil, err
+	}
+	for _, subtaskID := range subtaskIDs {
+		if visited[subtaskID] {
+			continue
+		}
+
+		// Subtasks can live in projects the user has no access to, those must not be copied
+		can, _, err := (&Task{ID: subtaskID}).CanRead(s, doer)
+		if err != nil {
+			return nil, err
+		}
+		if !can {
+			continue
+		}
+
+		subtask, err := td.duplicateTask(s, subtaskID, project, doer, visited)
+		if err != nil {
+			return nil, err
+		}
+
+		relation := &TaskRelation{
+			TaskID:       subtask.ID,
+			OtherTaskID:  task.ID,
+			RelationKind: RelationKindSubtask,
+		}
+		err = relation.Create(s, doer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return task, nil
+}
+
+func (td *TaskDuplicate) duplicateTaskDetails(s *xorm.Session, oldTaskID int64, task *Task, project *Project, doer web.Auth) (err error) {
+	if td.Labels {
+		labelTasks := []*LabelTask{}
+		err = s.Where("task_id = ?", oldTaskID).Find(&labelTasks)
+		if err != nil {
+			return
+		}
+		for _, lt := range labelTasks {
+			lt.ID = 0
+			lt.TaskID = task.ID
+			if _, err := s.Insert(lt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if td.Assignees {
+		// Only copy those assignees who have access to the target project
+		assignees := []*TaskAssginee{}
+		err = s.Where("task_id = ?", oldTaskID).Find(&assignees)
+		if err != nil {
+			return
+		}
+		for _, a := range assignees {
+			if err := task.addNewAssigneeByID(s, a.UserID, project, doer); err != nil {
+				if IsErrUserDoesNotHaveAccessToProject(err) {
+					continue
+				}
+				return err
+			}
+		}
+	}
+
+	if td.Attachments {
+		attachments, err := getTaskAttachmentsByTaskIDs(s, []int64{oldTaskID})
+		if err != nil {
+			return err
+		}
+		for _, attachment := range attachments {
+			if err := duplicateTaskAttachment(s, attachment, task.ID, doer); err != nil {
+				return err
+			}
+		}
+	}
+
+	if td.Comments {
+		comments := []*TaskComment{}
+		err = s.Where("task_id = ?", oldTaskID).OrderBy("created asc").Find(&comments)
+		if err != nil {
+			return
+		}
+		for _, c := range comments {
+			c.ID = 0
+			c.TaskID = task.ID
+			if _, err := s.Insert(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Only keep those checklist assignees who have access to the target project
+	checklistItems, err := getChecklistItemsForTasks(s, []int64{oldTaskID})
+	if err != nil {
+		return
+	}
+	for _, item := range checklistItems {
+		item.ID = 0
+		item.TaskID = task.ID
+		err = checkChecklistItemAssignee(s, task, item.AssigneeID)
+		if IsErrUserDoesNotHaveAccessToProject(err) || user.IsErrUserDoesNotExist(err) {
+			item.AssigneeID = 0
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := s.Insert(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// duplicateTaskAttachment copies an attachment including its file to another task.
+// We duplicate the underlying file since it could be modified in one task which would result in file changes in the
+// other task which is not something we want. Attachments whose file does not exist anymore are skipped.
+func duplicateTaskAttachment(s *xorm.Session, attachment *TaskAttachment, newTaskID int64, doer web.Auth) error {
+	oldAttachmentID := attachment.ID
+	attachment.ID = 0
+	attachment.TaskID = newTaskID
+	attachment.File = &files.File{ID: attachment.FileID}
+	if err := attachment.File.LoadFileMetaByID(); err != nil {
+		if files.IsErrFileDoesNotExist(err) {
+			log.Debugf("Not duplicating attachment %d (file %d) because it does not exist", oldAttachmentID, attachment.FileID)
+			return nil
+		}
+		return err
+	}
+	if err := attachment.File.LoadFileByID(); err != nil {
+		return err
+	}
+
+	err := attachment.NewAttachment(s, attachment.File.File, attachment.File.Name, attachment.File.Size, doer)
+	if err != nil {
+		return err
+	}
+
+	if attachment.File.File != nil {
+		_ = attachment.File.File.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskDuplicate(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("with everything", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{
+			TaskID:      1,
+			Labels:      true,
+			Assignees:   true,
+			Reminders:   true,
+			Attachments: true,
+			Comments:    true,
+		}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = td.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.NotEqual(t, int64(1), td.Task.ID)
+		assert.Equal(t, int64(1), td.Task.ProjectID)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":          td.Task.ID,
+			"title":       "task #1",
+			"description": "Lorem Ipsum",
+			"project_id":  1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  td.Task.ID,
+			"label_id": 4,
+		}, false)
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"task_id": td.Task.ID,
+			"comment": "Lorem Ipsum Dolor Sit Amet",
+		}, false)
+		db.AssertExists(t, "task_attachments", map[string]interface{}{
+			"task_id": td.Task.ID,
+		}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       td.Task.ID,
+			"other_task_id": 1,
+			"relation_kind": RelationKindCopiedFrom,
+		}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       1,
+			"other_task_id": td.Task.ID,
+			"relation_kind": RelationKindCopiedTo,
+		}, false)
+	})
+	t.Run("only the task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{TaskID: 1}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = td.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{
+			"task_id": td.Task.ID,
+		})
+		db.AssertMissing(t, "task_comments", map[string]interface{}{
+			"task_id": td.Task.ID,
+		})
+	})
+	t.Run("reminders", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{TaskID: 2, Reminders: true}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = td.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_reminders", map[string]interface{}{
+			"task_id": td.Task.ID,
+		}, false)
+	})
+	t.Run("subtasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{TaskID: 29, Subtasks: true}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = td.Create(s, u)
+		assert.NoError(t, err)
+
+		subtaskIDs, err := getSubtaskIDs(s, td.Task.ID)
+		assert.NoError(t, err)
+		assert.Len(t, subtaskIDs, 1)
+		assert.NotEqual(t, int64(1), subtaskIDs[0])
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       subtaskIDs[0],
+			"other_task_id": 1,
+			"relation_kind": RelationKindCopiedFrom,
+		}, false)
+	})
+	t.Run("subtasks without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// User 1 does not have access to task 13
+		_, err := s.Insert(&TaskRelation{
+			TaskID:       29,
+			OtherTaskID:  13,
+			RelationKind: RelationKindParenttask,
+			CreatedByID:  1,
+		})
+		assert.NoError(t, err)
+
+		td := &TaskDuplicate{TaskID: 29, Subtasks: true}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = td.Create(s, u)
+		assert.NoError(t, err)
+
+		subtaskIDs, err := getSubtaskIDs(s, td.Task.ID)
+		assert.NoError(t, err)
+		assert.Len(t, subtaskIDs, 1)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       subtaskIDs[0],
+			"other_task_id": 1,
+			"relation_kind": RelationKindCopiedFrom,
+		}, false)
+		db.AssertMissing(t, "task_relations", map[string]interface{}{
+			"other_task_id": 13,
+			"relation_kind": RelationKindCopiedFrom,
+		})
+	})
+	t.Run("into another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{TaskID: 1, ProjectID: 11}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = td.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(11), td.Task.ProjectID)
+		bucket, err := getBucketByID(s, td.Task.BucketID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), bucket.ProjectID)
+		// Task #20 already has index 1 in that project
+		assert.Equal(t, int64(2), td.Task.Index)
+	})
+	t.Run("into a read only project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{TaskID: 1, ProjectID: 9}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("no access to the task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		td := &TaskDuplicate{TaskID: 13}
+		can, err := td.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
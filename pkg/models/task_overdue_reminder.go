@@ -80,15 +80,12 @@ func getUndoneOverdueTasks(s *xorm.Session, now time.Time) (usersWithTasks map[i
 		}
 
 		// If it is time for that current user, add the task to their project of overdue tasks
-		tm, err := time.Parse("15:04", t.User.OverdueTasksRemindersTime)
+		overdueMailTime, isTimeForReminder, err := getOverdueMailTime(t.User, now, tz)
 		if err != nil {
 			return nil, err
 		}
-		overdueMailTime := time.Date(now.Year(), now.Month(), now.Day(), tm.Hour(), tm.Minute(), 0, 0, tz)
-		isTimeForReminder := overdueMailTime.After(now) || overdueMailTime.Equal(now.In(tz))
-		wasTimeForReminder := overdueMailTime.Before(nextMinute)
 		taskIsOverdueInUserTimezone := overdueMailTime.After(t.Task.DueDate.In(tz))
-		if isTimeForReminder && wasTimeForReminder && taskIsOverdueInUserTimezone {
+		if isTimeForReminder && taskIsOverdueInUserTimezone {
 			_, exists := uts[t.User.ID]
 			if !exists {
 				uts[t.User.ID] = &userWithTasks{
@@ -103,6 +100,35 @@ func getUndoneOverdueTasks(s *xorm.Session, now time.Time) (usersWithTasks map[i
 	return uts, nil
 }
 
+// getOverdueMailTime returns when the overdue tasks mail of a user is sent and whether that is in the current
+// minute. If the configured time is during the quiet hours of the user, the mail is sent when they end, which
+// may be on the next day.
+func getOverdueMailTime(u *user.User, now time.Time, tz *time.Location) (overdueMailTime time.Time, isTimeForReminder bool, err error) {
+	tm, err := time.Parse("15:04", u.OverdueTasksRemindersTime)
+	if err != nil {
+		return
+	}
+
+	nextMinute := now.Add(1 * time.Minute)
+	local := now.In(tz)
+
+	// Check yesterday as well because quiet hours may have moved yesterday's mail to today
+	for _, days := range []int{0, -1} {
+		overdueMailTime = time.Date(local.Year(), local.Month(), local.Day()+days, tm.Hour(), tm.Minute(), 0, 0, tz)
+		overdueMailTime, _, err = u.GetQuietHoursEnd(overdueMailTime)
+		if err != nil {
+			return
+		}
+
+		isTimeForReminder = (overdueMailTime.After(now) || overdueMailTime.Equal(now)) && overdueMailTime.Before(nextMinute)
+		if isTimeForReminder {
+			return
+		}
+	}
+
+	return
+}
+
 type userWithTasks struct {
 	user  *user.User
 	tasks map[int64]*Task
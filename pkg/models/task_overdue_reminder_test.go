@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,3 +74,41 @@ func TestGetUndoneOverDueTasks(t *testing.T) {
 		assert.Len(t, tasks, 0)
 	})
 }
+
+func TestGetOverdueMailTime(t *testing.T) {
+	tz, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	t.Run("no quiet hours", func(t *testing.T) {
+		u := &user.User{OverdueTasksRemindersTime: "09:00", Timezone: "Europe/Berlin"}
+		now := time.Date(2023, 10, 6, 9, 0, 0, 0, tz)
+		mailTime, isTime, err := getOverdueMailTime(u, now, tz)
+		assert.NoError(t, err)
+		assert.True(t, isTime)
+		assert.Equal(t, now, mailTime)
+	})
+	t.Run("deferred until the quiet hours end", func(t *testing.T) {
+		u := &user.User{OverdueTasksRemindersTime: "09:00", Timezone: "Europe/Berlin", QuietHoursStart: "08:00", QuietHoursEnd: "10:30"}
+		_, isTime, err := getOverdueMailTime(u, time.Date(2023, 10, 6, 9, 0, 0, 0, tz), tz)
+		assert.NoError(t, err)
+		assert.False(t, isTime)
+
+		now := time.Date(2023, 10, 6, 10, 30, 0, 0, tz)
+		mailTime, isTime, err := getOverdueMailTime(u, now, tz)
+		assert.NoError(t, err)
+		assert.True(t, isTime)
+		assert.Equal(t, now, mailTime)
+	})
+	t.Run("deferred to the next day", func(t *testing.T) {
+		u := &user.User{OverdueTasksRemindersTime: "23:00", Timezone: "Europe/Berlin", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+		_, isTime, err := getOverdueMailTime(u, time.Date(2023, 10, 6, 23, 0, 0, 0, tz), tz)
+		assert.NoError(t, err)
+		assert.False(t, isTime)
+
+		now := time.Date(2023, 10, 7, 7, 0, 0, 0, tz)
+		mailTime, isTime, err := getOverdueMailTime(u, now, tz)
+		assert.NoError(t, err)
+		assert.True(t, isTime)
+		assert.Equal(t, now, mailTime)
+	})
+}
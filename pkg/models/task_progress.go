@@ -0,0 +1,180 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+)
+
+// getSubtaskIDs returns the ids of all direct subtasks of a task. Subtasks in the trash are not included.
+func getSubtaskIDs(s *xorm.Session, taskID int64) (subtaskIDs []int64, err error) {
+	subtaskIDs = []int64{}
+	err = s.
+		Table("task_relations").
+		Join("INNER", "tasks", "tasks.id = task_relations.other_task_id").
+		Where("task_relations.task_id = ? AND task_relations.relation_kind = ?", taskID, RelationKindParenttask).
+		Cols("task_relations.other_task_id").
+		Find(&subtaskIDs)
+	return
+}
+
+// getParentTaskIDs returns the ids of all tasks the task is a subtask of.
+func getParentTaskIDs(s *xorm.Session, taskID int64) (parentIDs []int64, err error) {
+	parentIDs = []int64{}
+	err = s.
+		Table("task_relations").
+		Where("task_id = ? AND relation_kind = ?", taskID, RelationKindSubtask).
+		Cols("other_task_id").
+		Find(&parentIDs)
+	return
+}
+
+// calculateProgressFromSubtasks returns the progress of a task from 0 to 1 as the average progress of its subtasks.
+// Done subtasks count as complete. Subtasks are weighted by their estimate, subtasks without an estimate count as 1.
+func calculateProgressFromSubtasks(subtasks []*Task) (progress float64, allDone bool) {
+	if len(subtasks) == 0 {
+		return 0, false
+	}
+
+	allDone = true
+	var total float64
+	for _, st := range subtasks {
+		weight := st.Estimate
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight
+
+		if st.Done {
+			progress += weight
+			continue
+		}
+		allDone = false
+		progress += weight * st.PercentDone
+	}
+
+	return progress / total, allDone
+}
+
+// updateProgressFromSubtasks recalculates the progress of a task from its subtasks and then does the same for
+// all of its parent tasks, up to the top of the hierarchy. Only tasks in projects which enabled deriving the
+// progress or done state from subtasks are changed.
+func updateProgressFromSubtasks(s *xorm.Session, taskIDs ...int64) error {
+	visited := make(map[int64]bool)
+	for _, id := range taskIDs {
+		err := updateTaskProgress(s, id, visited)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateTaskProgress(s *xorm.Session, taskID int64, visited map[int64]bool) error {
+	if visited[taskID] {
+		return nil
+	}
+	visited[taskID] = true
+
+	task, err := GetTaskByIDSimple(s, taskID)
+	if err != nil {
+		if IsErrTaskDoesNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	subtaskIDs, err := getSubtaskIDs(s, taskID)
+	if err != nil {
+		return err
+	}
+
+	project, err := GetProjectSimpleByID(s, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	cols := []string{}
+	switch {
+	case len(subtaskIDs) > 0 && (project.ProgressFromSubtasks || project.DoneFromSubtasks):
+		subtasks := []*Task{}
+		err = s.In("id", subtaskIDs).Find(&subtasks)
+		if err != nil {
+			return err
+		}
+
+		progress, allDone := calculateProgressFromSubtasks(subtasks)
+		if project.ProgressFromSubtasks {
+			task.PercentDone = progress
+			task.ComputedProgress = &progress
+			cols = append(cols, "percent_done", "computed_progress")
+		}
+
+		if project.DoneFromSubtasks && task.Done != allDone {
+			cols = append(cols, "done", "done_at", "bucket_id")
+			err = setDoneFromSubtasks(s, &task, project, allDone)
+			if err != nil {
+				return err
+			}
+		}
+	case task.ComputedProgress != nil:
+		// The task lost its last subtask or the project does not derive the progress anymore
+		task.ComputedProgress = nil
+		cols = append(cols, "computed_progress")
+	}
+
+	if len(cols) > 0 {
+		_, err = s.ID(task.ID).Cols(cols...).Update(&task)
+		if err != nil {
+			return err
+		}
+	}
+
+	parentIDs, err := getParentTaskIDs(s, taskID)
+	if err != nil {
+		return err
+	}
+	for _, parentID := range parentIDs {
+		err = updateTaskProgress(s, parentID, visited)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setDoneFromSubtasks marks a task as done or undone because of its subtasks and moves it in or out of the done
+// bucket of its project.
+func setDoneFromSubtasks(s *xorm.Session, task *Task, project *Project, done bool) (err error) {
+	task.Done = done
+	if !done {
+		task.DoneAt = time.Time{}
+		if project.DoneBucketID != 0 && task.BucketID == project.DoneBucketID {
+			task.BucketID, err = getDefaultBucketID(s, project)
+		}
+		return
+	}
+
+	task.DoneAt = time.Now()
+	if project.DoneBucketID != 0 {
+		task.BucketID = project.DoneBucketID
+	}
+	return
+}
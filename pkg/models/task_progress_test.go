@@ -0,0 +1,134 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateProgressFromSubtasks(t *testing.T) {
+	t.Run("no subtasks", func(t *testing.T) {
+		progress, allDone := calculateProgressFromSubtasks([]*Task{})
+		assert.Equal(t, float64(0), progress)
+		assert.False(t, allDone)
+	})
+	t.Run("without estimates", func(t *testing.T) {
+		progress, allDone := calculateProgressFromSubtasks([]*Task{
+			{Done: true},
+			{PercentDone: 0.5},
+		})
+		assert.Equal(t, 0.75, progress)
+		assert.False(t, allDone)
+	})
+	t.Run("weighted by estimate", func(t *testing.T) {
+		progress, allDone := calculateProgressFromSubtasks([]*Task{
+			{Done: true},
+			{PercentDone: 0.5, Estimate: 3},
+		})
+		assert.Equal(t, 0.625, progress)
+		assert.False(t, allDone)
+	})
+	t.Run("all done", func(t *testing.T) {
+		progress, allDone := calculateProgressFromSubtasks([]*Task{
+			{Done: true, Estimate: 2},
+			{Done: true},
+		})
+		assert.Equal(t, float64(1), progress)
+		assert.True(t, allDone)
+	})
+}
+
+func TestUpdateProgressFromSubtasks(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("progress_from_subtasks", "done_from_subtasks").Update(&Project{
+			ProgressFromSubtasks: true,
+			DoneFromSubtasks:     true,
+		})
+		assert.NoError(t, err)
+
+		// Task 1 already is a subtask of task 29
+		rel := &TaskRelation{TaskID: 3, OtherTaskID: 29, RelationKind: RelationKindSubtask}
+		err = rel.Create(s, u)
+		assert.NoError(t, err)
+
+		parent, err := GetTaskByIDSimple(s, 29)
+		assert.NoError(t, err)
+		assert.NotNil(t, parent.ComputedProgress)
+		assert.Equal(t, float64(0), parent.PercentDone)
+
+		task := &Task{ID: 1, Title: "task #1", ProjectID: 1, Done: true}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		parent, err = GetTaskByIDSimple(s, 29)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.5, parent.PercentDone)
+		assert.Equal(t, 0.5, *parent.ComputedProgress)
+
+		task = &Task{ID: 3, Title: "task #3", ProjectID: 1, PercentDone: 0.5, Estimate: 3}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		parent, err = GetTaskByIDSimple(s, 29)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.625, parent.PercentDone)
+		assert.False(t, parent.Done)
+
+		task = &Task{ID: 3, Title: "task #3", ProjectID: 1, Done: true, Estimate: 3}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		parent, err = GetTaskByIDSimple(s, 29)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), parent.PercentDone)
+		assert.True(t, parent.Done)
+		assert.Equal(t, int64(3), parent.BucketID)
+
+		err = rel.Delete(s, u)
+		assert.NoError(t, err)
+		task = &Task{ID: 1, Title: "task #1", ProjectID: 1}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		parent, err = GetTaskByIDSimple(s, 29)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(0), parent.PercentDone)
+		assert.False(t, parent.Done)
+	})
+	t.Run("not enabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, Title: "task #1", ProjectID: 1, Done: true}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+
+		parent, err := GetTaskByIDSimple(s, 29)
+		assert.NoError(t, err)
+		assert.Nil(t, parent.ComputedProgress)
+		assert.Equal(t, float64(0), parent.PercentDone)
+		assert.False(t, parent.Done)
+	})
+}
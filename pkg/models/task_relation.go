@@ -138,6 +138,87 @@ func getInverseRelation(kind RelationKind) RelationKind {
 	return RelationKindUnknown
 }
 
+// isHierarchical returns true if relations of this kind form a chain in which a task must not appear twice.
+func (rk RelationKind) isHierarchical() bool {
+	return rk == RelationKindSubtask ||
+		rk == RelationKindParenttask ||
+		rk == RelationKindBlocking ||
+		rk == RelationKindBlocked ||
+		rk == RelationKindPreceeds ||
+		rk == RelationKindFollows
+}
+
+// createsCycle checks if the other task of the relation already reaches its base task through relations of the
+// same kind. Because every relation is stored in both directions, following the same kind is enough for all
+// hierarchical kinds.
+func (rel *TaskRelation) createsCycle(s *xorm.Session) (bool, error) {
+	if !rel.RelationKind.isHierarchical() {
+		return false, nil
+	}
+
+	visited := map[int64]bool{rel.OtherTaskID: true}
+	current := []int64{rel.OtherTaskID}
+	for len(current) > 0 {
+		relations := []*TaskRelation{}
+		err := s.
+			In("task_id", current).
+			And("relation_kind = ?", rel.RelationKind).
+			Find(&relations)
+		if err != nil {
+			return false, err
+		}
+
+		next := []int64{}
+		for _, r := range relations {
+			if r.OtherTaskID == rel.TaskID {
+				return true, nil
+			}
+			if visited[r.OtherTaskID] {
+				continue
+			}
+			visited[r.OtherTaskID] = true
+			next = append(next, r.OtherTaskID)
+		}
+		current = next
+	}
+
+	return false, nil
+}
+
+// getOpenBlockerIDs returns the ids of all tasks which block the task and are not done yet.
+func getOpenBlockerIDs(s *xorm.Session, taskID int64) (blockerIDs []int64, err error) {
+	blockerIDs = []int64{}
+	err = s.
+		Table("task_relations").
+		Join("INNER", "tasks", "tasks.id = task_relations.other_task_id").
+		Where("task_relations.task_id = ? AND task_relations.relation_kind = ? AND tasks.done = ?", taskID, RelationKindBlocked, false).
+		OrderBy("tasks.id asc").
+		Cols("tasks.id").
+		Find(&blockerIDs)
+	return
+}
+
+// checkTaskIsNotBlocked returns an error if the project of the task requires blocking tasks to be done first and the
+// task still has open blockers.
+func checkTaskIsNotBlocked(s *xorm.Session, taskID int64, project *Project) error {
+	if !project.EnforceBlockingTasks {
+		return nil
+	}
+
+	blockerIDs, err := getOpenBlockerIDs(s, taskID)
+	if err != nil {
+		return err
+	}
+	if len(blockerIDs) > 0 {
+		return ErrTaskIsBlocked{
+			TaskID:     taskID,
+			BlockerIDs: blockerIDs,
+		}
+	}
+
+	return nil
+}
+
 // Create creates a new task relation
 // @Summary Create a new relation between two tasks
 // @Description Creates a new relation between two tasks. The user needs to have update rights on the base task and at least read rights on the other task. Both tasks do not need to be on the same project. Take a look at the docs for available task relation kinds.
@@ -148,7 +229,7 @@ func getInverseRelation(kind RelationKind) RelationKind {
 // @Param relation body models.TaskRelation true "The relation object"
 // @Param taskID path int true "Task ID"
 // @Success 201 {object} models.TaskRelation "The created task relation object."
-// @Failure 400 {object} web.HTTPError "Invalid task relation object provided."
+// @Failure 400 {object} web.HTTPError "Invalid task relation object provided or the relation would create a cycle."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/{taskID}/relations [put]
 func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
@@ -177,6 +258,18 @@ func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
 		}
 	}
 
+	createsCycle, err := rel.createsCycle(s)
+	if err != nil {
+		return err
+	}
+	if createsCycle {
+		return ErrRelationCreatesCycle{
+			TaskID:      rel.TaskID,
+			OtherTaskID: rel.OtherTaskID,
+			Kind:        rel.RelationKind,
+		}
+	}
+
 	rel.CreatedBy, err = GetUserOrLinkShareUser(s, a)
 	if err != nil {
 		return err
@@ -200,6 +293,13 @@ func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
+	if rel.RelationKind == RelationKindSubtask || rel.RelationKind == RelationKindParenttask {
+		err = updateProgressFromSubtasks(s, rel.TaskID, rel.OtherTaskID)
+		if err != nil {
+			return err
+		}
+	}
+
 	doer, _ := user.GetFromAuth(a)
 	return events.Dispatch(&TaskRelationCreatedEvent{
 		Task:     &Task{ID: rel.TaskID},
@@ -260,6 +360,13 @@ func (rel *TaskRelation) Delete(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
+	if rel.RelationKind == RelationKindSubtask || rel.RelationKind == RelationKindParenttask {
+		err = updateProgressFromSubtasks(s, rel.TaskID, rel.OtherTaskID)
+		if err != nil {
+			return err
+		}
+	}
+
 	doer, _ := user.GetFromAuth(a)
 	return events.Dispatch(&TaskRelationDeletedEvent{
 		Task:     &Task{ID: rel.TaskID},
@@ -94,6 +94,56 @@ func TestTaskRelation_Create(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, IsErrRelationTasksCannotBeTheSame(err))
 	})
+	t.Run("Cycle", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		rel := TaskRelation{
+			TaskID:       29,
+			OtherTaskID:  1,
+			RelationKind: RelationKindSubtask,
+		}
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRelationCreatesCycle(err))
+	})
+	t.Run("Indirect Cycle", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		rel := TaskRelation{
+			TaskID:       29,
+			OtherTaskID:  3,
+			RelationKind: RelationKindSubtask,
+		}
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+
+		rel = TaskRelation{
+			TaskID:       3,
+			OtherTaskID:  1,
+			RelationKind: RelationKindSubtask,
+		}
+		err = rel.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRelationCreatesCycle(err))
+	})
+	t.Run("Cycle of the inverse kind", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		rel := TaskRelation{
+			TaskID:       1,
+			OtherTaskID:  29,
+			RelationKind: RelationKindParenttask,
+		}
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRelationCreatesCycle(err))
+	})
 }
 
 func TestTaskRelation_Delete(t *testing.T) {
@@ -25,6 +25,7 @@ import (
 	"code.vikunja.io/api/pkg/notifications"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/web"
 	"xorm.io/xorm"
 
 	"code.vikunja.io/api/pkg/config"
@@ -63,6 +64,61 @@ func (TaskReminder) TableName() string {
 	return "task_reminders"
 }
 
+// addDefaultReminders adds the default reminders of the project to a task for every date the task did not have
+// before. If the project has no default reminders, those of the user are used instead.
+func addDefaultReminders(s *xorm.Session, oldTask *Task, task *Task, project *Project, a web.Auth) error {
+	if task.DisableDefaultReminders {
+		return nil
+	}
+
+	newDates := map[ReminderRelation]bool{
+		ReminderRelationDueDate:   oldTask.DueDate.IsZero() && !task.DueDate.IsZero(),
+		ReminderRelationStartDate: oldTask.StartDate.IsZero() && !task.StartDate.IsZero(),
+		ReminderRelationEndDate:   oldTask.EndDate.IsZero() && !task.EndDate.IsZero(),
+	}
+	if !newDates[ReminderRelationDueDate] && !newDates[ReminderRelationStartDate] && !newDates[ReminderRelationEndDate] {
+		return nil
+	}
+
+	defaults := project.DefaultReminders
+	if len(defaults) == 0 {
+		if _, is := a.(*LinkSharing); is {
+			return nil
+		}
+
+		u, err := user.GetUserByID(s, a.GetID())
+		if err != nil {
+			return err
+		}
+		defaults = u.DefaultReminders
+	}
+
+	for _, d := range defaults {
+		relativeTo := ReminderRelation(d.RelativeTo)
+		if !newDates[relativeTo] {
+			continue
+		}
+
+		exists := false
+		for _, r := range task.Reminders {
+			if r.RelativeTo == relativeTo && r.RelativePeriod == d.RelativePeriod {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		task.Reminders = append(task.Reminders, &TaskReminder{
+			RelativeTo:     relativeTo,
+			RelativePeriod: d.RelativePeriod,
+		})
+	}
+
+	return nil
+}
+
 type taskUser struct {
 	Task *Task      `xorm:"extends"`
 	User *user.User `xorm:"extends"`
@@ -245,6 +301,13 @@ func RegisterReminderCron() {
 			return
 		}
 
+		deferred, err := getDueDeferredReminders(s, now)
+		if err != nil {
+			log.Errorf("[Task Reminder Cron] Could not get snoozed reminders: %s", err)
+			return
+		}
+		reminders = append(reminders, deferred...)
+
 		if len(reminders) == 0 {
 			return
 		}
@@ -252,6 +315,15 @@ func RegisterReminderCron() {
 		log.Debugf("[Task Reminder Cron] Sending %d reminders", len(reminders))
 
 		for _, n := range reminders {
+			isDeferred, err := deferReminderIfQuiet(s, n, now)
+			if err != nil {
+				log.Errorf("[Task Reminder Cron] Could not check quiet hours of user %d: %s", n.User.ID, err)
+				return
+			}
+			if isDeferred {
+				continue
+			}
+
 			err = notifications.Notify(n.User, n)
 			if err != nil {
 				log.Errorf("[Task Reminder Cron] Could not notify user %d: %s", n.User.ID, err)
@@ -0,0 +1,423 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// TaskScheduleChange holds the new dates of a task which was shifted because a task it follows was moved.
+type TaskScheduleChange struct {
+	// The id of the shifted task.
+	TaskID int64 `json:"task_id"`
+	// The new start date of the task.
+	StartDate time.Time `json:"start_date"`
+	// The new end date of the task.
+	EndDate time.Time `json:"end_date"`
+	// The new due date of the task.
+	DueDate time.Time `json:"due_date"`
+}
+
+// TaskSchedulePreview shows which tasks would be shifted if a task got new dates, without changing anything.
+type TaskSchedulePreview struct {
+	// The task which gets new dates.
+	TaskID int64 `json:"-" param:"projecttask"`
+	// The new start date of the task.
+	StartDate time.Time `json:"start_date"`
+	// The new end date of the task.
+	EndDate time.Time `json:"end_date"`
+	// The new due date of the task.
+	DueDate time.Time `json:"due_date"`
+
+	// All tasks which would be shifted, with their new dates.
+	Changes []*TaskScheduleChange `json:"changes"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanCreate checks if a user can preview the schedule of a task
+func (tsp *TaskSchedulePreview) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return (&Task{ID: tsp.TaskID}).CanUpdate(s, a)
+}
+
+// Create computes which tasks would be shifted
+// @Summary Preview shifting the following tasks
+// @Description Returns all tasks which would be moved if the task got the provided dates. Following tasks are all tasks connected through "precedes" relations. They keep their distance to the task, optionally skipping weekends as configured in the project of the task. Nothing is saved.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Task ID"
+// @Param preview body models.TaskSchedulePreview true "The new dates of the task."
+// @Success 201 {object} models.TaskSchedulePreview "The tasks which would be shifted."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the task."
+// @Failure 404 {object} web.HTTPError "The task does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{id}/schedule/preview [post]
+func (tsp *TaskSchedulePreview) Create(s *xorm.Session, a web.Auth) (err error) {
+	task, err := GetTaskByIDSimple(s, tsp.TaskID)
+	if err != nil {
+		return err
+	}
+
+	project, err := GetProjectSimpleByID(s, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	moved := &Task{
+		ID:        task.ID,
+		StartDate: tsp.StartDate,
+		EndDate:   tsp.EndDate,
+		DueDate:   tsp.DueDate,
+	}
+	tsp.Changes, err = shiftFollowingTasks(s, a, &task, moved, project.SchedulingSkipWeekends, true)
+	return err
+}
+
+// getScheduleReference returns the date of a task which following tasks depend on, before and after it was moved.
+// This is the end date, or the due date if the task has no end date, or the start date if it has neither.
+func getScheduleReference(oldTask, newTask *Task) (from, to time.Time, ok bool) {
+	for _, dates := range [][2]time.Time{
+		{oldTask.EndDate, newTask.EndDate},
+		{oldTask.DueDate, newTask.DueDate},
+		{oldTask.StartDate, newTask.StartDate},
+	} {
+		if !dates[0].IsZero() && !dates[1].IsZero() {
+			return dates[0], dates[1], true
+		}
+	}
+	return
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// countWorkingDays returns how many working days lie between two dates. The result is negative if to is before from.
+func countWorkingDays(from, to time.Time) (days int) {
+	tz := config.GetTimeZone()
+	from = from.In(tz)
+	to = to.In(tz)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, tz)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, tz)
+
+	step := 1
+	if end.Before(day) {
+		step = -1
+	}
+	for !day.Equal(end) {
+		day = day.AddDate(0, 0, step)
+		if !isWeekend(day) {
+			days += step
+		}
+	}
+	return
+}
+
+// addWorkingDays moves a date by a number of working days, skipping saturdays and sundays.
+func addWorkingDays(t time.Time, days int) time.Time {
+	t = t.In(config.GetTimeZone())
+	step := 1
+	if days < 0 {
+		step = -1
+		days = -days
+	}
+	for days > 0 {
+		t = t.AddDate(0, 0, step)
+		if !isWeekend(t) {
+			days--
+		}
+	}
+	return t
+}
+
+// getClock returns the time of the day of a date.
+func getClock(t time.Time) time.Duration {
+	t = t.In(config.GetTimeZone())
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// shiftFollowingTasks moves all tasks which follow a task through "precedes" relations by the same amount the task
+// was moved, keeping the gaps between them. If skipWeekends is true, tasks are moved by working days instead.
+// Tasks the user cannot edit are not moved, and neither are the tasks following them. If dryRun is true, nothing
+// is saved and only the changes are returned.
+func shiftFollowingTasks(s *xorm.Session, a web.Auth, oldTask, newTask *Task, skipWeekends bool, dryRun bool) (changes []*TaskScheduleChange, err error) {
+	changes = []*TaskScheduleChange{}
+
+	from, to, ok := getScheduleReference(oldTask, newTask)
+	if !ok || from.Equal(to) {
+		return
+	}
+
+	delta := to.Sub(from)
+	workingDays := countWorkingDays(from, to)
+	clockDelta := getClock(to) - getClock(from)
+	shift := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		if skipWeekends {
+			return addWorkingDays(t, workingDays).Add(clockDelta)
+		}
+		return t.Add(delta)
+	}
+
+	visited := map[int64]bool{newTask.ID: true}
+	current := []int64{newTask.ID}
+	for len(current) > 0 {
+		relations := []*TaskRelation{}
+		err = s.
+			In("task_id", current).
+			And("relation_kind = ?", RelationKindPreceeds).
+			OrderBy("other_task_id asc").
+			Find(&relations)
+		if err != nil {
+			return nil, err
+		}
+
+		next := []int64{}
+		for _, rel := range relations {
+			if visited[rel.OtherTaskID] {
+				continue
+			}
+			visited[rel.OtherTaskID] = true
+
+			follower, err := GetTaskByIDSimple(s, rel.OtherTaskID)
+			if err != nil {
+				if IsErrTaskDoesNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+
+			can, err := follower.CanUpdate(s, a)
+			if err != nil {
+				return nil, err
+			}
+			if !can {
+				continue
+			}
+
+			follower.StartDate = shift(follower.StartDate)
+			follower.EndDate = shift(follower.EndDate)
+			follower.DueDate = shift(follower.DueDate)
+			changes = append(changes, &TaskScheduleChange{
+				TaskID:    follower.ID,
+				StartDate: follower.StartDate,
+				EndDate:   follower.EndDate,
+				DueDate:   follower.DueDate,
+			})
+			next = append(next, follower.ID)
+
+			if dryRun {
+				continue
+			}
+
+			err = saveShiftedTask(s, a, &follower)
+			if err != nil {
+				return nil, err
+			}
+		}
+		current = next
+	}
+
+	return changes, nil
+}
+
+// saveShiftedTask saves the dates of a task which was moved by the scheduling and updates its relative reminders.
+func saveShiftedTask(s *xorm.Session, a web.Auth, task *Task) (err error) {
+	_, err = s.
+		ID(task.ID).
+		Cols("start_date", "end_date", "due_date").
+		Update(task)
+	if err != nil {
+		return err
+	}
+
+	task.Reminders, err = getRemindersForTasks(s, []int64{task.ID})
+	if err != nil {
+		return err
+	}
+	err = updateRelativeReminderDates(task)
+	if err != nil {
+		return err
+	}
+	for _, r := range task.Reminders {
+		if r.RelativeTo == "" {
+			continue
+		}
+		_, err = s.ID(r.ID).Cols("reminder").Update(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = updateProjectLastUpdated(s, &Project{ID: task.ProjectID})
+	if err != nil {
+		return err
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	return events.Dispatch(&TaskUpdatedEvent{
+		Task: task,
+		Doer: doer,
+	})
+}
+
+// ProjectCriticalPath is the longest chain of tasks in a project connected through "precedes" relations.
+type ProjectCriticalPath struct {
+	// The project the critical path belongs to.
+	ProjectID int64 `json:"-" param:"project"`
+	// The ids of the tasks on the critical path, in the order they follow each other.
+	TaskIDs []int64 `json:"task_ids"`
+	// The sum of the durations of all tasks on the critical path in seconds. The duration of a task is the time
+	// between its start and end date, or zero if it does not have both.
+	Duration int64 `json:"duration"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanRead checks if a user can see the critical path of a project
+func (pcp *ProjectCriticalPath) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	return (&Project{ID: pcp.ProjectID}).CanRead(s, a)
+}
+
+// ReadOne computes the critical path of a project
+// @Summary Get the critical path of a project
+// @Description Returns the longest chain of tasks connected through "precedes" relations in the project, weighted by the duration of the tasks. Gantt charts can use it to highlight the tasks which delay the whole project if they are late. Relations to tasks in other projects are ignored.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 200 {object} models.ProjectCriticalPath "The critical path."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/criticalpath [get]
+func (pcp *ProjectCriticalPath) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	tasks := []*Task{}
+	err = s.Where("project_id = ?", pcp.ProjectID).Find(&tasks)
+	if err != nil {
+		return err
+	}
+
+	durations := make(map[int64]int64, len(tasks))
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		taskIDs = append(taskIDs, t.ID)
+		if !t.StartDate.IsZero() && !t.EndDate.IsZero() && t.EndDate.After(t.StartDate) {
+			durations[t.ID] = int64(t.EndDate.Sub(t.StartDate).Seconds())
+		} else {
+			durations[t.ID] = 0
+		}
+	}
+
+	pcp.TaskIDs = []int64{}
+	pcp.Duration = 0
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	relations := []*TaskRelation{}
+	err = s.
+		In("task_id", taskIDs).
+		In("other_task_id", taskIDs).
+		And("relation_kind = ?", RelationKindPreceeds).
+		Find(&relations)
+	if err != nil {
+		return err
+	}
+
+	pcp.TaskIDs, pcp.Duration = getCriticalPath(taskIDs, durations, relations)
+	return nil
+}
+
+// getCriticalPath returns the chain of tasks with the longest total duration. If several chains take equally long,
+// the one with more tasks wins. Tasks which are part of a cycle are ignored.
+func getCriticalPath(taskIDs []int64, durations map[int64]int64, relations []*TaskRelation) (path []int64, duration int64) {
+	successors := make(map[int64][]int64)
+	predecessorCount := make(map[int64]int)
+	for _, rel := range relations {
+		successors[rel.TaskID] = append(successors[rel.TaskID], rel.OtherTaskID)
+		predecessorCount[rel.OtherTaskID]++
+	}
+
+	sort.Slice(taskIDs, func(i, j int) bool { return taskIDs[i] < taskIDs[j] })
+
+	// Longest path through a topological order of the tasks
+	type distance struct {
+		duration int64
+		tasks    int
+	}
+	longer := func(a, b distance) bool {
+		return a.duration > b.duration || (a.duration == b.duration && a.tasks > b.tasks)
+	}
+
+	dist := make(map[int64]distance, len(taskIDs))
+	previous := make(map[int64]int64)
+	queue := []int64{}
+	for _, id := range taskIDs {
+		if predecessorCount[id] == 0 {
+			queue = append(queue, id)
+			dist[id] = distance{duration: durations[id], tasks: 1}
+		}
+	}
+
+	var last int64
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if last == 0 || longer(dist[id], dist[last]) {
+			last = id
+		}
+
+		for _, next := range successors[id] {
+			candidate := distance{duration: dist[id].duration + durations[next], tasks: dist[id].tasks + 1}
+			if current, exists := dist[next]; !exists || longer(candidate, current) {
+				dist[next] = candidate
+				previous[next] = id
+			}
+
+			predecessorCount[next]--
+			if predecessorCount[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if last == 0 {
+		return []int64{}, 0
+	}
+
+	path = []int64{}
+	for id := last; id != 0; id = previous[id] {
+		path = append([]int64{id}, path...)
+	}
+	return path, dist[last].duration
+}
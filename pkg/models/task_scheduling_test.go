@@ -0,0 +1,181 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+func TestCountWorkingDays(t *testing.T) {
+	tz := config.GetTimeZone()
+	// 2023-10-06 is a friday
+	friday := time.Date(2023, 10, 6, 12, 0, 0, 0, tz)
+
+	assert.Equal(t, 0, countWorkingDays(friday, friday.Add(time.Hour)))
+	assert.Equal(t, 1, countWorkingDays(friday, friday.AddDate(0, 0, 3)))
+	assert.Equal(t, 0, countWorkingDays(friday, friday.AddDate(0, 0, 2)))
+	assert.Equal(t, 6, countWorkingDays(friday, friday.AddDate(0, 0, 10)))
+	assert.Equal(t, -1, countWorkingDays(friday.AddDate(0, 0, 3), friday))
+}
+
+func TestAddWorkingDays(t *testing.T) {
+	tz := config.GetTimeZone()
+	friday := time.Date(2023, 10, 6, 12, 0, 0, 0, tz)
+
+	assert.Equal(t, time.Date(2023, 10, 9, 12, 0, 0, 0, tz), addWorkingDays(friday, 1))
+	assert.Equal(t, time.Date(2023, 10, 16, 12, 0, 0, 0, tz), addWorkingDays(friday, 6))
+	assert.Equal(t, time.Date(2023, 10, 5, 12, 0, 0, 0, tz), addWorkingDays(friday, -1))
+	assert.Equal(t, friday, addWorkingDays(time.Date(2023, 10, 9, 12, 0, 0, 0, tz), -1))
+}
+
+func TestGetCriticalPath(t *testing.T) {
+	t.Run("longest duration", func(t *testing.T) {
+		durations := map[int64]int64{1: 10, 2: 100, 3: 10, 4: 5, 5: 0}
+		relations := []*TaskRelation{
+			{TaskID: 1, OtherTaskID: 2},
+			{TaskID: 1, OtherTaskID: 3},
+			{TaskID: 2, OtherTaskID: 4},
+			{TaskID: 3, OtherTaskID: 4},
+		}
+		path, duration := getCriticalPath([]int64{1, 2, 3, 4, 5}, durations, relations)
+		assert.Equal(t, []int64{1, 2, 4}, path)
+		assert.Equal(t, int64(115), duration)
+	})
+	t.Run("more tasks without durations", func(t *testing.T) {
+		durations := map[int64]int64{1: 0, 2: 0, 3: 0}
+		relations := []*TaskRelation{
+			{TaskID: 1, OtherTaskID: 2},
+			{TaskID: 2, OtherTaskID: 3},
+		}
+		path, duration := getCriticalPath([]int64{3, 2, 1}, durations, relations)
+		assert.Equal(t, []int64{1, 2, 3}, path)
+		assert.Equal(t, int64(0), duration)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		durations := map[int64]int64{1: 10, 2: 10, 3: 1}
+		relations := []*TaskRelation{
+			{TaskID: 1, OtherTaskID: 2},
+			{TaskID: 2, OtherTaskID: 1},
+		}
+		path, duration := getCriticalPath([]int64{1, 2, 3}, durations, relations)
+		assert.Equal(t, []int64{3}, path)
+		assert.Equal(t, int64(1), duration)
+	})
+}
+
+func TestTaskSchedulePreview_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	tz := config.GetTimeZone()
+
+	setup := func(t *testing.T, s *xorm.Session) {
+		_, err := s.In("id", []int64{1, 3, 4}).Cols("start_date", "end_date").Update(&Task{
+			StartDate: time.Date(2023, 10, 2, 9, 0, 0, 0, tz),
+			EndDate:   time.Date(2023, 10, 6, 17, 0, 0, 0, tz),
+		})
+		assert.NoError(t, err)
+
+		for _, rel := range []*TaskRelation{
+			{TaskID: 1, OtherTaskID: 3, RelationKind: RelationKindPreceeds},
+			{TaskID: 3, OtherTaskID: 4, RelationKind: RelationKindPreceeds},
+		} {
+			err = rel.Create(s, u)
+			assert.NoError(t, err)
+		}
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setup(t, s)
+
+		preview := &TaskSchedulePreview{
+			TaskID:    1,
+			StartDate: time.Date(2023, 10, 2, 9, 0, 0, 0, tz),
+			EndDate:   time.Date(2023, 10, 8, 17, 0, 0, 0, tz),
+		}
+		err := preview.Create(s, u)
+		assert.NoError(t, err)
+		assert.Len(t, preview.Changes, 2)
+		assert.Equal(t, int64(3), preview.Changes[0].TaskID)
+		assert.Equal(t, time.Date(2023, 10, 4, 9, 0, 0, 0, tz), preview.Changes[0].StartDate.In(tz))
+		assert.Equal(t, time.Date(2023, 10, 8, 17, 0, 0, 0, tz), preview.Changes[0].EndDate.In(tz))
+		assert.Equal(t, int64(4), preview.Changes[1].TaskID)
+
+		// Nothing was saved
+		task, err := GetTaskByIDSimple(s, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 6, 17, 0, 0, 0, tz), task.EndDate.In(tz))
+	})
+	t.Run("skipping weekends", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setup(t, s)
+
+		_, err := s.ID(1).Cols("scheduling_skip_weekends").Update(&Project{SchedulingSkipWeekends: true})
+		assert.NoError(t, err)
+
+		preview := &TaskSchedulePreview{
+			TaskID:    1,
+			StartDate: time.Date(2023, 10, 2, 9, 0, 0, 0, tz),
+			EndDate:   time.Date(2023, 10, 9, 17, 0, 0, 0, tz),
+		}
+		err = preview.Create(s, u)
+		assert.NoError(t, err)
+		assert.Len(t, preview.Changes, 2)
+		assert.Equal(t, time.Date(2023, 10, 3, 9, 0, 0, 0, tz), preview.Changes[0].StartDate.In(tz))
+		assert.Equal(t, time.Date(2023, 10, 9, 17, 0, 0, 0, tz), preview.Changes[0].EndDate.In(tz))
+	})
+}
+
+func TestTask_Update_AutoScheduling(t *testing.T) {
+	u := &user.User{ID: 1}
+	tz := config.GetTimeZone()
+
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.ID(1).Cols("auto_scheduling").Update(&Project{AutoScheduling: true})
+	assert.NoError(t, err)
+	_, err = s.ID(3).Cols("due_date").Update(&Task{DueDate: time.Date(2023, 10, 10, 12, 0, 0, 0, tz)})
+	assert.NoError(t, err)
+	rel := &TaskRelation{TaskID: 1, OtherTaskID: 3, RelationKind: RelationKindPreceeds}
+	err = rel.Create(s, u)
+	assert.NoError(t, err)
+
+	task := &Task{ID: 1, Title: "test", ProjectID: 1, DueDate: time.Date(2023, 10, 5, 12, 0, 0, 0, tz)}
+	err = task.Update(s, u)
+	assert.NoError(t, err)
+
+	task = &Task{ID: 1, Title: "test", ProjectID: 1, DueDate: time.Date(2023, 10, 7, 12, 0, 0, 0, tz)}
+	err = task.Update(s, u)
+	assert.NoError(t, err)
+
+	follower, err := GetTaskByIDSimple(s, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 10, 12, 12, 0, 0, 0, tz), follower.DueDate.In(tz))
+}
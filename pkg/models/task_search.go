@@ -159,10 +159,15 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 
 	var projectIDCond builder.Cond
 	var favoritesCond builder.Cond
+	var sharedTasksCond builder.Cond
 	if len(opts.projectIDs) > 0 {
 		projectIDCond = builder.In("project_id", opts.projectIDs)
 	}
 
+	if len(opts.sharedTaskIDs) > 0 {
+		sharedTasksCond = builder.In("id", opts.sharedTaskIDs)
+	}
+
 	if d.hasFavoritesProject {
 		// All favorite tasks for that user
 		favCond := builder.
@@ -225,7 +230,7 @@ func (d *dbTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task, totalCo
 	}
 
 	limit, start := getLimitFromPageIndex(opts.page, opts.perPage)
-	cond := builder.And(builder.Or(projectIDCond, favoritesCond), where, filterCond)
+	cond := builder.And(builder.Or(projectIDCond, favoritesCond, sharedTasksCond), where, filterCond)
 
 	query := d.s.Where(cond)
 	if limit > 0 {
@@ -315,6 +320,14 @@ func (t *typesenseTaskSearcher) Search(opts *taskSearchOptions) (tasks []*Task,
 		"project_id: [" + strings.Join(projectIDStrings, ", ") + "]",
 	}
 
+	if len(opts.sharedTaskIDs) > 0 {
+		sharedTaskIDStrings := []string{}
+		for _, id := range opts.sharedTaskIDs {
+			sharedTaskIDStrings = append(sharedTaskIDStrings, strconv.FormatInt(id, 10))
+		}
+		filterBy[0] = "(" + filterBy[0] + " || id: [" + strings.Join(sharedTaskIDStrings, ", ") + "])"
+	}
+
 	for _, f := range opts.filters {
 
 		if f.field == "reminders" {
@@ -0,0 +1,337 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskTemplate holds a single task which can be used to create new tasks.
+type TaskTemplate struct {
+	// The unique, numeric id of this template.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"template"`
+	// The title of the template.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The task to create the template from. Only used when creating a template.
+	TaskID int64 `xorm:"-" json:"task_id"`
+	// The task saved in this template. All dates are saved as an offset in seconds relative to the start of the
+	// day of the earliest date of the task.
+	Task *TemplateTask `xorm:"json not null" json:"task"`
+
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The user who owns this template.
+	Owner *user.User `xorm:"-" json:"owner" valid:"-"`
+
+	// A timestamp when this template was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this template was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task templates
+func (*TaskTemplate) TableName() string {
+	return "task_templates"
+}
+
+func getTaskTemplateByID(s *xorm.Session, id int64) (tt *TaskTemplate, err error) {
+	tt = &TaskTemplate{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(tt)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTaskTemplateDoesNotExist{TemplateID: id}
+	}
+	return
+}
+
+// applyTaskTemplate fills all fields of a new task which were not provided with the values of the template.
+// All dates of the template are moved relative to the earliest date provided for the task or the current day if
+// it has none.
+// It returns the template so that its labels, assignees and checklist can be added once the task was created.
+func applyTaskTemplate(s *xorm.Session, t *Task, a web.Auth) (*TemplateTask, time.Time, error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, time.Time{}, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	template, err := getTaskTemplateByID(s, t.TemplateID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if template.OwnerID != a.GetID() {
+		return nil, time.Time{}, ErrTaskTemplateDoesNotExist{TemplateID: t.TemplateID}
+	}
+
+	start := getTemplateAnchor(nil)
+	if !t.StartDate.IsZero() {
+		start = getTemplateAnchor([]*Task{t})
+	}
+
+	fromTemplate := template.Task.toTask(t.ProjectID, start)
+	if t.Title == "" {
+		t.Title = fromTemplate.Title
+	}
+	if t.Description == "" {
+		t.Description = fromTemplate.Description
+	}
+	if t.Priority == 0 {
+		t.Priority = fromTemplate.Priority
+	}
+	if t.HexColor == "" {
+		t.HexColor = fromTemplate.HexColor
+	}
+	if t.Estimate == 0 {
+		t.Estimate = fromTemplate.Estimate
+	}
+	if t.RepeatAfter == 0 && t.RepeatMode == TaskRepeatModeDefault {
+		t.RepeatAfter = fromTemplate.RepeatAfter
+		t.RepeatMode = fromTemplate.RepeatMode
+	}
+	if t.DueDate.IsZero() {
+		t.DueDate = fromTemplate.DueDate
+	}
+	if t.StartDate.IsZero() {
+		t.StartDate = fromTemplate.StartDate
+	}
+	if t.EndDate.IsZero() {
+		t.EndDate = fromTemplate.EndDate
+	}
+	if len(t.Reminders) == 0 {
+		t.Reminders = fromTemplate.Reminders
+	}
+
+	return template.Task, start, nil
+}
+
+// Create saves a task as a template
+// @Summary Create a task template
+// @Description Saves a task with its labels, assignees, reminders and checklist as a template. Dates are saved relative to the earliest date of the task. The user needs read access to the task. Use the template by passing its id as `template_id` when creating a task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template body models.TaskTemplate true "The template with the id of the task to create it from."
+// @Success 201 {object} models.TaskTemplate "The created template."
+// @Failure 400 {object} web.HTTPError "Invalid template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/tasks [put]
+func (tt *TaskTemplate) Create(s *xorm.Session, a web.Auth) (err error) {
+	task, err := GetTaskByIDSimple(s, tt.TaskID)
+	if err != nil {
+		return err
+	}
+
+	taskMap := map[int64]*Task{task.ID: &task}
+	err = addMoreInfoToTasks(s, taskMap, a)
+	if err != nil {
+		return err
+	}
+
+	tt.ID = 0
+	tt.OwnerID = a.GetID()
+	tt.Task = newTemplateTask(&task, getTemplateAnchor([]*Task{&task}), task.ChecklistItems)
+	// Relations are only kept in project templates
+	tt.Task.Key = 0
+	tt.Task.BucketKey = 0
+	tt.Task.Position = 0
+	tt.Task.KanbanPosition = 0
+
+	_, err = s.Insert(tt)
+	if err != nil {
+		return err
+	}
+
+	tt.Owner, err = user.GetUserByID(s, tt.OwnerID)
+	return err
+}
+
+// ReadAll returns all task templates of the current user
+// @Summary Get all task templates
+// @Description Returns all task templates the current user created.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search templates by title."
+// @Success 200 {array} models.TaskTemplate "The templates"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/tasks [get]
+func (tt *TaskTemplate) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	templates := []*TaskTemplate{}
+
+	var where builder.Cond = builder.Eq{"owner_id": a.GetID()}
+	if search != "" {
+		where = builder.And(
+			where,
+			db.ILIKE("title", search),
+		)
+	}
+
+	err = s.
+		Where(where).
+		OrderBy("title asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&templates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalCount, err := s.Where(where).Count(&TaskTemplate{})
+	return templates, len(templates), totalCount, err
+}
+
+// ReadOne returns one task template
+// @Summary Get one task template
+// @Description Returns a task template by its id.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Success 200 {object} models.TaskTemplate "The template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/tasks/{template} [get]
+func (tt *TaskTemplate) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	// tt already contains the full template from the rights check, we only need to add the user
+	tt.Owner, err = user.GetUserByID(s, tt.OwnerID)
+	return err
+}
+
+// Update changes a task template
+// @Summary Update a task template
+// @Description Updates the title and the saved task of a task template.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Param template body models.TaskTemplate true "The template"
+// @Success 200 {object} models.TaskTemplate "The updated template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/tasks/{template} [post]
+func (tt *TaskTemplate) Update(s *xorm.Session, _ web.Auth) (err error) {
+	cols := []string{"title"}
+	if tt.Task != nil {
+		cols = append(cols, "task")
+	}
+
+	_, err = s.
+		Where("id = ?", tt.ID).
+		Cols(cols...).
+		Update(tt)
+	if err != nil {
+		return err
+	}
+
+	updated, err := getTaskTemplateByID(s, tt.ID)
+	if err != nil {
+		return err
+	}
+	*tt = *updated
+	return nil
+}
+
+// Delete removes a task template
+// @Summary Delete a task template
+// @Description Deletes a task template. Tasks created from it are not affected.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template path int true "Template ID"
+// @Success 200 {object} models.Message "The template was deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/tasks/{template} [delete]
+func (tt *TaskTemplate) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = s.
+		Where("id = ?", tt.ID).
+		Delete(&TaskTemplate{})
+	return err
+}
+
+// CanCreate checks if a user can create a template from a task
+func (tt *TaskTemplate) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	t := &Task{ID: tt.TaskID}
+	canRead, _, err := t.CanRead(s, a)
+	return canRead, err
+}
+
+// CanRead checks if a user can see a task template
+func (tt *TaskTemplate) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := tt.canDoTemplate(s, a)
+	return can, int(RightAdmin), err
+}
+
+// CanUpdate checks if a user can update a task template
+func (tt *TaskTemplate) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	// A normal check would replace the passed struct which in our case would override the values we want to update.
+	ttt := &TaskTemplate{ID: tt.ID}
+	return ttt.canDoTemplate(s, a)
+}
+
+// CanDelete checks if a user can delete a task template
+func (tt *TaskTemplate) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return tt.canDoTemplate(s, a)
+}
+
+// Only owners are allowed to do something with a template
+func (tt *TaskTemplate) canDoTemplate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrTemplateNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	ttt, err := getTaskTemplateByID(s, tt.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if ttt.OwnerID != a.GetID() {
+		return false, nil
+	}
+
+	*tt = *ttt
+	return true, nil
+}
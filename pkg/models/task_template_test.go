@@ -0,0 +1,108 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTemplate_Create(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	u := &user.User{ID: 1}
+
+	tt := &TaskTemplate{Title: "template", TaskID: 1}
+	can, err := tt.CanCreate(s, u)
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = tt.Create(s, u)
+	assert.NoError(t, err)
+	assert.Equal(t, "task #1", tt.Task.Title)
+	assert.Equal(t, "Lorem Ipsum", tt.Task.Description)
+	assert.Equal(t, []int64{4}, tt.Task.LabelIDs)
+	assert.Empty(t, tt.Task.Relations)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "task_templates", map[string]interface{}{
+		"id":       tt.ID,
+		"title":    "template",
+		"owner_id": 1,
+	}, false)
+}
+
+func TestTask_CreateFromTemplate(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTemplate{Title: "template", TaskID: 1}
+		err := tt.Create(s, u)
+		assert.NoError(t, err)
+
+		task := &Task{ProjectID: 1, TemplateID: tt.ID}
+		err = task.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, "task #1", task.Title)
+		assert.Equal(t, "Lorem Ipsum", task.Description)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  task.ID,
+			"label_id": 4,
+		}, false)
+	})
+	t.Run("provided fields win", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTemplate{Title: "template", TaskID: 1}
+		err := tt.Create(s, u)
+		assert.NoError(t, err)
+
+		task := &Task{Title: "own title", ProjectID: 1, TemplateID: tt.ID}
+		err = task.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, "own title", task.Title)
+		assert.Equal(t, "Lorem Ipsum", task.Description)
+	})
+	t.Run("template of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tt := &TaskTemplate{Title: "template", TaskID: 1}
+		err := tt.Create(s, u)
+		assert.NoError(t, err)
+
+		task := &Task{ProjectID: 3, TemplateID: tt.ID}
+		err = task.Create(s, &user.User{ID: 2})
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskTemplateDoesNotExist(err))
+	})
+}
@@ -0,0 +1,289 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// TaskUser represents a task <-> user relation. It gives a user access to a single task
+// without sharing the whole project with them.
+type TaskUser struct {
+	// The unique, numeric id of this task <-> user relation.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The username.
+	Username string `xorm:"-" json:"user_id" param:"user"`
+	// Used internally to reference the user
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The task id.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"-" param:"projecttask"`
+	// The right this user has. 0 = Read only, 1 = Read & Write, 3 = Read & Comment. Task shares cannot have admin rights.
+	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|3)" maximum:"3" default:"0"`
+
+	// A timestamp when this relation was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this relation was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName is the table name for TaskUser
+func (TaskUser) TableName() string {
+	return "task_users"
+}
+
+// validateRight checks the right of a task share. Task shares only support read, comment and write, managing a task's shares is reserved for project admins.
+func (tu *TaskUser) validateRight() error {
+	if tu.Right != RightRead && tu.Right != RightWrite && tu.Right != RightComment {
+		return ErrInvalidRight{tu.Right}
+	}
+	return nil
+}
+
+// Create creates a new task <-> user relation
+// @Summary Share a task with a user
+// @Description Gives a user access to a single task without giving them access to the project the task belongs to.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Task ID"
+// @Param task body models.TaskUser true "The user you want to share the task with."
+// @Success 201 {object} models.TaskUser "The created user<->task relation."
+// @Failure 400 {object} web.HTTPError "Invalid user task object provided."
+// @Failure 404 {object} web.HTTPError "The user does not exist."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the project of the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{id}/users [put]
+func (tu *TaskUser) Create(s *xorm.Session, _ web.Auth) (err error) {
+
+	if err := tu.validateRight(); err != nil {
+		return err
+	}
+
+	task, err := GetTaskByIDSimple(s, tu.TaskID)
+	if err != nil {
+		return err
+	}
+
+	u, err := user.GetUserByUsername(s, tu.Username)
+	if err != nil {
+		return err
+	}
+	tu.UserID = u.ID
+
+	// If the user already has access to the whole project there is no point in sharing a single task with them.
+	project := &Project{ID: task.ProjectID}
+	canRead, _, err := project.CanRead(s, u)
+	if err != nil {
+		return err
+	}
+	if canRead {
+		return ErrUserAlreadyHasAccessToTask{UserID: tu.UserID, TaskID: tu.TaskID}
+	}
+
+	exists, err := s.Where("task_id = ? AND user_id = ?", tu.TaskID, tu.UserID).Exist(&TaskUser{})
+	if err != nil {
+		return
+	}
+	if exists {
+		return ErrUserAlreadyHasAccessToTask{UserID: tu.UserID, TaskID: tu.TaskID}
+	}
+
+	_, err = s.Insert(tu)
+	if err != nil {
+		return err
+	}
+
+	return updateTaskLastUpdated(s, &task)
+}
+
+// Delete deletes a task <-> user relation
+// @Summary Remove a user from a task
+// @Description Removes the share of a task with a user. The user won't have access to the task anymore.
+// @tags sharing
+// @Produce json
+// @Security JWTKeyAuth
+// @Param taskID path int true "Task ID"
+// @Param userID path int true "User ID"
+// @Success 200 {object} models.Message "The user was successfully removed from the task."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the project of the task."
+// @Failure 404 {object} web.HTTPError "user or task does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/users/{userID} [delete]
+func (tu *TaskUser) Delete(s *xorm.Session, _ web.Auth) (err error) {
+
+	u, err := user.GetUserByUsername(s, tu.Username)
+	if err != nil {
+		return
+	}
+	tu.UserID = u.ID
+
+	has, err := s.
+		Where("user_id = ? AND task_id = ?", tu.UserID, tu.TaskID).
+		Exist(&TaskUser{})
+	if err != nil {
+		return
+	}
+	if !has {
+		return ErrUserDoesNotHaveAccessToTask{TaskID: tu.TaskID, UserID: tu.UserID}
+	}
+
+	_, err = s.
+		Where("user_id = ? AND task_id = ?", tu.UserID, tu.TaskID).
+		Delete(&TaskUser{})
+	if err != nil {
+		return err
+	}
+
+	return updateTaskLastUpdated(s, &Task{ID: tu.TaskID})
+}
+
+// ReadAll gets all users a task was shared with
+// @Summary Get users a task was shared with
+// @Description Returns all users which have access to a task through a direct task share. Users with access to the project are not included.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search users by its name."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.UserWithRight "The users with the right they have."
+// @Failure 403 {object} web.HTTPError "No right to see the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{id}/users [get]
+func (tu *TaskUser) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	t := &Task{ID: tu.TaskID}
+	canRead, _, err := t.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canRead {
+		return nil, 0, 0, ErrNoRightToSeeTask{TaskID: tu.TaskID, UserID: a.GetID()}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	all := []*UserWithRight{}
+	query := s.
+		Join("INNER", "task_users", "user_id = users.id").
+		Where("task_users.task_id = ?", tu.TaskID).
+		Where(db.ILIKE("users.username", search))
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&all)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// Obfuscate all user emails
+	for _, u := range all {
+		u.Email = ""
+	}
+
+	numberOfTotalItems, err = s.
+		Join("INNER", "task_users", "user_id = users.id").
+		Where("task_users.task_id = ?", tu.TaskID).
+		Where(db.ILIKE("users.username", search)).
+		Count(&UserWithRight{})
+
+	return all, len(all), numberOfTotalItems, err
+}
+
+// Update updates a user <-> task relation
+// @Summary Update a user <-> task relation
+// @Description Update a user <-> task relation. Mostly used to update the right that user has.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param taskID path int true "Task ID"
+// @Param userID path int true "User ID"
+// @Param task body models.TaskUser true "The user you want to update."
+// @Security JWTKeyAuth
+// @Success 200 {object} models.TaskUser "The updated user <-> task relation."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the project of the task."
+// @Failure 404 {object} web.HTTPError "User or task does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/users/{userID} [post]
+func (tu *TaskUser) Update(s *xorm.Session, _ web.Auth) (err error) {
+
+	if err := tu.validateRight(); err != nil {
+		return err
+	}
+
+	u, err := user.GetUserByUsername(s, tu.Username)
+	if err != nil {
+		return err
+	}
+	tu.UserID = u.ID
+
+	_, err = s.
+		Where("task_id = ? AND user_id = ?", tu.TaskID, tu.UserID).
+		Cols("right").
+		Update(tu)
+	if err != nil {
+		return err
+	}
+
+	return updateTaskLastUpdated(s, &Task{ID: tu.TaskID})
+}
+
+// Returns the ids of all tasks which were shared directly with a user.
+func getTaskIDsSharedWithUser(s *xorm.Session, userID int64) (taskIDs []int64, err error) {
+	taskIDs = []int64{}
+	err = s.
+		Table("task_users").
+		Where("user_id = ?", userID).
+		Cols("task_id").
+		Find(&taskIDs)
+	return
+}
+
+// Deletes all user and link shares of a task.
+func deleteTaskShares(s *xorm.Session, taskID int64) (err error) {
+	_, err = s.Where("task_id = ?", taskID).Delete(&TaskUser{})
+	if err != nil {
+		return
+	}
+
+	shareIDs := []int64{}
+	err = s.
+		Table("link_shares").
+		Where("task_id = ?", taskID).
+		Cols("id").
+		Find(&shareIDs)
+	if err != nil {
+		return
+	}
+
+	_, err = s.Where("task_id = ?", taskID).Delete(&LinkSharing{})
+	if err != nil {
+		return
+	}
+
+	return deleteLinkShareAccesses(s, shareIDs)
+}
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if the user can share a task with another user
+func (tu *TaskUser) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return tu.canDoTaskUser(s, a)
+}
+
+// CanDelete checks if the user can remove a task share
+func (tu *TaskUser) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return tu.canDoTaskUser(s, a)
+}
+
+// CanUpdate checks if the user can update a task share
+func (tu *TaskUser) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return tu.canDoTaskUser(s, a)
+}
+
+func (tu *TaskUser) canDoTaskUser(s *xorm.Session, a web.Auth) (bool, error) {
+	// Link shares aren't allowed to do anything
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	// Only admins of the task's project can manage its shares
+	t, err := GetTaskByIDSimple(s, tu.TaskID)
+	if err != nil {
+		return false, err
+	}
+
+	p := &Project{ID: t.ProjectID}
+	return p.IsAdmin(s, a)
+}
@@ -0,0 +1,285 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskUser_Create(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   2,
+			Username: "user14",
+			Right:    RightWrite,
+		}
+		err := tu.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_users", map[string]interface{}{
+			"task_id": 2,
+			"user_id": 14,
+			"right":   RightWrite,
+		}, false)
+	})
+	t.Run("admin right", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   2,
+			Username: "user14",
+			Right:    RightAdmin,
+		}
+		err := tu.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRight(err))
+	})
+	t.Run("user already has access to the project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   32,
+			Username: "user1",
+		}
+		err := tu.Create(s, &user.User{ID: 3})
+		assert.Error(t, err)
+		assert.True(t, IsErrUserAlreadyHasAccessToTask(err))
+	})
+	t.Run("already shared", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   1,
+			Username: "user14",
+		}
+		err := tu.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrUserAlreadyHasAccessToTask(err))
+	})
+	t.Run("nonexisting user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   2,
+			Username: "notexisting",
+		}
+		err := tu.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, user.IsErrUserDoesNotExist(err))
+	})
+}
+
+func TestTaskUser_Delete(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   32,
+			Username: "user14",
+		}
+		err := tu.Delete(s, &user.User{ID: 3})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "task_users", map[string]interface{}{
+			"task_id": 32,
+			"user_id": 14,
+		})
+	})
+	t.Run("not shared", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{
+			TaskID:   2,
+			Username: "user14",
+		}
+		err := tu.Delete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToTask(err))
+	})
+}
+
+func TestTaskUser_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tu := &TaskUser{TaskID: 1}
+	result, count, total, err := tu.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	assert.NoError(t, err)
+	users, is := result.([]*UserWithRight)
+	assert.True(t, is)
+	assert.Len(t, users, 1)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, int64(14), users[0].ID)
+	assert.Equal(t, RightWrite, users[0].Right)
+	assert.Empty(t, users[0].Email)
+}
+
+func TestTaskUser_CanDoSomething(t *testing.T) {
+	t.Run("project admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{TaskID: 1}
+		can, err := tu.CanCreate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("user with a task share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{TaskID: 1}
+		can, err := tu.CanCreate(s, &user.User{ID: 14})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tu := &TaskUser{TaskID: 1}
+		can, err := tu.CanCreate(s, &LinkSharing{ID: 3, ProjectID: 1, Right: RightAdmin})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestTask_RightsWithTaskShare(t *testing.T) {
+	u := &user.User{ID: 14}
+
+	t.Run("read shared read only", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 32}
+		can, maxRight, err := task.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		assert.Equal(t, int(RightRead), maxRight)
+
+		canWrite, err := task.CanWrite(s, u)
+		assert.NoError(t, err)
+		assert.False(t, canWrite)
+	})
+	t.Run("write shared with write access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1}
+		canWrite, err := task.CanWrite(s, u)
+		assert.NoError(t, err)
+		assert.True(t, canWrite)
+
+		comment := &TaskComment{TaskID: 1}
+		canComment, err := comment.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, canComment)
+
+		attachment := &TaskAttachment{TaskID: 1}
+		canAttach, err := attachment.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, canAttach)
+	})
+	t.Run("not shared", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 2}
+		can, _, err := task.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("move to another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, ProjectID: 2}
+		can, err := task.CanUpdate(s, u)
+		assert.Error(t, err)
+		assert.False(t, can)
+	})
+	t.Run("task link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{ID: 5, ProjectID: 1, TaskID: 1, Right: RightRead}
+
+		task := &Task{ID: 1}
+		can, _, err := task.CanRead(s, share)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		other := &Task{ID: 2}
+		can, _, err = other.CanRead(s, share)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		project := &Project{ID: 1}
+		can, _, err = project.CanRead(s, share)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("tasks/all includes shared tasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{}
+		result, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		tasks, is := result.([]*Task)
+		assert.True(t, is)
+		assert.Len(t, tasks, 2)
+		assert.Equal(t, int64(1), tasks[0].ID)
+		assert.Equal(t, int64(32), tasks[1].ID)
+	})
+}
@@ -49,8 +49,9 @@ const (
 type Task struct {
 	// The unique, numeric id of this task.
 	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"projecttask"`
-	// The task text. This is what you'll see in the project.
-	Title string `xorm:"TEXT not null" json:"title" valid:"minstringlength(1)" minLength:"1"`
+	// The task text. This is what you'll see in the project. Can be left empty when creating a task from a
+	// template, the title of the template task is used then.
+	Title string `xorm:"TEXT not null" json:"title"`
 	// The task description.
 	Description string `xorm:"longtext null" json:"description"`
 	// Whether a task is done or not.
@@ -61,6 +62,8 @@ type Task struct {
 	DueDate time.Time `xorm:"DATETIME INDEX null 'due_date'" json:"due_date"`
 	// An array of reminders that are associated with this task.
 	Reminders []*TaskReminder `xorm:"-" json:"reminders"`
+	// If true, the default reminders of the project or user are not added when this task gets a due, start or end date.
+	DisableDefaultReminders bool `xorm:"not null default false" json:"disable_default_reminders"`
 	// The project this task belongs to.
 	ProjectID int64 `xorm:"bigint INDEX not null" json:"project_id" param:"project"`
 	// An amount in seconds this task repeats itself. If this is set, when marking the task as done, it will mark itself as "undone" and then increase all remindes and the due date by its amount.
@@ -81,6 +84,17 @@ type Task struct {
 	HexColor string `xorm:"varchar(6) null" json:"hex_color" valid:"runelength(0|6)" maxLength:"6"`
 	// Determines how far a task is left from being done
 	PercentDone float64 `xorm:"DOUBLE null" json:"percent_done"`
+	// The progress of this task from 0 to 1 derived from its subtasks. Only set if the project of the task derives
+	// the progress from subtasks and the task has at least one. In that case percent_done holds the same value.
+	ComputedProgress *float64 `xorm:"DOUBLE null" json:"computed_progress"`
+	// An estimate of the effort needed for this task, in any unit like hours or story points. Used to weight
+	// subtasks when computing the progress of their parent task.
+	Estimate float64 `xorm:"DOUBLE null" json:"estimate" valid:"range(0|1000000)"`
+	// The id of the milestone this task belongs to. 0 if the task is not part of any milestone.
+	MilestoneID int64 `xorm:"bigint not null default 0 INDEX" json:"milestone_id"`
+	// The id of the sprint this task is part of. 0 if the task is not part of any sprint. Only open sprints of the
+	// project of the task can be used.
+	SprintID int64 `xorm:"bigint not null default 0 INDEX" json:"sprint_id"`
 
 	// The task identifier, based on the project identifier and the task's index
 	Identifier string `xorm:"-" json:"identifier"`
@@ -96,6 +110,15 @@ type Task struct {
 	// All attachments this task has
 	Attachments []*TaskAttachment `xorm:"-" json:"attachments"`
 
+	// How many items of the checklist of this task are done. Only set if the task has a checklist.
+	ChecklistProgress *TaskChecklistProgress `xorm:"-" json:"checklist_progress"`
+	// The checklist items of this task, only used for the CalDAV export. Use the checklist endpoints to get them.
+	ChecklistItems []*TaskChecklistItem `xorm:"-" json:"-"`
+
+	// The id of a task template to create this task from. All fields which are not provided are taken from the
+	// template. Only used when creating a task.
+	TemplateID int64 `xorm:"-" json:"template_id,omitempty"`
+
 	// If this task has a cover image, the field will return the id of the attachment that is the cover image.
 	CoverImageAttachmentID int64 `xorm:"bigint default 0" json:"cover_image_attachment_id"`
 
@@ -174,6 +197,8 @@ type taskSearchOptions struct {
 	filterConcat       taskFilterConcatinator
 	filterIncludeNulls bool
 	projectIDs         []int64
+	// Tasks which should be included regardless of their project, for example because they were shared directly.
+	sharedTaskIDs []int64
 }
 
 // ReadAll is a dummy function to still have that endpoint documented
@@ -267,8 +292,8 @@ func getTaskIndexFromSearchString(s string) (index int64) {
 //nolint:gocyclo
 func getRawTasksForProjects(s *xorm.Session, projects []*Project, a web.Auth, opts *taskSearchOptions) (tasks []*Task, resultCount int, totalItems int64, err error) {
 
-	// If the user does not have any projects, don't try to get any tasks
-	if len(projects) == 0 {
+	// If the user does not have any projects or tasks shared with them, don't try to get any tasks
+	if len(projects) == 0 && len(opts.sharedTaskIDs) == 0 {
 		return nil, 0, 0, nil
 	}
 
@@ -562,6 +587,11 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth) (e
 		return
 	}
 
+	err = addChecklistItemsToTasks(s, taskIDs, taskMap)
+	if err != nil {
+		return
+	}
+
 	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
 	if err != nil {
 		return
@@ -711,7 +741,7 @@ func getNextTaskIndex(s *xorm.Session, projectID int64) (nextIndex int64, err er
 
 // Create is the implementation to create a project task
 // @Summary Create a task
-// @Description Inserts a task into a project.
+// @Description Inserts a task into a project. Pass a `template_id` to create the task from a task template.
 // @tags task
 // @Accept json
 // @Produce json
@@ -724,14 +754,49 @@ func getNextTaskIndex(s *xorm.Session, projectID int64) (nextIndex int64, err er
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{id}/tasks [put]
 func (t *Task) Create(s *xorm.Session, a web.Auth) (err error) {
-	return createTask(s, t, a, true)
+	if t.MilestoneID != 0 {
+		if err := checkTaskMilestone(s, t, a); err != nil {
+			return err
+		}
+	}
+	if t.SprintID != 0 {
+		if err := checkTaskSprint(s, t); err != nil {
+			return err
+		}
+	}
+
+	if t.TemplateID == 0 {
+		return createTask(s, t, a, true)
+	}
+
+	template, start, err := applyTaskTemplate(s, t, a)
+	if err != nil {
+		return err
+	}
+
+	err = createTask(s, t, a, true)
+	if err != nil {
+		return err
+	}
+
+	project, err := GetProjectSimpleByID(s, t.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	// Assignees passed with the task were already added
+	if len(t.Assignees) > 0 {
+		template.AssigneeIDs = nil
+	}
+	return template.addDetailsToTask(s, t, project, start, a, make(map[int64]bool))
 }
 
 func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err error) {
 
 	t.ID = 0
 
-	// Check if we have at least a text
+	// Check if we have at least a text. This is not enforced when validating the request because the title can
+	// come from a template.
 	if t.Title == "" {
 		return ErrTaskCannotBeEmpty{}
 	}
@@ -772,6 +837,12 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 		return err
 	}
 
+	if t.SprintID != 0 {
+		if err := addTaskToSprint(s, t.ID, t.SprintID); err != nil {
+			return err
+		}
+	}
+
 	t.CreatedBy = createdBy
 
 	// Update the assignees
@@ -782,6 +853,9 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 	}
 
 	// Update the reminders
+	if err := addDefaultReminders(s, &Task{}, t, p, a); err != nil {
+		return err
+	}
 	if err := t.updateReminders(s, t); err != nil {
 		return err
 	}
@@ -834,6 +908,28 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		t.ProjectID = ot.ProjectID
 	}
 
+	// A task moved into another project can't stay in a milestone of its old project
+	if t.MilestoneID == ot.MilestoneID && t.ProjectID != ot.ProjectID {
+		if err := clearMilestoneIfNotInProject(s, t); err != nil {
+			return err
+		}
+	}
+	if t.MilestoneID != 0 && t.MilestoneID != ot.MilestoneID {
+		if err := checkTaskMilestone(s, t, a); err != nil {
+			return err
+		}
+	}
+
+	// Sprints belong to a project, a task moved into another project leaves its sprint
+	if t.SprintID == ot.SprintID && t.ProjectID != ot.ProjectID {
+		t.SprintID = 0
+	}
+	if t.SprintID != ot.SprintID {
+		if err := moveTaskToSprint(s, t, ot.SprintID); err != nil {
+			return err
+		}
+	}
+
 	// Get the stored reminders
 	reminders, err := getRemindersForTasks(s, []int64{t.ID})
 	if err != nil {
@@ -854,11 +950,21 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return err
 	}
+	if t.Done && !ot.Done {
+		if err := checkTaskIsNotBlocked(s, t.ID, project); err != nil {
+			return err
+		}
+	}
 	if targetBucket.ID == project.DoneBucketID && t.RepeatAfter > 0 {
 		t.Done = true // This will trigger the correct re-scheduling of the task (happening in updateDone later)
 		t.BucketID = ot.BucketID
 	}
 
+	// Keep the old dates to move the following tasks along. Repeating tasks which are marked as done get new dates
+	// as well, but that should not affect other tasks.
+	oldDates := &Task{StartDate: ot.StartDate, EndDate: ot.EndDate, DueDate: ot.DueDate}
+	isRepeating := !ot.Done && t.Done && (ot.RepeatAfter > 0 || ot.RepeatMode == TaskRepeatModeMonth)
+
 	// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
 	updateDone(&ot, t)
 
@@ -868,6 +974,9 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	// Update the reminders
+	if err := addDefaultReminders(s, &ot, t, project, a); err != nil {
+		return err
+	}
 	if err := ot.updateReminders(s, t); err != nil {
 		return err
 	}
@@ -891,6 +1000,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		"repeat_mode",
 		"kanban_position",
 		"cover_image_attachment_id",
+		"disable_default_reminders",
+		"estimate",
+		"milestone_id",
+		"sprint_id",
 	}
 
 	// If the task is being moved between projects, make sure to move the bucket + index as well
@@ -975,6 +1088,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if t.Description == "" {
 		ot.Description = ""
 	}
+	// Default reminders
+	if !t.DisableDefaultReminders {
+		ot.DisableDefaultReminders = false
+	}
 	// Due date
 	if t.DueDate.IsZero() {
 		ot.DueDate = time.Time{}
@@ -999,6 +1116,18 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if t.PercentDone == 0 {
 		ot.PercentDone = 0
 	}
+	// Estimate
+	if t.Estimate == 0 {
+		ot.Estimate = 0
+	}
+	// Milestone
+	if t.MilestoneID == 0 {
+		ot.MilestoneID = 0
+	}
+	// Sprint
+	if t.SprintID == 0 {
+		ot.SprintID = 0
+	}
 	// Position
 	if t.Position == 0 {
 		ot.Position = 0
@@ -1041,6 +1170,12 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		}
 	}
 
+	// The progress of this task and its parents may depend on the changes
+	err = updateProgressFromSubtasks(s, t.ID)
+	if err != nil {
+		return err
+	}
+
 	// Get the task updated timestamp in a new struct - if we'd just try to put it into t which we already have, it
 	// would still contain the old updated date.
 	nt := &Task{}
@@ -1051,6 +1186,18 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	t.Updated = nt.Updated
 	t.Position = nt.Position
 	t.KanbanPosition = nt.KanbanPosition
+	t.PercentDone = nt.PercentDone
+	t.ComputedProgress = nt.ComputedProgress
+	t.Done = nt.Done
+	t.DoneAt = nt.DoneAt
+	t.BucketID = nt.BucketID
+
+	if project.AutoScheduling && !isRepeating {
+		_, err = shiftFollowingTasks(s, a, oldDates, t, project.SchedulingSkipWeekends, false)
+		if err != nil {
+			return err
+		}
+	}
 
 	doer, _ := user.GetFromAuth(a)
 	err = events.Dispatch(&TaskUpdatedEvent{
@@ -1399,7 +1546,7 @@ func updateTaskLastUpdated(s *xorm.Session, task *Task) error {
 
 // Delete implements the delete method for a task
 // @Summary Delete a task
-// @Description Deletes a task from a project. This does not mean "mark it done".
+// @Description Moves a task to the trash. It can be restored from there until it is removed permanently. This does not mean "mark it done".
 // @tags task
 // @Produce json
 // @Security JWTKeyAuth
@@ -1418,54 +1565,23 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
-	if _, err = s.ID(t.ID).Delete(Task{}); err != nil {
-		return err
-	}
-
-	// Delete assignees
-	if _, err = s.Where("task_id = ?", t.ID).Delete(TaskAssginee{}); err != nil {
-		return err
-	}
-
-	// Delete Favorites
-	err = removeFromFavorite(s, t.ID, a, FavoriteKindTask)
-	if err != nil {
-		return
-	}
-
-	// Delete label associations
-	_, err = s.Where("task_id = ?", t.ID).Delete(&LabelTask{})
-	if err != nil {
-		return
-	}
-
-	// Delete task attachments
-	attachments, err := getTaskAttachmentsByTaskIDs(s, []int64{t.ID})
+	task, err := GetTaskByIDSimple(s, t.ID)
 	if err != nil {
 		return err
 	}
-	for _, attachment := range attachments {
-		// Using the attachment delete method here because that takes care of removing all files properly
-		err = attachment.Delete(s, a)
-		if err != nil && !IsErrTaskAttachmentDoesNotExist(err) {
-			return err
-		}
-	}
 
-	// Delete all comments
-	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskComment{})
+	err = trashTask(s, &task, a)
 	if err != nil {
-		return
+		return err
 	}
 
-	// Delete all relations
-	_, err = s.Where("task_id = ? OR other_task_id = ?", t.ID, t.ID).Delete(&TaskRelation{})
+	// The relations are kept until the task is purged so that they are back when the task is restored,
+	// but the progress of all parents has to be updated right away.
+	parentIDs, err := getParentTaskIDs(s, t.ID)
 	if err != nil {
 		return
 	}
-
-	// Delete all reminders
-	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskReminder{})
+	err = updateProgressFromSubtasks(s, parentIDs...)
 	if err != nil {
 		return
 	}
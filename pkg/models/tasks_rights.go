@@ -18,6 +18,7 @@ package models
 
 import (
 	"code.vikunja.io/web"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -48,7 +49,13 @@ func (t *Task) CanRead(s *xorm.Session, a web.Auth) (canRead bool, maxRight int,
 
 	// A user can read a task if it has access to the project
 	l := &Project{ID: t.ProjectID}
-	return l.CanRead(s, a)
+	canRead, maxRight, err = l.CanRead(s, a)
+	if err != nil || canRead {
+		return
+	}
+
+	// Or if the task was shared with them directly
+	return t.checkTaskShareRight(s, a, RightRead, RightComment, RightWrite)
 }
 
 // CanWrite checks if a user has write access to a task
@@ -56,6 +63,24 @@ func (t *Task) CanWrite(s *xorm.Session, a web.Auth) (canWrite bool, err error)
 	return t.canDoTask(s, a)
 }
 
+// CanComment checks if a user can comment on a task and add attachments to it
+func (t *Task) CanComment(s *xorm.Session, a web.Auth) (bool, error) {
+	ot, err := GetTaskByIDSimple(s, t.ID)
+	if err != nil {
+		return false, err
+	}
+
+	l := &Project{ID: ot.ProjectID}
+	canComment, err := l.CanComment(s, a)
+	if err != nil || canComment {
+		return canComment, err
+	}
+
+	// Or if the task was shared with them directly with comment or write access
+	canComment, _, err = ot.checkTaskShareRight(s, a, RightComment, RightWrite)
+	return canComment, err
+}
+
 // Helper function to check if a user can do stuff on a project task
 func (t *Task) canDoTask(s *xorm.Session, a web.Auth) (bool, error) {
 	// Get the task
@@ -78,5 +103,48 @@ func (t *Task) canDoTask(s *xorm.Session, a web.Auth) (bool, error) {
 
 	// A user can do a task if it has write acces to its project
 	l := &Project{ID: ot.ProjectID}
-	return l.CanWrite(s, a)
+	canWrite, err := l.CanWrite(s, a)
+	if err != nil || canWrite {
+		return canWrite, err
+	}
+
+	// Or if the task was shared with them directly with write access
+	canWrite, _, err = ot.checkTaskShareRight(s, a, RightWrite)
+	return canWrite, err
+}
+
+// Checks if the task was shared directly with a user or through a task link share with any of the given rights.
+func (t *Task) checkTaskShareRight(s *xorm.Session, a web.Auth, rights ...Right) (bool, int, error) {
+	if shareAuth, is := a.(*LinkSharing); is {
+		if shareAuth.TaskID != t.ID {
+			return false, 0, nil
+		}
+		for _, r := range rights {
+			if shareAuth.Right == r {
+				return true, r.maxRight(), nil
+			}
+		}
+		return false, 0, nil
+	}
+
+	var rightConds []builder.Cond
+	for _, r := range rights {
+		rightConds = append(rightConds, builder.Eq{"tu.right": r})
+	}
+
+	tu := &TaskUser{}
+	exists, err := s.
+		Table("task_users").
+		Alias("tu").
+		Where(builder.And(
+			builder.Eq{"tu.task_id": t.ID},
+			builder.Eq{"tu.user_id": a.GetID()},
+			builder.Or(rightConds...),
+		)).
+		Get(tu)
+	if err != nil || !exists {
+		return false, 0, err
+	}
+
+	return true, tu.Right.maxRight(), nil
 }
@@ -112,6 +112,81 @@ func TestTask_Create(t *testing.T) {
 		err = s.Commit()
 		assert.NoError(t, err)
 	})
+	t.Run("with default reminders of the user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("default_reminders").Update(&user.User{
+			DefaultReminders: []*user.DefaultReminder{
+				{RelativeTo: "due_date", RelativePeriod: -3600},
+				{RelativeTo: "start_date", RelativePeriod: 0},
+			},
+		})
+		assert.NoError(t, err)
+
+		task := &Task{
+			Title:     "Lorem",
+			ProjectID: 1,
+			DueDate:   time.Date(2023, time.March, 7, 22, 0, 0, 0, time.Local),
+			Reminders: []*TaskReminder{
+				{
+					RelativeTo:     "due_date",
+					RelativePeriod: -3600,
+				},
+			},
+		}
+		err = task.Create(s, usr)
+		assert.NoError(t, err)
+		assert.Len(t, task.Reminders, 1)
+		assert.Equal(t, time.Date(2023, time.March, 7, 21, 0, 0, 0, time.Local), task.Reminders[0].Reminder)
+		assert.Equal(t, ReminderRelationDueDate, task.Reminders[0].RelativeTo)
+	})
+	t.Run("with default reminders of the project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("default_reminders").Update(&user.User{
+			DefaultReminders: []*user.DefaultReminder{{RelativeTo: "due_date", RelativePeriod: -3600}},
+		})
+		assert.NoError(t, err)
+		_, err = s.ID(1).Cols("default_reminders").Update(&Project{
+			DefaultReminders: []*user.DefaultReminder{{RelativeTo: "due_date", RelativePeriod: -86400}},
+		})
+		assert.NoError(t, err)
+
+		task := &Task{
+			Title:     "Lorem",
+			ProjectID: 1,
+			DueDate:   time.Date(2023, time.March, 7, 22, 0, 0, 0, time.Local),
+		}
+		err = task.Create(s, usr)
+		assert.NoError(t, err)
+		assert.Len(t, task.Reminders, 1)
+		assert.Equal(t, time.Date(2023, time.March, 6, 22, 0, 0, 0, time.Local), task.Reminders[0].Reminder)
+		assert.Equal(t, int64(-86400), task.Reminders[0].RelativePeriod)
+	})
+	t.Run("with default reminders disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("default_reminders").Update(&user.User{
+			DefaultReminders: []*user.DefaultReminder{{RelativeTo: "due_date", RelativePeriod: -3600}},
+		})
+		assert.NoError(t, err)
+
+		task := &Task{
+			Title:                   "Lorem",
+			ProjectID:               1,
+			DueDate:                 time.Date(2023, time.March, 7, 22, 0, 0, 0, time.Local),
+			DisableDefaultReminders: true,
+		}
+		err = task.Create(s, usr)
+		assert.NoError(t, err)
+		assert.Empty(t, task.Reminders)
+	})
 	t.Run("empty title", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -215,6 +290,64 @@ func TestTask_Update(t *testing.T) {
 			"project_id":  1,
 		}, false)
 	})
+	t.Run("blocked task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("enforce_blocking_tasks").Update(&Project{EnforceBlockingTasks: true})
+		assert.NoError(t, err)
+		rel := &TaskRelation{
+			TaskID:       1,
+			OtherTaskID:  3,
+			RelationKind: RelationKindBlocked,
+		}
+		err = rel.Create(s, u)
+		assert.NoError(t, err)
+
+		task := &Task{
+			ID:        1,
+			Title:     "test",
+			Done:      true,
+			ProjectID: 1,
+		}
+		err = task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+		assert.Equal(t, []int64{3}, err.(ErrTaskIsBlocked).BlockerIDs)
+
+		task = &Task{
+			ID:        1,
+			Title:     "test",
+			BucketID:  3, // Done bucket
+			ProjectID: 1,
+		}
+		err = task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+	})
+	t.Run("blocked task when not enforced", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		rel := &TaskRelation{
+			TaskID:       1,
+			OtherTaskID:  3,
+			RelationKind: RelationKindBlocked,
+		}
+		err := rel.Create(s, u)
+		assert.NoError(t, err)
+
+		task := &Task{
+			ID:        1,
+			Title:     "test",
+			Done:      true,
+			ProjectID: 1,
+		}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+	})
 	t.Run("nonexistant task", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
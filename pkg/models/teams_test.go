@@ -200,6 +200,7 @@ func TestIsErrInvalidRight(t *testing.T) {
 	assert.NoError(t, RightAdmin.isValid())
 	assert.NoError(t, RightRead.isValid())
 	assert.NoError(t, RightWrite.isValid())
+	assert.NoError(t, RightComment.isValid())
 
 	// Check invalid
 	var tr Right = 938
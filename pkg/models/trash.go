@@ -0,0 +1,680 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TrashedItemKind represents what kind of entity was moved to the trash
+type TrashedItemKind string
+
+const (
+	TrashedItemKindTask    TrashedItemKind = "task"
+	TrashedItemKindProject TrashedItemKind = "project"
+)
+
+// trashedTask holds the complete row of a task in the trash, including the columns which are never sent to clients.
+type trashedTask struct {
+	*Task
+	UID         string `json:"uid"`
+	CreatedByID int64  `json:"created_by_id"`
+}
+
+func newTrashedTask(t *Task) *trashedTask {
+	return &trashedTask{
+		Task:        t,
+		UID:         t.UID,
+		CreatedByID: t.CreatedByID,
+	}
+}
+
+func (tt *trashedTask) toTask() *Task {
+	t := tt.Task
+	t.UID = tt.UID
+	t.CreatedByID = tt.CreatedByID
+	return t
+}
+
+// trashedProject holds the complete row of a project in the trash, including the columns which are never sent to clients.
+type trashedProject struct {
+	*Project
+	OwnerID          int64  `json:"owner_id"`
+	InboundEmailHash string `json:"inbound_email_hash"`
+	BackgroundFileID int64  `json:"background_file_id"`
+}
+
+func newTrashedProject(p *Project) *trashedProject {
+	return &trashedProject{
+		Project:          p,
+		OwnerID:          p.OwnerID,
+		InboundEmailHash: p.InboundEmailHash,
+		BackgroundFileID: p.BackgroundFileID,
+	}
+}
+
+func (tp *trashedProject) toProject() *Project {
+	p := tp.Project
+	p.OwnerID = tp.OwnerID
+	p.InboundEmailHash = tp.InboundEmailHash
+	p.BackgroundFileID = tp.BackgroundFileID
+	return p
+}
+
+// trashedItemContent holds the rows which were removed when an item was moved to the trash.
+// Everything else belonging to the tasks (labels, assignees, relations, attachments, comments, ...) stays in place
+// until the item is purged.
+type trashedItemContent struct {
+	Project *trashedProject `json:"project,omitempty"`
+	Tasks   []*trashedTask  `json:"tasks"`
+}
+
+// TrashedItem represents a task or project which was deleted and can still be restored.
+type TrashedItem struct {
+	// The unique, numeric id of this trash item.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"trashitem"`
+	// What kind of entity this is. Either `task` or `project`.
+	Kind TrashedItemKind `xorm:"varchar(50) not null" json:"kind"`
+	// The id the task or project had before it was deleted. It will get the same id back when restored.
+	EntityID int64 `xorm:"bigint not null INDEX" json:"entity_id"`
+	// The title of the deleted task or project.
+	Title string `xorm:"text not null" json:"title"`
+	// For tasks, the project the task belonged to. For projects, their parent project.
+	ProjectID int64 `xorm:"bigint not null default 0" json:"project_id"`
+	// For projects, the number of tasks which were deleted together with the project.
+	TaskCount int `xorm:"-" json:"task_count"`
+
+	Content *trashedItemContent `xorm:"json not null" json:"-"`
+
+	// The owner of the project the item belonged to. They can always see and restore the item.
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+
+	DeletedByID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The user who deleted the task or project.
+	DeletedBy *user.User `xorm:"-" json:"deleted_by"`
+
+	// A timestamp when this item was deleted. You cannot change this value.
+	Deleted time.Time `xorm:"created not null INDEX" json:"deleted"`
+	// When the item will be removed permanently. Null if items in the trash are never removed automatically.
+	PurgeAt *time.Time `xorm:"-" json:"purge_at"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for trashed items
+func (*TrashedItem) TableName() string {
+	return "trashed_items"
+}
+
+func getTrashRetention() time.Duration {
+	return time.Duration(config.ServiceTrashRetention.GetInt()) * time.Hour * 24
+}
+
+func getTrashedItemByID(s *xorm.Session, id int64) (item *TrashedItem, err error) {
+	item = &TrashedItem{}
+	exists, err := s.Where("id = ?", id).Get(item)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTrashedItemDoesNotExist{ID: id}
+	}
+	return
+}
+
+func moveToTrash(s *xorm.Session, item *TrashedItem, a web.Auth) (err error) {
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	item.DeletedByID = doer.ID
+	_, err = s.Insert(item)
+	return
+}
+
+// trashTask moves a single task to the trash. Only the task row itself is removed, everything attached to it stays
+// until the task is purged.
+func trashTask(s *xorm.Session, task *Task, a web.Auth) (err error) {
+	project, err := GetProjectSimpleByID(s, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	err = moveToTrash(s, &TrashedItem{
+		Kind:      TrashedItemKindTask,
+		EntityID:  task.ID,
+		Title:     task.Title,
+		ProjectID: task.ProjectID,
+		OwnerID:   project.OwnerID,
+		Content: &trashedItemContent{
+			Tasks: []*trashedTask{newTrashedTask(task)},
+		},
+	}, a)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ID(task.ID).Delete(&Task{})
+	return
+}
+
+// trashProject moves a project and all of its tasks to the trash.
+func trashProject(s *xorm.Session, project *Project, tasks []*Task, a web.Auth) (err error) {
+	content := &trashedItemContent{
+		Project: newTrashedProject(project),
+		Tasks:   make([]*trashedTask, 0, len(tasks)),
+	}
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		content.Tasks = append(content.Tasks, newTrashedTask(t))
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	err = moveToTrash(s, &TrashedItem{
+		Kind:      TrashedItemKindProject,
+		EntityID:  project.ID,
+		Title:     project.Title,
+		ProjectID: project.ParentProjectID,
+		OwnerID:   project.OwnerID,
+		Content:   content,
+	}, a)
+	if err != nil {
+		return err
+	}
+
+	if len(taskIDs) > 0 {
+		_, err = s.In("id", taskIDs).Delete(&Task{})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.ID(project.ID).Delete(&Project{})
+	return
+}
+
+// purgeTask removes everything belonging to a task which is already gone from the tasks table.
+func purgeTask(s *xorm.Session, taskID int64, a web.Auth) (err error) {
+	// Delete assignees
+	if _, err = s.Where("task_id = ?", taskID).Delete(TaskAssginee{}); err != nil {
+		return err
+	}
+
+	// Delete Favorites of all users
+	_, err = s.Where("entity_id = ? AND kind = ?", taskID, FavoriteKindTask).Delete(&Favorite{})
+	if err != nil {
+		return
+	}
+
+	// Delete label associations
+	_, err = s.Where("task_id = ?", taskID).Delete(&LabelTask{})
+	if err != nil {
+		return
+	}
+
+	// Delete task attachments
+	attachments, err := getTaskAttachmentsByTaskIDs(s, []int64{taskID})
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		// Using the attachment delete method here because that takes care of removing all files properly
+		err = attachment.Delete(s, a)
+		if err != nil && !IsErrTaskAttachmentDoesNotExist(err) {
+			return err
+		}
+	}
+
+	// Delete all comments
+	_, err = s.Where("task_id = ?", taskID).Delete(&TaskComment{})
+	if err != nil {
+		return
+	}
+
+	// Delete all relations
+	_, err = s.Where("task_id = ? OR other_task_id = ?", taskID, taskID).Delete(&TaskRelation{})
+	if err != nil {
+		return
+	}
+
+	// Delete all reminders
+	_, err = s.Where("task_id = ?", taskID).Delete(&TaskReminder{})
+	if err != nil {
+		return
+	}
+
+	// Delete the checklist
+	_, err = s.Where("task_id = ?", taskID).Delete(&TaskChecklistItem{})
+	if err != nil {
+		return
+	}
+
+	// Delete the sprint history
+	_, err = s.Where("task_id = ?", taskID).Delete(&SprintTask{})
+	if err != nil {
+		return
+	}
+
+	// Delete all user and link shares of the task
+	return deleteTaskShares(s, taskID)
+}
+
+// purge permanently removes a trashed item and everything which belonged to it.
+func (ti *TrashedItem) purge(s *xorm.Session, a web.Auth) (err error) {
+	for _, t := range ti.Content.Tasks {
+		err = purgeTask(s, t.ID, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ti.Kind == TrashedItemKindProject {
+		project := ti.Content.Project.toProject()
+		err = project.DeleteBackgroundFileIfExists()
+		if err != nil {
+			return err
+		}
+
+		// Delete all project specific notification preferences
+		_, err = s.Where("project_id = ?", project.ID).Delete(&notifications.NotificationPreference{})
+		if err != nil {
+			return err
+		}
+
+		err = deleteMilestones(s, builder.Eq{"project_id": project.ID})
+		if err != nil {
+			return err
+		}
+
+		err = deleteSprints(s, builder.Eq{"project_id": project.ID})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.Where("id = ?", ti.ID).Delete(&TrashedItem{})
+	return
+}
+
+// purgeTrashedItems permanently removes all items matching the condition. The user who deleted an item is used as
+// the doer for all events dispatched while purging it.
+func purgeTrashedItems(s *xorm.Session, cond builder.Cond) (purged int, err error) {
+	items := []*TrashedItem{}
+	err = s.Where(cond).Find(&items)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range items {
+		err = item.purge(s, &user.User{ID: item.DeletedByID})
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return
+}
+
+func restoreTask(s *xorm.Session, tt *trashedTask, project *Project) (task *Task, err error) {
+	task = tt.toTask()
+
+	// Another task might have gotten the same index while this one was in the trash
+	exists, err := s.
+		Where("project_id = ? AND `index` = ?", task.ProjectID, task.Index).
+		Exist(&Task{})
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		task.Index, err = getNextTaskIndex(s, task.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The bucket might have been deleted in the meantime
+	if task.BucketID != 0 {
+		exists, err = s.
+			Where("id = ? AND project_id = ?", task.BucketID, task.ProjectID).
+			Exist(&Bucket{})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			task.BucketID = 0
+		}
+	}
+	if task.BucketID == 0 {
+		_, err = setTaskBucket(s, task, nil, false, project)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The milestone might have been deleted in the meantime
+	if task.MilestoneID != 0 {
+		exists, err = s.Where("id = ?", task.MilestoneID).Exist(&Milestone{})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			task.MilestoneID = 0
+		}
+	}
+
+	// The sprint might have been deleted or closed in the meantime
+	err = clearSprintIfNotInProject(s, task)
+	if err != nil {
+		return nil, err
+	}
+
+	// The task gets its original id and timestamps back so that everything still attached to it works again.
+	_, err = s.NoAutoTime().Insert(task)
+	if err != nil {
+		return nil, err
+	}
+
+	parentIDs, err := getParentTaskIDs(s, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = updateProgressFromSubtasks(s, parentIDs...)
+	return task, err
+}
+
+func restoreProject(s *xorm.Session, tp *trashedProject) (project *Project, err error) {
+	project = tp.toProject()
+
+	// The parent project might have been deleted in the meantime
+	if project.ParentProjectID != 0 {
+		exists, err := s.Where("id = ?", project.ParentProjectID).Exist(&Project{})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			project.ParentProjectID = 0
+		}
+	}
+
+	// Another project might use the identifier by now
+	if project.Identifier != "" {
+		exists, err := s.Where("identifier = ?", project.Identifier).Exist(&Project{})
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			project.Identifier = ""
+		}
+	}
+
+	_, err = s.NoAutoTime().Insert(project)
+	return project, err
+}
+
+func getTrashedItemsCond(a web.Auth) builder.Cond {
+	return builder.Or(
+		builder.Eq{"owner_id": a.GetID()},
+		builder.Eq{"deleted_by_id": a.GetID()},
+	)
+}
+
+func addMoreInfoToTrashedItems(s *xorm.Session, items []*TrashedItem) (err error) {
+	if len(items) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		userIDs = append(userIDs, item.DeletedByID)
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return err
+	}
+
+	retention := getTrashRetention()
+	for _, item := range items {
+		item.DeletedBy = users[item.DeletedByID]
+		if item.Kind == TrashedItemKindProject {
+			item.TaskCount = len(item.Content.Tasks)
+		}
+		if retention > 0 {
+			purgeAt := item.Deleted.Add(retention)
+			item.PurgeAt = &purgeAt
+		}
+	}
+
+	return nil
+}
+
+// ReadAll returns all trashed items of the current user
+// @Summary Get all items in the trash
+// @Description Returns all tasks and projects the current user deleted or which belonged to one of their projects, newest first.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search items by title."
+// @Success 200 {array} models.TrashedItem "The trashed items"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash [get]
+func (ti *TrashedItem) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrTrashNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	items := []*TrashedItem{}
+
+	where := getTrashedItemsCond(a)
+	if search != "" {
+		where = builder.And(
+			where,
+			db.ILIKE("title", search),
+		)
+	}
+
+	err = s.
+		Where(where).
+		OrderBy("deleted desc, id desc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&items)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	err = addMoreInfoToTrashedItems(s, items)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalCount, err := s.Where(where).Count(&TrashedItem{})
+	return items, len(items), totalCount, err
+}
+
+// Delete permanently removes an item from the trash
+// @Summary Permanently delete an item in the trash
+// @Description Removes a task or project in the trash together with everything that belonged to it. This cannot be undone.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param trashitem path int true "Trash item ID"
+// @Success 200 {object} models.Message "The item was deleted permanently."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that item."
+// @Failure 404 {object} web.HTTPError "The item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash/{trashitem} [delete]
+func (ti *TrashedItem) Delete(s *xorm.Session, a web.Auth) (err error) {
+	return ti.purge(s, a)
+}
+
+// TrashedItemRestore restores an item from the trash
+type TrashedItemRestore struct {
+	// The id of the item in the trash.
+	TrashedItemID int64 `json:"-" param:"trashitem"`
+
+	// The restored task, if the item was a task.
+	Task *Task `json:"task,omitempty"`
+	// The restored project, if the item was a project.
+	Project *Project `json:"project,omitempty"`
+
+	item *TrashedItem
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// Create restores a trashed item
+// @Summary Restore an item from the trash
+// @Description Restores a task or project with the id it had before. Tasks come back with their labels, assignees, relations, attachments, comments, reminders and kanban bucket. If the bucket was deleted in the meantime, the task is put into the default bucket of its project. Projects are restored together with all their tasks. To restore a task, its project must still exist and the user needs write access to it.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param trashitem path int true "Trash item ID"
+// @Success 201 {object} models.TrashedItemRestore "The restored task or project."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that item or the project it belongs to."
+// @Failure 404 {object} web.HTTPError "The item or the project of the task does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash/{trashitem}/restore [post]
+func (tr *TrashedItemRestore) Create(s *xorm.Session, a web.Auth) (err error) {
+	item := tr.item
+	if item == nil {
+		item, err = getTrashedItemByID(s, tr.TrashedItemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	restoredTasks := make([]*Task, 0, len(item.Content.Tasks))
+
+	switch item.Kind {
+	case TrashedItemKindTask:
+		project, err := GetProjectSimpleByID(s, item.ProjectID)
+		if err != nil {
+			return err
+		}
+		tr.Task, err = restoreTask(s, item.Content.Tasks[0], project)
+		if err != nil {
+			return err
+		}
+		restoredTasks = append(restoredTasks, tr.Task)
+	case TrashedItemKindProject:
+		tr.Project, err = restoreProject(s, item.Content.Project)
+		if err != nil {
+			return err
+		}
+		for _, tt := range item.Content.Tasks {
+			task, err := restoreTask(s, tt, tr.Project)
+			if err != nil {
+				return err
+			}
+			restoredTasks = append(restoredTasks, task)
+		}
+	}
+
+	_, err = s.Where("id = ?", item.ID).Delete(&TrashedItem{})
+	if err != nil {
+		return err
+	}
+
+	if tr.Project != nil {
+		err = events.Dispatch(&ProjectRestoredEvent{
+			Project: tr.Project,
+			Doer:    a,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, task := range restoredTasks {
+		err = events.Dispatch(&TaskRestoredEvent{
+			Task: task,
+			Doer: doer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if tr.Task != nil {
+		return updateProjectLastUpdated(s, &Project{ID: tr.Task.ProjectID})
+	}
+
+	return nil
+}
+
+// RegisterTrashPurgeCron registers a cron function which permanently removes all items which were in the trash
+// longer than the configured retention period.
+func RegisterTrashPurgeCron() {
+	if config.ServiceTrashRetention.GetInt() <= 0 {
+		return
+	}
+
+	const logPrefix = "[Trash Purge Cron] "
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := s.Begin()
+		if err != nil {
+			log.Errorf(logPrefix+"Could not start transaction: %s", err)
+			return
+		}
+
+		purged, err := purgeTrashedItems(s, builder.Lt{"deleted": time.Now().Add(-getTrashRetention())})
+		if err != nil {
+			_ = s.Rollback()
+			log.Errorf(logPrefix+"Could not purge trashed items: %s", err)
+			return
+		}
+
+		err = s.Commit()
+		if err != nil {
+			log.Errorf(logPrefix+"Could not commit purged trashed items: %s", err)
+			return
+		}
+
+		if purged > 0 {
+			log.Debugf(logPrefix+"Permanently removed %d items from the trash", purged)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register trash purge cron: %s", err)
+	}
+}
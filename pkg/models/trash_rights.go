@@ -0,0 +1,77 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanDelete checks if a user has the right to permanently delete a trashed item
+func (ti *TrashedItem) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return ti.canDoTrashedItem(s, a)
+}
+
+// CanCreate checks if a user has the right to restore a trashed item
+func (tr *TrashedItemRestore) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	item := &TrashedItem{ID: tr.TrashedItemID}
+	can, err := item.canDoTrashedItem(s, a)
+	if err != nil || !can {
+		return can, err
+	}
+
+	tr.item = item
+
+	switch item.Kind {
+	case TrashedItemKindTask:
+		// The task is put back into its old project, which needs to exist and be writable.
+		return (&Project{ID: item.ProjectID}).CanWrite(s, a)
+	case TrashedItemKindProject:
+		if item.ProjectID == 0 {
+			return true, nil
+		}
+		// If the parent project is gone, the project is restored as a top-level project.
+		parent := &Project{ID: item.ProjectID}
+		can, err = parent.CanWrite(s, a)
+		if IsErrProjectDoesNotExist(err) {
+			return true, nil
+		}
+		return can, err
+	}
+
+	return false, nil
+}
+
+// Items in the trash can be seen by the user who deleted them and the owner of the project they belonged to.
+func (ti *TrashedItem) canDoTrashedItem(s *xorm.Session, a web.Auth) (bool, error) {
+	// Link shares don't have a trash
+	if _, is := a.(*LinkSharing); is {
+		return false, ErrTrashNotAvailableForLinkShare{LinkShareID: a.GetID()}
+	}
+
+	item, err := getTrashedItemByID(s, ti.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if item.OwnerID != a.GetID() && item.DeletedByID != a.GetID() {
+		return false, nil
+	}
+
+	*ti = *item
+	return true, nil
+}
@@ -0,0 +1,369 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/builder"
+)
+
+func getTrashedItemForEntity(t *testing.T, kind TrashedItemKind, id int64) *TrashedItem {
+	s := db.NewSession()
+	defer s.Close()
+
+	item := &TrashedItem{}
+	exists, err := s.Where("kind = ? AND entity_id = ?", kind, id).Get(item)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	return item
+}
+
+func TestTrashedItem_Task(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("delete moves to trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1}
+		err := task.Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"id": 1,
+		})
+		db.AssertExists(t, "trashed_items", map[string]interface{}{
+			"kind":          TrashedItemKindTask,
+			"entity_id":     1,
+			"project_id":    1,
+			"owner_id":      1,
+			"deleted_by_id": 1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  1,
+			"label_id": 4,
+		}, false)
+		db.AssertExists(t, "task_attachments", map[string]interface{}{
+			"id":      1,
+			"task_id": 1,
+		}, false)
+	})
+	t.Run("restore", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1}
+		err := task.Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		item := getTrashedItemForEntity(t, TrashedItemKindTask, 1)
+
+		s = db.NewSession()
+		defer s.Close()
+		restore := &TrashedItemRestore{TrashedItemID: item.ID}
+		can, err := restore.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(1), restore.Task.ID)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":            1,
+			"title":         "task #1",
+			"project_id":    1,
+			"index":         1,
+			"bucket_id":     1,
+			"created_by_id": 1,
+		}, false)
+		db.AssertMissing(t, "trashed_items", map[string]interface{}{
+			"id": item.ID,
+		})
+
+		s = db.NewSession()
+		defer s.Close()
+		restored := &Task{ID: 1}
+		err = restored.ReadOne(s, u)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, restored.Labels)
+	})
+	t.Run("restore into a deleted bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		restore := &TrashedItemRestore{TrashedItemID: 1}
+		_, err := s.Where("id = ?", 1).Delete(&Bucket{})
+		assert.NoError(t, err)
+		can, err := restore.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(9001), restore.Task.ID)
+		assert.NotEqual(t, int64(1), restore.Task.BucketID)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         9001,
+			"uid":        "trashed-task-uid",
+			"project_id": 1,
+			"bucket_id":  restore.Task.BucketID,
+		}, false)
+	})
+	t.Run("restore with a taken index", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1}
+		err := task.Delete(s, u)
+		assert.NoError(t, err)
+		newTask := &Task{Title: "new", ProjectID: 1}
+		err = newTask.Create(s, u)
+		assert.NoError(t, err)
+		_, err = s.Where("id = ?", newTask.ID).Cols("index").Update(&Task{Index: 1})
+		assert.NoError(t, err)
+
+		item := &TrashedItem{}
+		_, err = s.Where("kind = ? AND entity_id = ?", TrashedItemKindTask, 1).Get(item)
+		assert.NoError(t, err)
+		restore := &TrashedItemRestore{TrashedItemID: item.ID}
+		err = restore.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.NotEqual(t, int64(1), restore.Task.Index)
+	})
+	t.Run("restore without access to the project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		restore := &TrashedItemRestore{TrashedItemID: 1}
+		can, err := restore.CanCreate(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("restore when the project is gone", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Delete(&Project{})
+		assert.NoError(t, err)
+		restore := &TrashedItemRestore{TrashedItemID: 1}
+		_, err = restore.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrProjectDoesNotExist(err))
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		restore := &TrashedItemRestore{TrashedItemID: 9999}
+		_, err := restore.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTrashedItemDoesNotExist(err))
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		restore := &TrashedItemRestore{TrashedItemID: 1}
+		_, err := restore.CanCreate(s, &LinkSharing{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrTrashNotAvailableForLinkShare(err))
+	})
+}
+
+func TestTrashedItem_Project(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("delete and restore", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		project := &Project{ID: 1}
+		err := project.Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "projects", map[string]interface{}{
+			"id": 1,
+		})
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"id": 1,
+		})
+		item := getTrashedItemForEntity(t, TrashedItemKindProject, 1)
+
+		s = db.NewSession()
+		defer s.Close()
+		restore := &TrashedItemRestore{TrashedItemID: item.ID}
+		can, err := restore.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = restore.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"id":         1,
+			"title":      "Test1",
+			"identifier": "test1",
+			"owner_id":   1,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":         1,
+			"project_id": 1,
+			"bucket_id":  1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  1,
+			"label_id": 4,
+		}, false)
+	})
+	t.Run("purge", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		files.InitTestFileFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		project := &Project{ID: 35}
+		err := project.Delete(s, &user.User{ID: 6})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		// The background is only removed once the project is gone for good
+		db.AssertExists(t, "files", map[string]interface{}{
+			"id": 1,
+		}, false)
+
+		item := getTrashedItemForEntity(t, TrashedItemKindProject, 35)
+		s = db.NewSession()
+		defer s.Close()
+		can, err := item.CanDelete(s, &user.User{ID: 6})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = item.Delete(s, &user.User{ID: 6})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "trashed_items", map[string]interface{}{
+			"id": item.ID,
+		})
+		db.AssertMissing(t, "files", map[string]interface{}{
+			"id": 1,
+		})
+	})
+}
+
+func TestTrashedItem_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TrashedItem{}
+		result, _, total, err := item.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		assert.NoError(t, err)
+		items := result.([]*TrashedItem)
+		require.Len(t, items, 1)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, int64(9001), items[0].EntityID)
+		assert.Equal(t, TrashedItemKindTask, items[0].Kind)
+		assert.Equal(t, int64(1), items[0].DeletedBy.ID)
+		assert.NotNil(t, items[0].PurgeAt)
+	})
+	t.Run("only own items", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TrashedItem{}
+		result, _, _, err := item.ReadAll(s, &user.User{ID: 2}, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+	t.Run("search", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 34}
+		err := task.Delete(s, &user.User{ID: 13})
+		assert.NoError(t, err)
+
+		item := &TrashedItem{}
+		result, _, _, err := item.ReadAll(s, &user.User{ID: 13}, "task #34", 0, 50)
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+}
+
+func TestPurgeTrashedItems(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	task := &Task{ID: 1}
+	err := task.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+
+	purged, err := purgeTrashedItems(s, builder.Eq{"entity_id": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "label_tasks", map[string]interface{}{
+		"task_id": 1,
+	})
+	db.AssertMissing(t, "task_attachments", map[string]interface{}{
+		"task_id": 1,
+	})
+	db.AssertMissing(t, "task_comments", map[string]interface{}{
+		"task_id": 1,
+	})
+	db.AssertExists(t, "trashed_items", map[string]interface{}{
+		"id": 1,
+	}, false)
+}
@@ -149,6 +149,14 @@ func CreateTypesenseCollections() error {
 				Name: "bucket_id",
 				Type: "int64",
 			},
+			{
+				Name: "milestone_id",
+				Type: "int64",
+			},
+			{
+				Name: "sprint_id",
+				Type: "int64",
+			},
 			{
 				Name: "position",
 				Type: "float",
@@ -414,6 +422,8 @@ type typesenseTask struct {
 	Created                int64       `json:"created"`
 	Updated                int64       `json:"updated"`
 	BucketID               int64       `json:"bucket_id"`
+	MilestoneID            int64       `json:"milestone_id"`
+	SprintID               int64       `json:"sprint_id"`
 	Position               float64     `json:"position"`
 	KanbanPosition         float64     `json:"kanban_position"`
 	CreatedByID            int64       `json:"created_by_id"`
@@ -448,6 +458,8 @@ func convertTaskToTypesenseTask(task *Task) *typesenseTask {
 		Created:                task.Created.UTC().Unix(),
 		Updated:                task.Updated.UTC().Unix(),
 		BucketID:               task.BucketID,
+		MilestoneID:            task.MilestoneID,
+		SprintID:               task.SprintID,
 		Position:               task.Position,
 		KanbanPosition:         task.KanbanPosition,
 		CreatedByID:            task.CreatedByID,
@@ -47,6 +47,7 @@ func SetupTests() {
 		"label_tasks",
 		"labels",
 		"link_shares",
+		"link_share_accesses",
 		"projects",
 		"task_assignees",
 		"task_attachments",
@@ -60,11 +61,21 @@ func SetupTests() {
 		"users",
 		"user_tokens",
 		"users_projects",
+		"task_users",
 		"buckets",
 		"saved_filters",
 		"subscriptions",
 		"favorites",
 		"api_tokens",
+		"notification_preferences",
+		"notification_digest_entries",
+		"push_targets",
+		"task_checklist_items",
+		"trashed_items",
+		"milestones",
+		"sprints",
+		"sprint_tasks",
+		"sent_mentions",
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -145,6 +145,35 @@ func DeleteUser(s *xorm.Session, u *user.User) (err error) {
 		}
 	}
 
+	// Everything the user owns that is still in the trash has to go as well
+	_, err = purgeTrashedItems(s, builder.Eq{"owner_id": u.ID})
+	if err != nil {
+		return err
+	}
+
+	err = deleteMilestones(s, builder.And(
+		builder.Eq{"project_id": 0},
+		builder.Eq{"owner_id": u.ID},
+	))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("notifiable_id = ?", u.ID).Delete(&notifications.NotificationPreference{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("notifiable_id = ?", u.ID).Delete(&notifications.PushTarget{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&SentMention{})
+	if err != nil {
+		return err
+	}
+
 	_, err = s.Where("id = ?", u.ID).Delete(&user.User{})
 	if err != nil {
 		return err
@@ -59,6 +59,9 @@ func ListUsersFromProject(s *xorm.Session, l *Project, search string) (users []*
 					builder.Or(builder.Eq{"ul.right": RightRead}),
 					builder.Or(builder.Eq{"tl.right": RightRead}),
 
+					builder.Or(builder.Eq{"ul.right": RightComment}),
+					builder.Or(builder.Eq{"tl.right": RightComment}),
+
 					builder.Or(builder.Eq{"ul.right": RightWrite}),
 					builder.Or(builder.Eq{"tl.right": RightWrite}),
 
@@ -87,12 +87,18 @@ func NewLinkShareJWTAuthtoken(share *models.LinkSharing) (token string, err erro
 	var ttl = time.Duration(config.ServiceJWTTTL.GetInt64())
 	var exp = time.Now().Add(time.Second * ttl).Unix()
 
+	// The token should not outlive the link share
+	if !share.ExpiresAt.IsZero() && share.ExpiresAt.Unix() < exp {
+		exp = share.ExpiresAt.Unix()
+	}
+
 	// Set claims
 	claims := t.Claims.(jwt.MapClaims)
 	claims["type"] = AuthTypeLinkShare
 	claims["id"] = share.ID
 	claims["hash"] = share.Hash
 	claims["project_id"] = share.ProjectID
+	claims["task_id"] = share.TaskID
 	claims["right"] = share.Right
 	claims["sharedByID"] = share.SharedByID
 	claims["exp"] = exp
@@ -0,0 +1,292 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/models"
+)
+
+// maxMultipartDepth limits how deep nested multipart messages are parsed.
+const maxMultipartDepth = 10
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// charsetReader converts the charsets commonly used in emails to utf-8. Unknown charsets are passed through as-is.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "us-ascii":
+		content, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(latin1ToUTF8(content)), nil
+	}
+	return input, nil
+}
+
+func latin1ToUTF8(content []byte) string {
+	runes := make([]rune, len(content))
+	for i, b := range content {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// parseEmail parses a raw email into the parts Vikunja uses to create tasks or comments.
+func parseEmail(raw []byte) (*models.InboundEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	email := &models.InboundEmail{}
+
+	from, err := (&mail.AddressParser{WordDecoder: wordDecoder}).Parse(msg.Header.Get("From"))
+	if err == nil {
+		email.From = from.Address
+		email.FromName = from.Name
+	}
+
+	email.SenderVerified = isSenderVerified(msg.Header["Authentication-Results"], email.From)
+
+	email.Subject, err = wordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		email.Subject = msg.Header.Get("Subject")
+	}
+
+	var htmlText string
+	err = parsePart(email, &htmlText, msg.Header, msg.Body, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(email.Text) == "" && htmlText != "" {
+		email.Text = htmlToText(htmlText)
+	}
+
+	return email, nil
+}
+
+var authResultsCommentRegex = regexp.MustCompile(`\([^)]*\)`)
+
+// isSenderVerified checks the Authentication-Results headers added by the mail server for a passed DMARC, DKIM or
+// SPF check which belongs to the domain of the sender. Headers from other servers are ignored since anyone can add
+// them to an email.
+func isSenderVerified(authResults []string, from string) bool {
+	authservID := config.InboundAuthservID.GetString()
+	at := strings.LastIndex(from, "@")
+	if authservID == "" || at < 0 {
+		return false
+	}
+	domain := from[at+1:]
+
+	for _, header := range authResults {
+		parts := strings.Split(authResultsCommentRegex.ReplaceAllString(header, ""), ";")
+		// The authserv-id can be followed by a version
+		id := strings.Fields(parts[0])
+		if len(id) == 0 || !strings.EqualFold(id[0], authservID) {
+			continue
+		}
+
+		for _, result := range parts[1:] {
+			fields := strings.Fields(result)
+			if len(fields) == 0 {
+				continue
+			}
+
+			var property string
+			switch strings.ToLower(fields[0]) {
+			case "dmarc=pass":
+				property = "header.from"
+			case "dkim=pass":
+				property = "header.d"
+			case "spf=pass":
+				property = "smtp.mailfrom"
+			default:
+				continue
+			}
+
+			for _, field := range fields[1:] {
+				key, value, found := strings.Cut(field, "=")
+				if !found || !strings.EqualFold(key, property) {
+					continue
+				}
+				// smtp.mailfrom contains an address, the others only a domain
+				if valueAt := strings.LastIndex(value, "@"); valueAt >= 0 {
+					value = value[valueAt+1:]
+				}
+				if strings.EqualFold(strings.Trim(value, `"`), domain) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// header is implemented by the headers of the message and the headers of its mime parts.
+type header interface {
+	Get(key string) string
+}
+
+func parsePart(email *models.InboundEmail, htmlText *string, h header, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMultipartDepth {
+			return nil
+		}
+
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			err = parsePart(email, htmlText, part.Header, part, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransferEncoding(h.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return err
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	if disposition == "attachment" || (filename != "" && !strings.HasPrefix(mediaType, "text/")) {
+		if filename == "" {
+			filename = "attachment"
+		}
+		if decoded, err := wordDecoder.DecodeHeader(filename); err == nil {
+			filename = decoded
+		}
+		email.Attachments = append(email.Attachments, &models.InboundEmailAttachment{
+			Filename: filename,
+			Content:  content,
+		})
+		return nil
+	}
+
+	text, err := decodeCharset(params["charset"], content)
+	if err != nil {
+		return err
+	}
+
+	switch mediaType {
+	case "text/plain":
+		if email.Text == "" {
+			email.Text = text
+		}
+	case "text/html":
+		if *htmlText == "" {
+			*htmlText = text
+		}
+	}
+
+	return nil
+}
+
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// newlineStripper removes line breaks from base64 encoded content since the decoder does not accept them.
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n *newlineStripper) Read(p []byte) (int, error) {
+	for {
+		read, err := n.r.Read(p)
+		kept := 0
+		for _, b := range p[:read] {
+			if b != '\r' && b != '\n' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+func decodeCharset(charset string, content []byte) (string, error) {
+	if charset == "" {
+		return string(content), nil
+	}
+
+	r, err := charsetReader(charset, bytes.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("could not decode charset %s: %w", charset, err)
+	}
+	decoded, err := io.ReadAll(r)
+	return string(decoded), err
+}
+
+var (
+	htmlBreakRegex     = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>|</h[1-6]>`)
+	htmlIgnoredRegex   = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	htmlTagRegex       = regexp.MustCompile(`(?s)<[^>]*>`)
+	multipleBreakRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText converts the html content of an email to plain text for emails which do not have a text part.
+func htmlToText(content string) string {
+	content = htmlIgnoredRegex.ReplaceAllString(content, "")
+	content = htmlBreakRegex.ReplaceAllString(content, "\n")
+	content = htmlTagRegex.ReplaceAllString(content, "")
+	content = strings.ReplaceAll(html.UnescapeString(content), "\u00a0", " ")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.TrimSpace(multipleBreakRegex.ReplaceAllString(content, "\n\n"))
+}
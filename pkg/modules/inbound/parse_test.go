@@ -0,0 +1,157 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEmail(t *testing.T) {
+	t.Run("plain text", func(t *testing.T) {
+		raw := strings.Join([]string{
+			"From: =?utf-8?q?J=C3=BCrgen?= <juergen@example.com>",
+			"To: project-abc@inbound.example.com",
+			"Subject: =?iso-8859-1?q?Gr=FC=DFe?=",
+			"Content-Type: text/plain; charset=utf-8",
+			"Content-Transfer-Encoding: quoted-printable",
+			"",
+			"Hello=20there,",
+			"this is a long line which is wrapped =",
+			"by the sender.",
+		}, "\r\n")
+
+		email, err := parseEmail([]byte(raw))
+		assert.NoError(t, err)
+		assert.Equal(t, "juergen@example.com", email.From)
+		assert.Equal(t, "Jürgen", email.FromName)
+		assert.Equal(t, "Grüße", email.Subject)
+		assert.Equal(t, "Hello there,\r\nthis is a long line which is wrapped by the sender.", email.Text)
+	})
+	t.Run("multipart with attachment", func(t *testing.T) {
+		raw := strings.Join([]string{
+			"From: user@example.com",
+			"Subject: Files",
+			"Content-Type: multipart/mixed; boundary=outer",
+			"",
+			"--outer",
+			"Content-Type: multipart/alternative; boundary=inner",
+			"",
+			"--inner",
+			"Content-Type: text/html; charset=utf-8",
+			"",
+			"<p>Html</p>",
+			"--inner",
+			"Content-Type: text/plain; charset=utf-8",
+			"",
+			"Text",
+			"--inner--",
+			"--outer",
+			"Content-Type: application/octet-stream",
+			"Content-Disposition: attachment; filename=\"data.bin\"",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"aGVsbG8g",
+			"d29ybGQ=",
+			"--outer--",
+		}, "\r\n")
+
+		email, err := parseEmail([]byte(raw))
+		assert.NoError(t, err)
+		assert.Equal(t, "Text", email.Text)
+		assert.Len(t, email.Attachments, 1)
+		assert.Equal(t, "data.bin", email.Attachments[0].Filename)
+		assert.Equal(t, "hello world", string(email.Attachments[0].Content))
+	})
+	t.Run("html only", func(t *testing.T) {
+		raw := strings.Join([]string{
+			"From: user@example.com",
+			"Subject: Html",
+			"Content-Type: text/html; charset=utf-8",
+			"",
+			"<html><head><style>p {}</style></head><body><p>First &amp; foremost</p><p>Second<br>line</p></body></html>",
+		}, "\r\n")
+
+		email, err := parseEmail([]byte(raw))
+		assert.NoError(t, err)
+		assert.Equal(t, "First & foremost\nSecond\nline", email.Text)
+	})
+}
+
+func TestParsePath(t *testing.T) {
+	address, ok := parsePath("FROM:<user@example.com> SIZE=100", "FROM:")
+	assert.True(t, ok)
+	assert.Equal(t, "user@example.com", address)
+
+	address, ok = parsePath("to: <project-abc@inbound.example.com>", "TO:")
+	assert.True(t, ok)
+	assert.Equal(t, "project-abc@inbound.example.com", address)
+
+	_, ok = parsePath("TO:user@example.com", "TO:")
+	assert.False(t, ok)
+}
+
+func TestIsSenderVerified(t *testing.T) {
+	config.InboundAuthservID.Set("mx.example.com")
+	defer config.InboundAuthservID.Set("")
+
+	t.Run("dmarc pass", func(t *testing.T) {
+		assert.True(t, isSenderVerified([]string{
+			"mx.example.com; spf=fail smtp.mailfrom=other.org; dmarc=pass (p=none dis=none) header.from=example.org",
+		}, "user@example.org"))
+	})
+	t.Run("dkim pass of another domain", func(t *testing.T) {
+		assert.False(t, isSenderVerified([]string{
+			"mx.example.com 1; dkim=pass header.d=attacker.org header.s=mail",
+		}, "user@example.org"))
+	})
+	t.Run("spf pass", func(t *testing.T) {
+		assert.True(t, isSenderVerified([]string{
+			"mx.example.com; spf=pass smtp.mailfrom=bounce@Example.org",
+		}, "user@example.org"))
+	})
+	t.Run("header of another server", func(t *testing.T) {
+		assert.False(t, isSenderVerified([]string{
+			"mx.attacker.org; dmarc=pass header.from=example.org",
+		}, "user@example.org"))
+	})
+	t.Run("not configured", func(t *testing.T) {
+		config.InboundAuthservID.Set("")
+		defer config.InboundAuthservID.Set("mx.example.com")
+
+		assert.False(t, isSenderVerified([]string{
+			"mx.example.com; dmarc=pass header.from=example.org",
+		}, "user@example.org"))
+	})
+	t.Run("parsed from the email", func(t *testing.T) {
+		raw := strings.Join([]string{
+			"Authentication-Results: mx.example.com; dkim=pass header.d=example.org",
+			"From: user@example.org",
+			"Subject: Verified",
+			"",
+			"Hi",
+		}, "\r\n")
+
+		email, err := parseEmail([]byte(raw))
+		assert.NoError(t, err)
+		assert.True(t, email.SenderVerified)
+	})
+}
@@ -0,0 +1,338 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package inbound
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/c2h5oh/datasize"
+)
+
+const (
+	commandTimeout = 5 * time.Minute
+	maxRecipients  = 100
+)
+
+// Server accepts emails via SMTP or LMTP and turns them into tasks and comments.
+type Server struct {
+	listener net.Listener
+	hostname string
+	maxSize  uint64
+}
+
+// Init starts the inbound email server if it is enabled in the config.
+func Init() {
+	if !config.InboundEnabled.GetBool() {
+		return
+	}
+
+	if config.InboundDomain.GetString() == "" {
+		log.Error("Inbound email is enabled but no domain is configured, not starting the inbound email server.")
+		return
+	}
+
+	var maxSize datasize.ByteSize
+	err := maxSize.UnmarshalText([]byte(config.InboundMaxSize.GetString()))
+	if err != nil {
+		log.Fatalf("Could not parse inbound email max size: %s", err)
+	}
+
+	listener, err := net.Listen("tcp", config.InboundListen.GetString())
+	if err != nil {
+		log.Fatalf("Could not start the inbound email server: %s", err)
+	}
+
+	server := &Server{
+		listener: listener,
+		hostname: config.InboundDomain.GetString(),
+		maxSize:  maxSize.Bytes(),
+	}
+
+	log.Infof("Accepting inbound emails on %s", listener.Addr())
+
+	go server.Serve()
+}
+
+// Serve accepts connections until the listener is closed.
+func (srv *Server) Serve() {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Errorf("Could not accept inbound email connection: %s", err)
+			continue
+		}
+
+		go srv.handleConnection(conn)
+	}
+}
+
+// Close stops accepting new connections.
+func (srv *Server) Close() error {
+	return srv.listener.Close()
+}
+
+// session holds the state of one smtp or lmtp conversation.
+type session struct {
+	srv        *Server
+	conn       net.Conn
+	text       *textproto.Conn
+	lmtp       bool
+	greeted    bool
+	from       string
+	recipients []string
+}
+
+func (srv *Server) handleConnection(conn net.Conn) {
+	sess := &session{
+		srv:  srv,
+		conn: conn,
+		text: textproto.NewConn(conn),
+	}
+	defer sess.text.Close()
+
+	sess.reply(220, srv.hostname+" Vikunja ready")
+
+	for {
+		_ = conn.SetDeadline(time.Now().Add(commandTimeout))
+		line, err := sess.text.ReadLine()
+		if err != nil {
+			if err != io.EOF {
+				log.Debugf("Inbound email connection from %s closed: %s", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if !sess.handleCommand(strings.ToUpper(command), strings.TrimSpace(arg)) {
+			return
+		}
+	}
+}
+
+func (sess *session) reply(code int, message string) {
+	_ = sess.text.PrintfLine("%d %s", code, message)
+}
+
+func (sess *session) reset() {
+	sess.from = ""
+	sess.recipients = nil
+}
+
+// handleCommand handles one command of the client. It returns false if the connection should be closed.
+func (sess *session) handleCommand(command, arg string) bool {
+	switch command {
+	case "HELO", "EHLO", "LHLO":
+		sess.lmtp = command == "LHLO"
+		sess.greeted = true
+		sess.reset()
+		if command == "HELO" {
+			sess.reply(250, sess.srv.hostname)
+			return true
+		}
+		_ = sess.text.PrintfLine("250-%s", sess.srv.hostname)
+		_ = sess.text.PrintfLine("250-SIZE %d", sess.srv.maxSize)
+		_ = sess.text.PrintfLine("250-8BITMIME")
+		sess.reply(250, "PIPELINING")
+	case "MAIL":
+		if !sess.greeted {
+			sess.reply(503, "Send HELO, EHLO or LHLO first")
+			return true
+		}
+		address, ok := parsePath(arg, "FROM:")
+		if !ok {
+			sess.reply(501, "Syntax: MAIL FROM:<address>")
+			return true
+		}
+		sess.reset()
+		sess.from = address
+		sess.reply(250, "OK")
+	case "RCPT":
+		if sess.from == "" {
+			sess.reply(503, "Send MAIL first")
+			return true
+		}
+		address, ok := parsePath(arg, "TO:")
+		if !ok || address == "" {
+			sess.reply(501, "Syntax: RCPT TO:<address>")
+			return true
+		}
+		if len(sess.recipients) >= maxRecipients {
+			sess.reply(452, "Too many recipients")
+			return true
+		}
+		if !models.IsInboundEmailRecipient(address) {
+			sess.reply(550, "No such user here")
+			return true
+		}
+		sess.recipients = append(sess.recipients, address)
+		sess.reply(250, "OK")
+	case "DATA":
+		if len(sess.recipients) == 0 {
+			sess.reply(503, "Send RCPT first")
+			return true
+		}
+		sess.reply(354, "End data with <CR><LF>.<CR><LF>")
+		sess.handleData()
+		sess.reset()
+	case "RSET":
+		sess.reset()
+		sess.reply(250, "OK")
+	case "NOOP":
+		sess.reply(250, "OK")
+	case "VRFY":
+		sess.reply(252, "Cannot verify user")
+	case "QUIT":
+		sess.reply(221, "Bye")
+		return false
+	default:
+		sess.reply(502, "Command not implemented")
+	}
+
+	return true
+}
+
+// parsePath extracts the address of a MAIL FROM or RCPT TO argument.
+func parsePath(arg, prefix string) (address string, ok bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	path := strings.TrimSpace(arg[len(prefix):])
+	// Ignore any parameters after the path, like SIZE=
+	path, _, _ = strings.Cut(path, " ")
+	if !strings.HasPrefix(path, "<") || !strings.HasSuffix(path, ">") {
+		return "", false
+	}
+
+	return strings.TrimSpace(path[1 : len(path)-1]), true
+}
+
+func (sess *session) handleData() {
+	data := sess.text.DotReader()
+	raw, err := io.ReadAll(io.LimitReader(data, int64(sess.srv.maxSize)+1))
+	if err != nil {
+		sess.replyAll(451, "Could not read message")
+		return
+	}
+
+	if uint64(len(raw)) > sess.srv.maxSize {
+		// Read the rest of the message so that the connection stays usable
+		_, _ = io.Copy(io.Discard, data)
+		sess.replyAll(552, "Message exceeds the maximum size")
+		return
+	}
+
+	email, err := parseEmail(raw)
+	if err != nil {
+		log.Debugf("Could not parse inbound email from %s: %s", sess.from, err)
+		sess.replyAll(554, "Could not parse message")
+		return
+	}
+
+	// The envelope sender is used if the message does not have a valid From header
+	if email.From == "" {
+		email.From = sess.from
+	}
+
+	var firstErr error
+	delivered := false
+	for _, recipient := range sess.recipients {
+		err := deliver(recipient, email)
+		if err != nil {
+			log.Errorf("Could not process inbound email from %s to %s: %s", email.From, recipient, err)
+			if sess.lmtp {
+				sess.reply(errorCode(err), "Could not deliver to "+recipient)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		delivered = true
+		if sess.lmtp {
+			sess.reply(250, "Delivered to "+recipient)
+		}
+	}
+
+	if sess.lmtp {
+		return
+	}
+
+	if delivered || firstErr == nil {
+		sess.reply(250, "OK")
+		return
+	}
+	sess.reply(errorCode(firstErr), "Could not deliver message")
+}
+
+// replyAll sends a reply once for smtp and once per recipient for lmtp.
+func (sess *session) replyAll(code int, message string) {
+	if !sess.lmtp {
+		sess.reply(code, message)
+		return
+	}
+
+	for range sess.recipients {
+		sess.reply(code, message)
+	}
+}
+
+func deliver(recipient string, email *models.InboundEmail) (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = models.ProcessInboundEmail(s, recipient, email)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+// errorCode returns a permanent error code for errors which will not change when the email is sent again and a
+// temporary one for everything else.
+func errorCode(err error) int {
+	if models.IsErrInboundEmailRecipientDoesNotExist(err) ||
+		models.IsErrInboundEmailSenderNotAllowed(err) ||
+		user.IsErrUserDoesNotExist(err) ||
+		models.IsErrTaskDoesNotExist(err) {
+		return 550
+	}
+
+	return 451
+}
@@ -203,6 +203,9 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 
 		oldid := t.ID
 		t.ProjectID = project.ID
+		// Milestones and sprints are not migrated, the ids would point to the ones of the old instance
+		t.MilestoneID = 0
+		t.SprintID = 0
 		err = t.Create(s, user)
 		if err != nil {
 			return
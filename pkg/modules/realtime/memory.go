@@ -0,0 +1,79 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package realtime
+
+import (
+	"sync"
+
+	"code.vikunja.io/api/pkg/config"
+)
+
+// memoryBackend keeps events of this instance in memory. Cursors are reset when Vikunja is restarted.
+type memoryBackend struct {
+	lock    sync.Mutex
+	lastID  int64
+	backlog []*Event
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{
+		backlog: make([]*Event, 0, config.RealtimeBacklog.GetInt()),
+	}
+}
+
+func (m *memoryBackend) publish(event *Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.lastID++
+	event.ID = m.lastID
+
+	m.backlog = append(m.backlog, event)
+	if size := config.RealtimeBacklog.GetInt(); len(m.backlog) > size {
+		m.backlog = m.backlog[len(m.backlog)-size:]
+	}
+
+	deliver(event)
+	return nil
+}
+
+func (m *memoryBackend) since(cursor int64) (events []*Event, complete bool, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return eventsSince(m.backlog, m.lastID, cursor)
+}
+
+// eventsSince returns all events from a backlog ordered by id which come after the cursor.
+func eventsSince(backlog []*Event, lastID, cursor int64) (events []*Event, complete bool, err error) {
+	// A cursor from the future means the ids were reset, for example after a restart
+	if cursor > lastID {
+		return nil, false, nil
+	}
+	if cursor == lastID {
+		return nil, true, nil
+	}
+	complete = len(backlog) > 0 && backlog[0].ID <= cursor+1
+
+	for _, e := range backlog {
+		if e.ID > cursor {
+			events = append(events, e)
+		}
+	}
+
+	return events, complete, nil
+}
@@ -0,0 +1,164 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+)
+
+// Event is a change which is streamed to clients as it happens.
+type Event struct {
+	// The cursor of this event. Clients pass the id of the last event they received to resume the stream after reconnecting.
+	ID int64 `json:"id"`
+	// The name of the event, for example task.updated.
+	Name string `json:"name"`
+	// The project this event happened in. Only users who can read the project receive it.
+	ProjectID int64 `json:"project_id,omitempty"`
+	// If set, the event is only sent to this user, regardless of the project.
+	UserID int64 `json:"user_id,omitempty"`
+	// The changed entities.
+	Data json.RawMessage `json:"data"`
+	// When this event happened.
+	Created time.Time `json:"created"`
+}
+
+// ResetEventName is sent to a client if events were missed since the cursor it passed, for example because the
+// cursor is older than the backlog. The client should reload all data it shows.
+const ResetEventName = "stream.reset"
+
+// backend assigns ids to events, keeps a backlog to resume streams and distributes events to all instances.
+type backend interface {
+	publish(event *Event) error
+	since(cursor int64) (events []*Event, complete bool, err error)
+}
+
+var (
+	b backend
+
+	subscribersLock sync.Mutex
+	subscribers     = make(map[*Subscription]bool)
+)
+
+// InitRealtime sets up the configured realtime backend. When redis is enabled, events are distributed to all
+// instances through redis pub/sub, otherwise they are only kept in memory.
+func InitRealtime() {
+	if !config.RealtimeEnabled.GetBool() {
+		return
+	}
+
+	if config.RedisEnabled.GetBool() {
+		b = newRedisBackend()
+		log.Debug("Using redis for realtime events")
+		return
+	}
+
+	b = newMemoryBackend()
+}
+
+// Enabled returns whether realtime events are available.
+func Enabled() bool {
+	return b != nil
+}
+
+// Publish sends an event to all clients subscribed to it.
+func Publish(name string, projectID, userID int64, data interface{}) error {
+	if b == nil {
+		return nil
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return b.publish(&Event{
+		Name:      name,
+		ProjectID: projectID,
+		UserID:    userID,
+		Data:      content,
+		Created:   time.Now(),
+	})
+}
+
+// Since returns all events after the cursor which are still in the backlog. complete is false if some events after
+// the cursor are not available anymore.
+func Since(cursor int64) (events []*Event, complete bool, err error) {
+	if b == nil {
+		return nil, false, nil
+	}
+	return b.since(cursor)
+}
+
+// Subscription receives all events published after it was created.
+type Subscription struct {
+	events chan *Event
+}
+
+// Subscribe creates a new subscription. It needs to be closed with Unsubscribe once it is not needed anymore.
+func Subscribe() *Subscription {
+	sub := &Subscription{
+		events: make(chan *Event, config.RealtimeBufferSize.GetInt()),
+	}
+
+	subscribersLock.Lock()
+	defer subscribersLock.Unlock()
+	subscribers[sub] = true
+
+	return sub
+}
+
+// Events returns the channel new events are sent to. The channel is closed if the subscriber does not keep up
+// with new events, the client should then reconnect and resume from its last cursor.
+func (sub *Subscription) Events() <-chan *Event {
+	return sub.events
+}
+
+// Unsubscribe stops sending events to a subscription.
+func (sub *Subscription) Unsubscribe() {
+	subscribersLock.Lock()
+	defer subscribersLock.Unlock()
+	removeSubscriber(sub)
+}
+
+// removeSubscriber needs to be called with subscribersLock held.
+func removeSubscriber(sub *Subscription) {
+	if !subscribers[sub] {
+		return
+	}
+	delete(subscribers, sub)
+	close(sub.events)
+}
+
+// deliver hands an event to all subscribers of this instance. Backends call it in the order of the event ids.
+func deliver(event *Event) {
+	subscribersLock.Lock()
+	defer subscribersLock.Unlock()
+
+	for sub := range subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			log.Debugf("Dropping realtime subscriber because it does not keep up with events")
+			removeSubscriber(sub)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package realtime
+
+import (
+	"os"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	config.InitDefaultConfig()
+	os.Exit(m.Run())
+}
+
+func TestMemoryBackend(t *testing.T) {
+	t.Run("publish and subscribe", func(t *testing.T) {
+		b = newMemoryBackend()
+		defer func() { b = nil }()
+
+		sub := Subscribe()
+		defer sub.Unsubscribe()
+
+		err := Publish("task.updated", 1, 0, map[string]string{"title": "test"})
+		assert.NoError(t, err)
+		err = Publish("notification.created", 0, 2, map[string]string{})
+		assert.NoError(t, err)
+
+		first := <-sub.Events()
+		assert.Equal(t, int64(1), first.ID)
+		assert.Equal(t, "task.updated", first.Name)
+		assert.Equal(t, int64(1), first.ProjectID)
+		assert.JSONEq(t, `{"title":"test"}`, string(first.Data))
+		second := <-sub.Events()
+		assert.Equal(t, int64(2), second.ID)
+		assert.Equal(t, int64(2), second.UserID)
+	})
+	t.Run("resume from cursor", func(t *testing.T) {
+		b = newMemoryBackend()
+		defer func() { b = nil }()
+
+		for i := 0; i < 3; i++ {
+			err := Publish("task.updated", 1, 0, nil)
+			assert.NoError(t, err)
+		}
+
+		events, complete, err := Since(1)
+		assert.NoError(t, err)
+		assert.True(t, complete)
+		assert.Len(t, events, 2)
+		assert.Equal(t, int64(2), events[0].ID)
+		assert.Equal(t, int64(3), events[1].ID)
+
+		events, complete, err = Since(3)
+		assert.NoError(t, err)
+		assert.True(t, complete)
+		assert.Empty(t, events)
+	})
+	t.Run("cursor older than backlog", func(t *testing.T) {
+		config.RealtimeBacklog.Set(2)
+		defer config.RealtimeBacklog.Set(1000)
+		b = newMemoryBackend()
+		defer func() { b = nil }()
+
+		for i := 0; i < 5; i++ {
+			err := Publish("task.updated", 1, 0, nil)
+			assert.NoError(t, err)
+		}
+
+		events, complete, err := Since(1)
+		assert.NoError(t, err)
+		assert.False(t, complete)
+		assert.Len(t, events, 2)
+	})
+	t.Run("cursor from before a restart", func(t *testing.T) {
+		b = newMemoryBackend()
+		defer func() { b = nil }()
+
+		err := Publish("task.updated", 1, 0, nil)
+		assert.NoError(t, err)
+
+		_, complete, err := Since(42)
+		assert.NoError(t, err)
+		assert.False(t, complete)
+	})
+	t.Run("slow subscriber", func(t *testing.T) {
+		config.RealtimeBufferSize.Set(1)
+		defer config.RealtimeBufferSize.Set(100)
+		b = newMemoryBackend()
+		defer func() { b = nil }()
+
+		sub := Subscribe()
+		defer sub.Unsubscribe()
+
+		for i := 0; i < 2; i++ {
+			err := Publish("task.updated", 1, 0, nil)
+			assert.NoError(t, err)
+		}
+
+		_, ok := <-sub.Events()
+		assert.True(t, ok)
+		_, ok = <-sub.Events()
+		assert.False(t, ok)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		assert.False(t, Enabled())
+		err := Publish("task.updated", 1, 0, nil)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,119 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/red"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisCursorKey  = "realtime:cursor"
+	redisBacklogKey = "realtime:backlog"
+	redisChannel    = "realtime:events"
+)
+
+// publishScript assigns the next id to an event, adds it to the backlog and publishes it in one step so that
+// all instances receive events in the order of their ids.
+var publishScript = redis.NewScript(`
+local id = redis.call('INCR', KEYS[1])
+local payload = string.gsub(ARGV[1], '^{"id":0,', '{"id":' .. id .. ',', 1)
+redis.call('RPUSH', KEYS[2], payload)
+redis.call('LTRIM', KEYS[2], -tonumber(ARGV[2]), -1)
+redis.call('PUBLISH', ARGV[3], payload)
+return id
+`)
+
+// redisBackend distributes events to all Vikunja instances using redis pub/sub.
+type redisBackend struct {
+	client *redis.Client
+}
+
+func newRedisBackend() *redisBackend {
+	red.InitRedis()
+
+	r := &redisBackend{
+		client: red.GetRedis(),
+	}
+	go r.receive()
+	return r
+}
+
+// receive delivers all events published by any instance to the local subscribers.
+func (r *redisBackend) receive() {
+	pubsub := r.client.Subscribe(context.Background(), redisChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		event := &Event{}
+		err := json.Unmarshal([]byte(msg.Payload), event)
+		if err != nil {
+			log.Errorf("Could not decode realtime event from redis: %s", err)
+			continue
+		}
+		deliver(event)
+	}
+}
+
+func (r *redisBackend) publish(event *Event) error {
+	event.ID = 0
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return publishScript.Run(
+		context.Background(),
+		r.client,
+		[]string{redisCursorKey, redisBacklogKey},
+		string(payload),
+		config.RealtimeBacklog.GetInt(),
+		redisChannel,
+	).Err()
+}
+
+func (r *redisBackend) since(cursor int64) (events []*Event, complete bool, err error) {
+	ctx := context.Background()
+
+	lastID, err := r.client.Get(ctx, redisCursorKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, false, err
+	}
+
+	raw, err := r.client.LRange(ctx, redisBacklogKey, 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	backlog := make([]*Event, 0, len(raw))
+	for _, payload := range raw {
+		event := &Event{}
+		if err := json.Unmarshal([]byte(payload), event); err != nil {
+			return nil, false, err
+		}
+		backlog = append(backlog, event)
+	}
+
+	return eventsSince(backlog, lastID, cursor)
+}
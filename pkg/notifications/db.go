@@ -20,5 +20,8 @@ package notifications
 func GetTables() []interface{} {
 	return []interface{}{
 		&DatabaseNotification{},
+		&NotificationPreference{},
+		&DigestEntry{},
+		&PushTarget{},
 	}
 }
@@ -0,0 +1,145 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"xorm.io/xorm"
+)
+
+// DigestEntry is a mail notification which was held back to be sent as part of a digest mail.
+type DigestEntry struct {
+	// The unique, numeric id of this entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+
+	// The ID of the notifiable this entry will be sent to.
+	NotifiableID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The name of the notification this entry was created from.
+	Name string `xorm:"varchar(250) not null" json:"name"`
+	// The project and task this entry is about. Used to group the entries in the digest mail.
+	ProjectID int64 `xorm:"bigint not null default 0" json:"project_id"`
+	TaskID    int64 `xorm:"bigint not null default 0" json:"task_id"`
+	// The title of the task at the time the notification was created. The task might not exist anymore when the
+	// digest is sent.
+	TaskTitle string `xorm:"text null" json:"task_title"`
+	// A short summary of what happened.
+	Line string `xorm:"text not null" json:"line"`
+
+	// A timestamp when this entry was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName resolves to a better table name for digest entries
+func (d *DigestEntry) TableName() string {
+	return "notification_digest_entries"
+}
+
+// DigestableNotification is a notification which can be batched into a digest mail instead of sending a mail for
+// each notification.
+type DigestableNotification interface {
+	ToDigest(lang string) *DigestEntry
+}
+
+// DigestNotifiable is a notifiable which may want to receive mail notifications as a digest.
+type DigestNotifiable interface {
+	WantsDigest() (bool, error)
+}
+
+// queueForDigest saves the notification as digest entry if the notifiable wants a digest.
+// Returns whether the notification was queued. If it was not, the mail has to be sent immediately.
+func queueForDigest(notifiable Notifiable, notification Notification, lang string) (queued bool, err error) {
+	if !config.MailerEnabled.GetBool() {
+		return false, nil
+	}
+
+	dn, is := notifiable.(DigestNotifiable)
+	if !is {
+		return false, nil
+	}
+
+	digestable, is := notification.(DigestableNotification)
+	if !is {
+		return false, nil
+	}
+
+	wantsDigest, err := dn.WantsDigest()
+	if err != nil || !wantsDigest {
+		return false, err
+	}
+
+	entry := digestable.ToDigest(lang)
+	if entry == nil {
+		return false, nil
+	}
+
+	entry.ID = 0
+	entry.NotifiableID = notifiable.RouteForDB()
+	entry.Name = notification.Name()
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err = s.Insert(entry)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetPendingDigestEntries returns all digest entries of the given notifiables which were not sent yet, grouped by
+// notifiable.
+func GetPendingDigestEntries(s *xorm.Session, notifiableIDs []int64) (entries map[int64][]*DigestEntry, err error) {
+	entries = make(map[int64][]*DigestEntry)
+	if len(notifiableIDs) == 0 {
+		return
+	}
+
+	all := []*DigestEntry{}
+	err = s.
+		In("notifiable_id", notifiableIDs).
+		OrderBy("created ASC, id ASC").
+		Find(&all)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range all {
+		entries[e.NotifiableID] = append(entries[e.NotifiableID], e)
+	}
+
+	return
+}
+
+// DeleteDigestEntries removes digest entries after they were sent.
+func DeleteDigestEntries(s *xorm.Session, entries []*DigestEntry) (err error) {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	_, err = s.In("id", ids).Delete(&DigestEntry{})
+	return
+}
@@ -22,12 +22,14 @@ import "code.vikunja.io/api/pkg/mail"
 type Mail struct {
 	from       string
 	to         string
+	replyTo    string
 	subject    string
 	actionText string
 	actionURL  string
 	greeting   string
 	introLines []string
 	outroLines []string
+	language   string
 }
 
 // NewMail creates a new mail object with a default greeting
@@ -47,6 +49,12 @@ func (m *Mail) To(to string) *Mail {
 	return m
 }
 
+// ReplyTo sets the address replies to the mail message should be sent to
+func (m *Mail) ReplyTo(replyTo string) *Mail {
+	m.replyTo = replyTo
+	return m
+}
+
 // Subject sets the subject of the mail message
 func (m *Mail) Subject(subject string) *Mail {
 	m.subject = subject
@@ -78,6 +86,12 @@ func (m *Mail) Line(line string) *Mail {
 	return m
 }
 
+// Language sets the language the parts of the mail which are not set by the notification are rendered in
+func (m *Mail) Language(lang string) *Mail {
+	m.language = lang
+	return m
+}
+
 // SendMail passes the notification to the mailing queue for sending
 func SendMail(m *Mail) error {
 	opts, err := RenderMail(m)
@@ -85,7 +99,5 @@ func SendMail(m *Mail) error {
 		return err
 	}
 
-	mail.SendMail(opts)
-
-	return nil
+	return mail.SendMail(opts)
 }
@@ -19,11 +19,12 @@ package notifications
 import (
 	"bytes"
 	"embed"
-	_ "embed"
 	templatehtml "html/template"
-	templatetext "text/template"
+	"io"
+	"strings"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/mail"
 	"code.vikunja.io/api/pkg/utils"
 
@@ -39,7 +40,11 @@ const mailTemplatePlain = `
 {{ .ActionURL }}{{end}}
 {{ range $line := .OutroLines}}
 {{ $line }}
-{{ end }}`
+{{ end }}{{ if .HasFooter }}
+--
+{{ range $line := .FooterLines }}{{ $line }}
+{{ end }}{{ range $link := .FooterLinks }}{{ $link.Title }}: {{ $link.URL }}
+{{ end }}{{ end }}`
 
 const mailTemplateHTML = `
 <!doctype html>
@@ -47,7 +52,7 @@ const mailTemplateHTML = `
 <head>
     <meta name="viewport" content="width: display-width;">
 </head>
-<body style="width: 100%; padding: 0; margin: 0; background: #f3f4f6">
+<body style="width: 100%; padding: 0; margin: 0; background: {{ .BackgroundColor }}">
 <div style="width: 100%; font-family: 'Open Sans', sans-serif; text-rendering: optimizeLegibility">
     <div style="width: 600px; margin: 0 auto; text-align: justify;">
         <h1 style="font-size: 30px; text-align: center;">
@@ -64,7 +69,7 @@ const mailTemplateHTML = `
 
 {{ if .ActionURL }}
 	<a href="{{ .ActionURL }}" title="{{ .ActionText }}"
-		style="position: relative;text-decoration:none;display: block;border-radius: 4px;cursor: pointer;padding-bottom: 8px;padding-left: 14px;padding-right: 14px;padding-top: 8px;width:280px;margin:10px auto;text-align: center;white-space: nowrap;border: 0;text-transform: uppercase;font-size: 14px;font-weight: 700;-webkit-box-shadow: 0 3px 6px rgba(107,114,128,.12),0 2px 4px rgba(107,114,128,.1);box-shadow: 0 3px 6px rgba(107,114,128,.12),0 2px 4px rgba(107,114,128,.1);background-color: #1973ff;border-color: transparent;color: #fff;">
+		style="position: relative;text-decoration:none;display: block;border-radius: 4px;cursor: pointer;padding-bottom: 8px;padding-left: 14px;padding-right: 14px;padding-top: 8px;width:280px;margin:10px auto;text-align: center;white-space: nowrap;border: 0;text-transform: uppercase;font-size: 14px;font-weight: 700;-webkit-box-shadow: 0 3px 6px rgba(107,114,128,.12),0 2px 4px rgba(107,114,128,.1);box-shadow: 0 3px 6px rgba(107,114,128,.12),0 2px 4px rgba(107,114,128,.1);background-color: {{ .PrimaryColor }};border-color: transparent;color: #fff;">
 		{{ .ActionText }}
 	</a>
 {{end}}
@@ -75,11 +80,15 @@ const mailTemplateHTML = `
 
 {{ if .ActionURL }}
 	<p style="color: #9CA3AF;font-size:12px;border-top: 1px solid #dbdbdb;margin-top:20px;padding-top:20px;">
-		If the button above doesn't work, copy the url below and paste it in your browser's address bar:<br/>
+		{{ .CopyURLText }}<br/>
 		{{ .ActionURL }}
 	</p>
 {{ end }}
-</div>
+</div>{{ if .HasFooter }}
+<div style="color: #9CA3AF; font-size: 12px; text-align: center; padding: 10px 25px;">
+{{ range $line := .FooterHTML }}	{{ $line }}
+{{ end }}{{ if .FooterLinks }}	<p>{{ range $i, $link := .FooterLinks }}{{ if $i }} &middot; {{ end }}<a href="{{ $link.URL }}" style="color: #9CA3AF;">{{ $link.Title }}</a>{{ end }}</p>
+{{ end }}</div>{{ end }}
 </div>
 </div>
 </body>
@@ -89,26 +98,39 @@ const mailTemplateHTML = `
 //go:embed logo.png
 var logo embed.FS
 
-// RenderMail takes a precomposed mail message and renders it into a ready to send mail.Opts object
-func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
-
-	var htmlContent bytes.Buffer
-	var plainContent bytes.Buffer
+// mailFooterLink is a link shown in the footer of every mail.
+type mailFooterLink struct {
+	Title string
+	URL   string
+}
 
-	plain, err := templatetext.New("mail-plain").Parse(mailTemplatePlain)
+// markdownToHTML escapes a line of markdown and converts it to html.
+func markdownToHTML(line string) (templatehtml.HTML, error) {
+	md := []byte(templatehtml.HTMLEscapeString(line))
+	var buf bytes.Buffer
+	err := goldmark.Convert(md, &buf)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	//#nosec - the html is escaped few lines before
+	return templatehtml.HTML(buf.String()), nil
+}
 
-	html, err := templatehtml.New("mail-plain").Parse(mailTemplateHTML)
-	if err != nil {
-		return nil, err
+func markdownLinesToHTML(lines []string) (html []templatehtml.HTML, err error) {
+	for _, line := range lines {
+		converted, err := markdownToHTML(line)
+		if err != nil {
+			return nil, err
+		}
+		html = append(html, converted)
 	}
+	return html, nil
+}
 
-	boundary := "np" + utils.MakeRandomString(13)
-
-	data := make(map[string]interface{})
+func getMailTemplateData(m *Mail, boundary string) (data map[string]interface{}, err error) {
+	data = make(map[string]interface{})
 
+	data["Subject"] = m.subject
 	data["Greeting"] = m.greeting
 	data["IntroLines"] = m.introLines
 	data["OutroLines"] = m.outroLines
@@ -116,38 +138,74 @@ func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
 	data["ActionURL"] = m.actionURL
 	data["Boundary"] = boundary
 	data["FrontendURL"] = config.ServiceFrontendurl.GetString()
+	// Translations are trusted and may contain quotes which should not be escaped
+	data["CopyURLText"] = templatehtml.HTML(i18n.T(m.language, "notifications.common.copy_url"))
+	data["Language"] = m.language
+	data["PrimaryColor"] = config.MailerBrandingPrimaryColor.GetString()
+	data["BackgroundColor"] = config.MailerBrandingBackgroundColor.GetString()
 
-	var introLinesHTML []templatehtml.HTML
-	for _, line := range m.introLines {
-		md := []byte(templatehtml.HTMLEscapeString(line))
-		var buf bytes.Buffer
-		err = goldmark.Convert(md, &buf)
-		if err != nil {
-			return nil, err
-		}
-		//#nosec - the html is escaped few lines before
-		introLinesHTML = append(introLinesHTML, templatehtml.HTML(buf.String()))
+	data["IntroLinesHTML"], err = markdownLinesToHTML(m.introLines)
+	if err != nil {
+		return nil, err
 	}
-	data["IntroLinesHTML"] = introLinesHTML
 
-	var outroLinesHTML []templatehtml.HTML
-	for _, line := range m.outroLines {
-		md := []byte(templatehtml.HTMLEscapeString(line))
-		var buf bytes.Buffer
-		err = goldmark.Convert(md, &buf)
-		if err != nil {
-			return nil, err
-		}
-		//#nosec - the html is escaped few lines before
-		outroLinesHTML = append(outroLinesHTML, templatehtml.HTML(buf.String()))
+	data["OutroLinesHTML"], err = markdownLinesToHTML(m.outroLines)
+	if err != nil {
+		return nil, err
 	}
-	data["OutroLinesHTML"] = outroLinesHTML
 
-	err = plain.Execute(&plainContent, data)
+	var footerLines []string
+	if footer := strings.TrimSpace(config.MailerBrandingFooter.GetString()); footer != "" {
+		footerLines = strings.Split(footer, "\n")
+	}
+	data["FooterLines"] = footerLines
+	data["FooterHTML"], err = markdownLinesToHTML(footerLines)
 	if err != nil {
 		return nil, err
 	}
-	err = html.Execute(&htmlContent, data)
+
+	footerLinks := []*mailFooterLink{}
+	if config.LegalImprintURL.GetString() != "" {
+		footerLinks = append(footerLinks, &mailFooterLink{
+			Title: i18n.T(m.language, "notifications.common.imprint"),
+			URL:   config.LegalImprintURL.GetString(),
+		})
+	}
+	if config.LegalPrivacyURL.GetString() != "" {
+		footerLinks = append(footerLinks, &mailFooterLink{
+			Title: i18n.T(m.language, "notifications.common.privacy_policy"),
+			URL:   config.LegalPrivacyURL.GetString(),
+		})
+	}
+	data["FooterLinks"] = footerLinks
+	data["HasFooter"] = len(footerLines) > 0 || len(footerLinks) > 0
+
+	return data, nil
+}
+
+// RenderMail takes a precomposed mail message and renders it into a ready to send mail.Opts object
+func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
+
+	var htmlContent bytes.Buffer
+	var plainContent bytes.Buffer
+
+	templates, err := getMailTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	boundary := "np" + utils.MakeRandomString(13)
+
+	data, err := getMailTemplateData(m, boundary)
+	if err != nil {
+		return nil, err
+	}
+
+	err = templates.plain.Execute(&plainContent, data)
+	if err != nil {
+		return nil, err
+	}
+	err = templates.html.Execute(&htmlContent, data)
 	if err != nil {
 		return nil, err
 	}
@@ -155,14 +213,22 @@ func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
 	mailOpts = &mail.Opts{
 		From:        m.from,
 		To:          m.to,
+		ReplyTo:     m.replyTo,
 		Subject:     m.subject,
 		ContentType: mail.ContentTypeMultipart,
 		Message:     plainContent.String(),
 		HTMLMessage: htmlContent.String(),
 		Boundary:    boundary,
-		EmbedFS: map[string]*embed.FS{
+	}
+
+	if templates.logo != nil {
+		mailOpts.Embeds = map[string]io.Reader{
+			"logo.png": bytes.NewReader(templates.logo),
+		}
+	} else {
+		mailOpts.EmbedFS = map[string]*embed.FS{
 			"logo.png": &logo,
-		},
+		}
 	}
 
 	return mailOpts, nil
@@ -0,0 +1,205 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	templatehtml "html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	templatetext "text/template"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+)
+
+const (
+	mailTemplateHTMLFile  = "mail.html"
+	mailTemplatePlainFile = "mail.txt"
+	mailLogoFile          = "logo.png"
+)
+
+// mailTemplates holds the layouts all mails are rendered with.
+type mailTemplates struct {
+	plain *templatetext.Template
+	html  *templatehtml.Template
+	// The content of a custom logo. If nil, the built-in logo is used.
+	logo []byte
+}
+
+var (
+	loadedMailTemplates *mailTemplates
+	mailTemplatesLock   sync.RWMutex
+)
+
+var cssColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// InitMailTemplates loads the mail layouts and logo from the configured templates directory and checks that they
+// can be rendered. Every file which does not exist in the directory is replaced with the built-in one.
+func InitMailTemplates() error {
+	templates, err := loadMailTemplates(config.MailerTemplatesDir.GetString())
+	if err != nil {
+		return err
+	}
+
+	err = validateMailTemplates(templates)
+	if err != nil {
+		return err
+	}
+
+	mailTemplatesLock.Lock()
+	loadedMailTemplates = templates
+	mailTemplatesLock.Unlock()
+
+	return nil
+}
+
+func getMailTemplates() (*mailTemplates, error) {
+	mailTemplatesLock.RLock()
+	templates := loadedMailTemplates
+	mailTemplatesLock.RUnlock()
+
+	if templates != nil {
+		return templates, nil
+	}
+
+	// Templates were not initialized, for example in tests or commands which don't do a full init
+	templates, err := loadMailTemplates("")
+	if err != nil {
+		return nil, err
+	}
+
+	mailTemplatesLock.Lock()
+	loadedMailTemplates = templates
+	mailTemplatesLock.Unlock()
+
+	return templates, nil
+}
+
+// readTemplateFile returns the content of a file in the templates directory or the fallback if the directory is
+// not configured or does not contain the file.
+func readTemplateFile(dir, name, fallback string) (content string, custom bool, err error) {
+	if dir == "" {
+		return fallback, false, nil
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fallback, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(raw), true, nil
+}
+
+func loadMailTemplates(dir string) (templates *mailTemplates, err error) {
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("could not open the mail templates directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("the mail templates path %s is not a directory", dir)
+		}
+	}
+
+	templates = &mailTemplates{}
+
+	plain, custom, err := readTemplateFile(dir, mailTemplatePlainFile, mailTemplatePlain)
+	if err != nil {
+		return nil, err
+	}
+	templates.plain, err = templatetext.New("mail-plain").Parse(plain)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the plain text mail template: %w", err)
+	}
+	if custom {
+		log.Infof("Using custom plain text mail template from %s", filepath.Join(dir, mailTemplatePlainFile))
+	}
+
+	html, custom, err := readTemplateFile(dir, mailTemplateHTMLFile, mailTemplateHTML)
+	if err != nil {
+		return nil, err
+	}
+	templates.html, err = templatehtml.New("mail-html").Parse(html)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the html mail template: %w", err)
+	}
+	if custom {
+		log.Infof("Using custom html mail template from %s", filepath.Join(dir, mailTemplateHTMLFile))
+	}
+
+	if dir == "" {
+		return templates, nil
+	}
+
+	logo, err := os.ReadFile(filepath.Join(dir, mailLogoFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return templates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if http.DetectContentType(logo) != "image/png" {
+		return nil, fmt.Errorf("the mail logo %s is not a png image", filepath.Join(dir, mailLogoFile))
+	}
+	templates.logo = logo
+	log.Infof("Using custom mail logo from %s", filepath.Join(dir, mailLogoFile))
+
+	return templates, nil
+}
+
+// validateMailTemplates renders a sample mail with the templates to catch errors which only show up when
+// executing them, like references to data which does not exist.
+func validateMailTemplates(templates *mailTemplates) error {
+	for _, key := range []config.Key{config.MailerBrandingPrimaryColor, config.MailerBrandingBackgroundColor} {
+		if !cssColorRegex.MatchString(key.GetString()) {
+			return fmt.Errorf("%s must be a hex color like #1973ff, got '%s'", key, key.GetString())
+		}
+	}
+
+	sample := NewMail().
+		Subject("Sample").
+		Greeting("Hi there,").
+		Line("This is a line").
+		Action("The action", "https://example.com").
+		Line("This is an outro line")
+
+	data, err := getMailTemplateData(sample, "sample")
+	if err != nil {
+		return err
+	}
+
+	err = templates.plain.Execute(io.Discard, data)
+	if err != nil {
+		return fmt.Errorf("could not render the plain text mail template: %w", err)
+	}
+
+	err = templates.html.Execute(io.Discard, data)
+	if err != nil {
+		return fmt.Errorf("could not render the html mail template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetMailTemplates(t *testing.T) {
+	t.Cleanup(func() {
+		config.MailerTemplatesDir.Set("")
+		config.MailerBrandingPrimaryColor.Set("#1973ff")
+		config.MailerBrandingFooter.Set("")
+		config.LegalImprintURL.Set("")
+		config.LegalPrivacyURL.Set("")
+		mailTemplatesLock.Lock()
+		loadedMailTemplates = nil
+		mailTemplatesLock.Unlock()
+	})
+}
+
+func TestInitMailTemplates(t *testing.T) {
+	t.Run("custom templates", func(t *testing.T) {
+		resetMailTemplates(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, mailTemplateHTMLFile), []byte(`<h1 style="color: {{ .PrimaryColor }}">{{ .Subject }}</h1>{{ range .IntroLinesHTML }}{{ . }}{{ end }}`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, mailTemplatePlainFile), []byte(`** {{ .Subject }} **`), 0o644))
+		config.MailerTemplatesDir.Set(dir)
+		config.MailerBrandingPrimaryColor.Set("#ff0000")
+
+		err := InitMailTemplates()
+		require.NoError(t, err)
+
+		opts, err := RenderMail(NewMail().Subject("Testmail").Line("A **line**"))
+		require.NoError(t, err)
+		assert.Equal(t, "<h1 style=\"color: #ff0000\">Testmail</h1><p>A <strong>line</strong></p>\n", opts.HTMLMessage)
+		assert.Equal(t, "** Testmail **", opts.Message)
+	})
+	t.Run("custom logo", func(t *testing.T) {
+		resetMailTemplates(t)
+		dir := t.TempDir()
+		png, err := logo.ReadFile(mailLogoFile)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, mailLogoFile), png, 0o644))
+		config.MailerTemplatesDir.Set(dir)
+
+		err = InitMailTemplates()
+		require.NoError(t, err)
+
+		opts, err := RenderMail(NewMail().Subject("Testmail"))
+		require.NoError(t, err)
+		assert.Contains(t, opts.Embeds, mailLogoFile)
+		assert.Empty(t, opts.EmbedFS)
+	})
+	t.Run("invalid logo", func(t *testing.T) {
+		resetMailTemplates(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, mailLogoFile), []byte("not a png"), 0o644))
+		config.MailerTemplatesDir.Set(dir)
+
+		err := InitMailTemplates()
+		require.Error(t, err)
+	})
+	t.Run("template syntax error", func(t *testing.T) {
+		resetMailTemplates(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, mailTemplateHTMLFile), []byte(`{{ if .Subject }}`), 0o644))
+		config.MailerTemplatesDir.Set(dir)
+
+		err := InitMailTemplates()
+		require.Error(t, err)
+	})
+	t.Run("template execution error", func(t *testing.T) {
+		resetMailTemplates(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, mailTemplatePlainFile), []byte(`{{ .Subject.Nonexistent }}`), 0o644))
+		config.MailerTemplatesDir.Set(dir)
+
+		err := InitMailTemplates()
+		require.Error(t, err)
+	})
+	t.Run("nonexistent directory", func(t *testing.T) {
+		resetMailTemplates(t)
+		config.MailerTemplatesDir.Set(filepath.Join(t.TempDir(), "nonexistent"))
+
+		err := InitMailTemplates()
+		require.Error(t, err)
+	})
+	t.Run("invalid color", func(t *testing.T) {
+		resetMailTemplates(t)
+		config.MailerBrandingPrimaryColor.Set("red; display: none")
+
+		err := InitMailTemplates()
+		require.Error(t, err)
+	})
+}
+
+func TestRenderMailFooter(t *testing.T) {
+	resetMailTemplates(t)
+	config.MailerBrandingFooter.Set("Vikunja Inc., *Somewhere*")
+	config.LegalImprintURL.Set("https://example.com/imprint")
+	config.LegalPrivacyURL.Set("https://example.com/privacy")
+
+	opts, err := RenderMail(NewMail().Subject("Testmail").Line("Hello").Language("de"))
+	require.NoError(t, err)
+
+	assert.Contains(t, opts.HTMLMessage, "Vikunja Inc., <em>Somewhere</em>")
+	assert.Contains(t, opts.HTMLMessage, `<a href="https://example.com/imprint" style="color: #9CA3AF;">Impressum</a>`)
+	assert.Contains(t, opts.HTMLMessage, `<a href="https://example.com/privacy" style="color: #9CA3AF;">Datenschutzerklärung</a>`)
+	assert.Contains(t, opts.Message, "Vikunja Inc., *Somewhere*")
+	assert.Contains(t, opts.Message, "Impressum: https://example.com/imprint")
+}
@@ -20,16 +20,24 @@ import (
 	"encoding/json"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/realtime"
 )
 
 // Notification is a notification which can be sent via mail or db.
 type Notification interface {
-	ToMail() *Mail
+	ToMail(lang string) *Mail
 	ToDB() interface{}
 	Name() string
 }
 
+// ReplyableNotification is a notification which can be replied to via email.
+type ReplyableNotification interface {
+	// ReplyTo returns the address replies to the mail sent to the notifiable should go to.
+	ReplyTo(notifiable Notifiable) string
+}
+
 type SubjectID interface {
 	SubjectID() int64
 }
@@ -50,6 +58,29 @@ type Notifiable interface {
 	ShouldNotify() (should bool, err error)
 }
 
+// LocalizedNotifiable is a notifiable which wants to receive notifications in a specific language.
+type LocalizedNotifiable interface {
+	// Lang returns the language the notifiable prefers, for example "de-DE".
+	Lang() (string, error)
+}
+
+// getLanguage returns the language notifications for a notifiable should be sent in. Notifiables without a
+// preference get their notifications in the default language.
+func getLanguage(notifiable Notifiable) string {
+	ln, is := notifiable.(LocalizedNotifiable)
+	if !is {
+		return i18n.DefaultLanguage
+	}
+
+	lang, err := ln.Lang()
+	if err != nil {
+		log.Debugf("Could not get the language of notifiable %d, using the default: %s", notifiable.RouteForDB(), err)
+		return i18n.DefaultLanguage
+	}
+
+	return lang
+}
+
 // Notify notifies a notifiable of a notification
 func Notify(notifiable Notifiable, notification Notification) (err error) {
 	if isUnderTest {
@@ -63,19 +94,47 @@ func Notify(notifiable Notifiable, notification Notification) (err error) {
 		return err
 	}
 
-	err = notifyMail(notifiable, notification)
+	sendMail, sendInApp, sendPush, err := getNotificationChannels(notifiable.RouteForDB(), notification)
 	if err != nil {
-		return
+		return err
+	}
+
+	lang := getLanguage(notifiable)
+
+	if sendMail {
+		queued, err := queueForDigest(notifiable, notification, lang)
+		if err != nil {
+			return err
+		}
+
+		if !queued {
+			err = notifyMail(notifiable, notification, lang)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if sendPush {
+		err = notifyPush(notifiable, notification, lang)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !sendInApp {
+		return nil
 	}
 
 	return notifyDB(notifiable, notification)
 }
 
-func notifyMail(notifiable Notifiable, notification Notification) error {
-	mail := notification.ToMail()
+func notifyMail(notifiable Notifiable, notification Notification, lang string) error {
+	mail := notification.ToMail(lang)
 	if mail == nil {
 		return nil
 	}
+	mail.Language(lang)
 
 	to, err := notifiable.RouteForMail()
 	if err != nil {
@@ -83,6 +142,10 @@ func notifyMail(notifiable Notifiable, notification Notification) error {
 	}
 	mail.To(to)
 
+	if replyable, is := notification.(ReplyableNotification); is {
+		mail.ReplyTo(replyable.ReplyTo(notifiable))
+	}
+
 	return SendMail(mail)
 }
 
@@ -115,5 +178,17 @@ func notifyDB(notifiable Notifiable, notification Notification) (err error) {
 		return err
 	}
 
-	return s.Commit()
+	err = s.Commit()
+	if err != nil {
+		return err
+	}
+
+	// Clients of the notifiable are told about the new notification right away
+	dbNotification.Notification = json.RawMessage(content)
+	err = realtime.Publish("notification.created", 0, dbNotification.NotifiableID, dbNotification)
+	if err != nil {
+		log.Errorf("Could not publish realtime event for notification %d: %s", dbNotification.ID, err)
+	}
+
+	return nil
 }
@@ -30,7 +30,7 @@ type testNotification struct {
 }
 
 // ToMail returns the mail notification for testNotification
-func (n *testNotification) ToMail() *Mail {
+func (n *testNotification) ToMail(_ string) *Mail {
 	return NewMail().
 		Subject("Test Notification").
 		Line(n.Test)
@@ -49,6 +49,15 @@ func (n *testNotification) Name() string {
 	return "test.notification"
 }
 
+type testProjectNotification struct {
+	testNotification
+	Project int64
+}
+
+func (n *testProjectNotification) ProjectID() int64 {
+	return n.Project
+}
+
 type testNotifiable struct {
 	ShouldSendNotification bool
 }
@@ -123,4 +132,82 @@ func TestNotify(t *testing.T) {
 			"notifiable_id": 42,
 		})
 	})
+	t.Run("disabled via preference", func(t *testing.T) {
+
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Exec("delete from notifications")
+		assert.NoError(t, err)
+		_, err = s.Exec("delete from notification_preferences")
+		assert.NoError(t, err)
+		_, err = s.Insert(&NotificationPreference{
+			NotifiableID: 42,
+			Name:         "test.notification",
+			Mail:         true,
+			InApp:        false,
+		})
+		assert.NoError(t, err)
+
+		tn := &testNotification{
+			Test:       "somethingsomething",
+			OtherValue: 42,
+		}
+		tnf := &testNotifiable{
+			ShouldSendNotification: true,
+		}
+
+		err = Notify(tnf, tn)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "notifications", map[string]interface{}{
+			"notifiable_id": 42,
+		})
+	})
+	t.Run("enabled via project preference", func(t *testing.T) {
+
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Exec("delete from notifications")
+		assert.NoError(t, err)
+		_, err = s.Exec("delete from notification_preferences")
+		assert.NoError(t, err)
+		_, err = s.Insert(&NotificationPreference{
+			NotifiableID: 42,
+			Name:         "test.notification",
+			Mail:         false,
+			InApp:        false,
+		})
+		assert.NoError(t, err)
+		_, err = s.Insert(&NotificationPreference{
+			NotifiableID: 42,
+			Name:         "test.notification",
+			ProjectID:    3,
+			Mail:         false,
+			InApp:        true,
+		})
+		assert.NoError(t, err)
+
+		tn := &testProjectNotification{
+			testNotification: testNotification{
+				Test:       "somethingsomething",
+				OtherValue: 42,
+			},
+			Project: 3,
+		}
+		tnf := &testNotifiable{
+			ShouldSendNotification: true,
+		}
+
+		err = Notify(tnf, tn)
+		assert.NoError(t, err)
+		db.AssertExists(t, "notifications", map[string]interface{}{
+			"notifiable_id": 42,
+			"name":          "test.notification",
+		}, false)
+
+		mail, inApp, push, err := getNotificationChannels(42, &tn.testNotification)
+		assert.NoError(t, err)
+		assert.False(t, mail)
+		assert.False(t, inApp)
+		assert.False(t, push)
+	})
 }
@@ -0,0 +1,158 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"xorm.io/xorm"
+)
+
+// NotificationPreference defines through which channels a notifiable wants to receive a notification.
+// A preference with a project id overrides the global preference for the same notification.
+type NotificationPreference struct {
+	// The unique, numeric id of this preference.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"preference"`
+
+	// The ID of the notifiable this preference belongs to.
+	NotifiableID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The name of the notification this preference is for, for example task.comment.
+	Name string `xorm:"varchar(250) not null INDEX" json:"name"`
+	// The project this preference applies to. If 0, the preference applies to all projects unless a project has its own preference.
+	ProjectID int64 `xorm:"bigint not null default 0 INDEX" json:"project_id"`
+
+	// Whether to send this notification via mail.
+	Mail bool `xorm:"not null default true" json:"mail"`
+	// Whether to show this notification in the app.
+	InApp bool `xorm:"not null default true" json:"in_app"`
+	// Whether to send this notification as a push message to all push targets.
+	Push bool `xorm:"not null default true" json:"push"`
+
+	// A timestamp when this preference was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this preference was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+}
+
+// TableName resolves to a better table name for notification preferences
+func (n *NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// NotificationWithProject is a notification which is about something in a project.
+// Used to look up project specific notification preferences.
+type NotificationWithProject interface {
+	ProjectID() int64
+}
+
+// GetNotificationPreferencesForNotifiable returns all notification preferences of a notifiable.
+func GetNotificationPreferencesForNotifiable(s *xorm.Session, notifiableID int64) (preferences []*NotificationPreference, err error) {
+	preferences = []*NotificationPreference{}
+	err = s.
+		Where("notifiable_id = ?", notifiableID).
+		OrderBy("project_id ASC, name ASC").
+		Find(&preferences)
+	return
+}
+
+// GetNotificationPreference returns the stored preference of a notifiable for a notification and project.
+// Returns nil if there is none.
+func GetNotificationPreference(s *xorm.Session, notifiableID int64, name string, projectID int64) (preference *NotificationPreference, err error) {
+	preference = &NotificationPreference{}
+	exists, err := s.
+		Where("notifiable_id = ? AND name = ? AND project_id = ?", notifiableID, name, projectID).
+		Get(preference)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// SaveNotificationPreference creates or updates the preference of a notifiable for a notification and project.
+func SaveNotificationPreference(s *xorm.Session, preference *NotificationPreference) (err error) {
+	existing, err := GetNotificationPreference(s, preference.NotifiableID, preference.Name, preference.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		preference.ID = 0
+		_, err = s.Insert(preference)
+		return err
+	}
+
+	preference.ID = existing.ID
+	preference.Created = existing.Created
+	_, err = s.
+		Where("id = ?", preference.ID).
+		Cols("mail", "in_app", "push").
+		Update(preference)
+	return err
+}
+
+// DeleteNotificationPreference removes a preference of a notifiable. Afterwards, the global preference or the
+// default will be used again.
+func DeleteNotificationPreference(s *xorm.Session, notifiableID, preferenceID int64) (deleted bool, err error) {
+	count, err := s.
+		Where("id = ? AND notifiable_id = ?", preferenceID, notifiableID).
+		Delete(&NotificationPreference{})
+	return count > 0, err
+}
+
+// getNotificationChannels returns through which channels a notification should be sent to a notifiable.
+// Notifications without a name cannot be configured and are always sent through all channels.
+func getNotificationChannels(notifiableID int64, notification Notification) (mail, inApp, push bool, err error) {
+	mail, inApp, push = true, true, true
+
+	name := notification.Name()
+	if name == "" {
+		return
+	}
+
+	var projectID int64
+	if n, is := notification.(NotificationWithProject); is {
+		projectID = n.ProjectID()
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	preferences := []*NotificationPreference{}
+	err = s.
+		Where("notifiable_id = ? AND name = ?", notifiableID, name).
+		In("project_id", []int64{0, projectID}).
+		Find(&preferences)
+	if err != nil {
+		return
+	}
+
+	for _, p := range preferences {
+		if p.ProjectID == 0 {
+			mail, inApp, push = p.Mail, p.InApp, p.Push
+		}
+	}
+	// A project specific preference always wins over the global one
+	for _, p := range preferences {
+		if p.ProjectID != 0 && p.ProjectID == projectID {
+			mail, inApp, push = p.Mail, p.InApp, p.Push
+		}
+	}
+
+	return
+}
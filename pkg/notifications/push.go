@@ -0,0 +1,304 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+
+	"xorm.io/xorm"
+)
+
+// PushMessage is the content of a push notification.
+type PushMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// The url which should be opened when clicking the notification.
+	URL string `json:"url"`
+}
+
+// PushNotification is a notification which can be sent as a push message.
+type PushNotification interface {
+	// ToPush returns the push message for a notification. If it returns nil, no push message is sent.
+	ToPush(lang string) *PushMessage
+}
+
+// The types of push targets
+const (
+	PushTargetTypeWebPush = "webpush"
+	PushTargetTypeNtfy    = "ntfy"
+	PushTargetTypeGotify  = "gotify"
+)
+
+// PushTarget is a device or service a notifiable receives push notifications on.
+type PushTarget struct {
+	// The unique, numeric id of this push target.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"pushtarget"`
+
+	// The ID of the notifiable this push target belongs to.
+	NotifiableID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The type of this push target. Can be `webpush`, `ntfy` or `gotify`.
+	Type string `xorm:"varchar(20) not null" json:"type"`
+	// A name to recognize this push target, for example the name of the device.
+	Title string `xorm:"varchar(250) null" json:"title"`
+
+	// For web push, the endpoint of the push subscription. For ntfy and gotify, the url of the server.
+	// If empty for ntfy, the configured default ntfy server is used.
+	URL string `xorm:"text null" json:"url"`
+	// The ntfy topic to publish to.
+	Topic string `xorm:"varchar(250) null" json:"topic"`
+	// The ntfy access token or the gotify application token. Will never be returned.
+	Token string `xorm:"text null" json:"token,omitempty"`
+	// The p256dh key of a web push subscription.
+	P256dh string `xorm:"text null" json:"p256dh"`
+	// The auth secret of a web push subscription. Will never be returned.
+	Auth string `xorm:"text null" json:"auth,omitempty"`
+
+	// A timestamp when this push target was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName resolves to a better table name for push targets
+func (p *PushTarget) TableName() string {
+	return "push_targets"
+}
+
+// PushChannel delivers push messages to one type of push target.
+type PushChannel interface {
+	// Type returns the type of push targets this channel can deliver to.
+	Type() string
+	// Enabled returns whether this channel is configured and can be used.
+	Enabled() bool
+	// Validate checks if all information this channel needs is present on a push target.
+	Validate(target *PushTarget) error
+	// Send delivers a push message to a push target.
+	Send(target *PushTarget, message *PushMessage) error
+}
+
+var pushChannels = make(map[string]PushChannel)
+
+// RegisterPushChannel makes a push channel available to deliver push messages.
+func RegisterPushChannel(channel PushChannel) {
+	pushChannels[channel.Type()] = channel
+}
+
+// GetPushChannel returns the enabled push channel for a push target type or nil if there is none.
+func GetPushChannel(targetType string) PushChannel {
+	if !config.PushEnabled.GetBool() {
+		return nil
+	}
+
+	channel, has := pushChannels[targetType]
+	if !has || !channel.Enabled() {
+		return nil
+	}
+
+	return channel
+}
+
+// ErrPushTargetGone is returned by push channels if the push target does not exist anymore, for example because a
+// web push subscription expired. Push targets are removed if sending to them results in this error.
+type ErrPushTargetGone struct {
+	StatusCode int
+}
+
+func (err *ErrPushTargetGone) Error() string {
+	return fmt.Sprintf("Push target does not exist anymore [Status: %d]", err.StatusCode)
+}
+
+// GetEnabledPushTargetTypes returns the types of all push targets which can currently be used.
+func GetEnabledPushTargetTypes() (types []string) {
+	types = []string{}
+	for _, t := range []string{PushTargetTypeWebPush, PushTargetTypeNtfy, PushTargetTypeGotify} {
+		if GetPushChannel(t) != nil {
+			types = append(types, t)
+		}
+	}
+	return
+}
+
+func init() {
+	RegisterPushChannel(&webPushChannel{})
+	RegisterPushChannel(&ntfyChannel{})
+	RegisterPushChannel(&gotifyChannel{})
+}
+
+// GetPushTargetsForNotifiable returns all push targets of a notifiable.
+func GetPushTargetsForNotifiable(s *xorm.Session, notifiableID int64) (targets []*PushTarget, err error) {
+	targets = []*PushTarget{}
+	err = s.
+		Where("notifiable_id = ?", notifiableID).
+		OrderBy("id ASC").
+		Find(&targets)
+	return
+}
+
+// DeletePushTarget removes a push target of a notifiable.
+func DeletePushTarget(s *xorm.Session, notifiableID, targetID int64) (deleted bool, err error) {
+	count, err := s.
+		Where("id = ? AND notifiable_id = ?", targetID, notifiableID).
+		Delete(&PushTarget{})
+	return count > 0, err
+}
+
+func notifyPush(notifiable Notifiable, notification Notification, lang string) (err error) {
+	pn, is := notification.(PushNotification)
+	if !is || !config.PushEnabled.GetBool() {
+		return nil
+	}
+
+	message := pn.ToPush(lang)
+	if message == nil {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	targets, err := GetPushTargetsForNotifiable(s, notifiable.RouteForDB())
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		channel := GetPushChannel(target.Type)
+		if channel == nil {
+			continue
+		}
+
+		// A failing push target should not prevent delivery to the others
+		err = channel.Send(target, message)
+		if err == nil {
+			continue
+		}
+
+		if _, gone := err.(*ErrPushTargetGone); gone {
+			log.Debugf("Removing push target %d of notifiable %d because it does not exist anymore", target.ID, target.NotifiableID)
+			_, err = DeletePushTarget(s, target.NotifiableID, target.ID)
+			if err != nil {
+				log.Errorf("Could not remove push target %d: %s", target.ID, err)
+			}
+			continue
+		}
+
+		log.Errorf("Could not send push notification %s to push target %d: %s", notification.Name(), target.ID, err)
+	}
+
+	return nil
+}
+
+// ErrPushAddressNotAllowed is returned when a push target points to an address in a private network.
+var ErrPushAddressNotAllowed = errors.New("push target address is not allowed")
+
+// isPublicIP checks whether an ip address can be reached from the internet and is not the host Vikunja runs on.
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// isAllowedPushHost checks whether the admin allowed push requests to a host even if it is in a private network.
+func isAllowedPushHost(host string) bool {
+	if defaultServer, err := url.Parse(config.PushNtfyDefaultServer.GetString()); err == nil &&
+		defaultServer.Hostname() != "" && strings.EqualFold(defaultServer.Hostname(), host) {
+		return true
+	}
+
+	for _, allowed := range config.PushAllowedHosts.GetStringSlice() {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// newPushHTTPClient returns a http client for requests to a push target. Unless the host of the push target is
+// allowed in the config, it refuses to connect to private addresses. The check happens when connecting so that
+// host names resolving to a private address are caught as well. Redirects are not followed.
+func newPushHTTPClient(host string) *http.Client {
+	hc := &http.Client{
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if isAllowedPushHost(host) {
+		return hc
+	}
+
+	dialer := &net.Dialer{
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ipString, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(ipString)
+			if ip == nil || !isPublicIP(ip) {
+				return ErrPushAddressNotAllowed
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	hc.Transport = transport
+	return hc
+}
+
+// postPushRequest sends a request to a push service. It returns an error for unsuccessful status codes.
+func postPushRequest(url string, body []byte, headers map[string]string) (statusCode int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.PushTimeout.GetInt())*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	hc := newPushHTTPClient(req.URL.Hostname())
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("push service returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return resp.StatusCode, nil
+}
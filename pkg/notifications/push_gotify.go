@@ -0,0 +1,79 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+)
+
+// gotifyChannel sends push messages to a gotify server using an application token. See https://gotify.net
+type gotifyChannel struct{}
+
+type gotifyMessage struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+func (c *gotifyChannel) Type() string {
+	return PushTargetTypeGotify
+}
+
+func (c *gotifyChannel) Enabled() bool {
+	return config.PushGotifyEnabled.GetBool()
+}
+
+func (c *gotifyChannel) Validate(target *PushTarget) error {
+	if target.URL == "" {
+		return errors.New("a server url is required for gotify")
+	}
+	if target.Token == "" {
+		return errors.New("an application token is required for gotify")
+	}
+	return nil
+}
+
+func (c *gotifyChannel) Send(target *PushTarget, message *PushMessage) error {
+	msg := &gotifyMessage{
+		Title:    message.Title,
+		Message:  message.Body,
+		Priority: 5,
+	}
+	if message.URL != "" {
+		msg.Extras = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": message.URL},
+			},
+		}
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = postPushRequest(strings.TrimSuffix(target.URL, "/")+"/message", body, map[string]string{
+		"Content-Type": "application/json",
+		"X-Gotify-Key": target.Token,
+	})
+	return err
+}
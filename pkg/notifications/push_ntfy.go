@@ -0,0 +1,81 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+)
+
+// ntfyChannel publishes push messages to a topic on an ntfy server. See https://ntfy.sh
+type ntfyChannel struct{}
+
+type ntfyMessage struct {
+	Topic   string `json:"topic"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Click   string `json:"click,omitempty"`
+}
+
+func (c *ntfyChannel) Type() string {
+	return PushTargetTypeNtfy
+}
+
+func (c *ntfyChannel) Enabled() bool {
+	return config.PushNtfyEnabled.GetBool()
+}
+
+func (c *ntfyChannel) Validate(target *PushTarget) error {
+	if target.Topic == "" {
+		return errors.New("a topic is required for ntfy")
+	}
+	if target.URL == "" && config.PushNtfyDefaultServer.GetString() == "" {
+		return errors.New("a server url is required for ntfy")
+	}
+	return nil
+}
+
+func (c *ntfyChannel) Send(target *PushTarget, message *PushMessage) error {
+	server := target.URL
+	if server == "" {
+		server = config.PushNtfyDefaultServer.GetString()
+	}
+
+	// Publishing json messages needs to happen against the root url of the server
+	body, err := json.Marshal(&ntfyMessage{
+		Topic:   target.Topic,
+		Title:   message.Title,
+		Message: message.Body,
+		Click:   message.URL,
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if target.Token != "" {
+		headers["Authorization"] = "Bearer " + target.Token
+	}
+
+	_, err = postPushRequest(strings.TrimSuffix(server, "/"), body, headers)
+	return err
+}
@@ -0,0 +1,273 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPushNotification struct {
+	testNotification
+}
+
+func (n *testPushNotification) ToPush(_ string) *PushMessage {
+	return &PushMessage{
+		Title: "Test Notification",
+		Body:  n.Test,
+		URL:   "https://example.com/tasks/1",
+	}
+}
+
+// decryptWebPushPayload does what a browser does when receiving a web push message.
+func decryptWebPushPayload(t *testing.T, uaPrivate *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	salt := body[:16]
+	idLen := int(body[20])
+	asPublicRaw := body[21 : 21+idLen]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	assert.NoError(t, err)
+	ecdhSecret, err := uaPrivate.ECDH(asPublic)
+	assert.NoError(t, err)
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicRaw...)
+	ikm, err := hkdfExpand(ecdhSecret, authSecret, keyInfo, 32)
+	assert.NoError(t, err)
+	cek, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	assert.NoError(t, err)
+	nonce, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	assert.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	assert.NoError(t, err)
+
+	// Strip the padding delimiter
+	assert.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestPushChannels(t *testing.T) {
+	message := &PushMessage{
+		Title: "Reminder",
+		Body:  "Do the thing",
+		URL:   "https://example.com/tasks/1",
+	}
+
+	t.Run("ntfy", func(t *testing.T) {
+		var body map[string]interface{}
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}))
+		defer server.Close()
+
+		target := &PushTarget{Type: PushTargetTypeNtfy, URL: server.URL, Topic: "vikunja", Token: "tk_secret"}
+		channel := GetPushChannel(PushTargetTypeNtfy)
+		assert.NoError(t, channel.Validate(target))
+		err := channel.Send(target, message)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer tk_secret", authorization)
+		assert.Equal(t, "vikunja", body["topic"])
+		assert.Equal(t, "Reminder", body["title"])
+		assert.Equal(t, "Do the thing", body["message"])
+		assert.Equal(t, "https://example.com/tasks/1", body["click"])
+	})
+	t.Run("ntfy without topic", func(t *testing.T) {
+		err := GetPushChannel(PushTargetTypeNtfy).Validate(&PushTarget{Type: PushTargetTypeNtfy})
+		assert.Error(t, err)
+	})
+	t.Run("gotify", func(t *testing.T) {
+		var body map[string]interface{}
+		var path, key string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			key = r.Header.Get("X-Gotify-Key")
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}))
+		defer server.Close()
+
+		target := &PushTarget{Type: PushTargetTypeGotify, URL: server.URL + "/", Token: "apptoken"}
+		channel := GetPushChannel(PushTargetTypeGotify)
+		assert.NoError(t, channel.Validate(target))
+		err := channel.Send(target, message)
+		assert.NoError(t, err)
+		assert.Equal(t, "/message", path)
+		assert.Equal(t, "apptoken", key)
+		assert.Equal(t, "Reminder", body["title"])
+		assert.Equal(t, "Do the thing", body["message"])
+	})
+	t.Run("gotify error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		target := &PushTarget{Type: PushTargetTypeGotify, URL: server.URL, Token: "wrong"}
+		err := GetPushChannel(PushTargetTypeGotify).Send(target, message)
+		assert.Error(t, err)
+		_, gone := err.(*ErrPushTargetGone)
+		assert.False(t, gone)
+	})
+	t.Run("private address", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		config.PushAllowedHosts.Set([]string{})
+		defer config.PushAllowedHosts.Set([]string{"127.0.0.1"})
+
+		target := &PushTarget{Type: PushTargetTypeGotify, URL: server.URL, Token: "apptoken"}
+		err := GetPushChannel(PushTargetTypeGotify).Send(target, message)
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrPushAddressNotAllowed)
+		assert.False(t, called)
+	})
+	t.Run("web push disabled without keys", func(t *testing.T) {
+		assert.Nil(t, GetPushChannel(PushTargetTypeWebPush))
+	})
+	t.Run("web push", func(t *testing.T) {
+		publicKey, privateKey, err := GenerateVAPIDKeys()
+		assert.NoError(t, err)
+		config.PushWebPushEnabled.Set(true)
+		config.PushWebPushPublicKey.Set(publicKey)
+		config.PushWebPushPrivateKey.Set(privateKey)
+		config.PushWebPushSubject.Set("mailto:admin@example.com")
+		defer func() {
+			config.PushWebPushEnabled.Set(false)
+			config.PushWebPushPublicKey.Set("")
+			config.PushWebPushPrivateKey.Set("")
+		}()
+
+		uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		authSecret := make([]byte, 16)
+		_, err = rand.Read(authSecret)
+		assert.NoError(t, err)
+
+		var received []byte
+		var headers http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+			received, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		target := &PushTarget{
+			Type:   PushTargetTypeWebPush,
+			URL:    server.URL + "/push/abc",
+			P256dh: base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes()),
+			Auth:   base64.URLEncoding.EncodeToString(authSecret),
+		}
+		channel := GetPushChannel(PushTargetTypeWebPush)
+		assert.NotNil(t, channel)
+		assert.NoError(t, channel.Validate(target))
+
+		err = channel.Send(target, message)
+		assert.NoError(t, err)
+		assert.Equal(t, "aes128gcm", headers.Get("Content-Encoding"))
+		assert.True(t, strings.HasPrefix(headers.Get("Authorization"), "vapid t="))
+		assert.True(t, strings.HasSuffix(headers.Get("Authorization"), ", k="+publicKey))
+
+		decrypted := decryptWebPushPayload(t, uaPrivate, authSecret, received)
+		got := &PushMessage{}
+		err = json.Unmarshal(decrypted, got)
+		assert.NoError(t, err)
+		assert.Equal(t, message, got)
+	})
+}
+
+func TestNotifyPush(t *testing.T) {
+	t.Run("removes gone targets", func(t *testing.T) {
+		publicKey, privateKey, err := GenerateVAPIDKeys()
+		assert.NoError(t, err)
+		config.PushWebPushEnabled.Set(true)
+		config.PushWebPushPublicKey.Set(publicKey)
+		config.PushWebPushPrivateKey.Set(privateKey)
+		defer func() {
+			config.PushWebPushEnabled.Set(false)
+			config.PushWebPushPublicKey.Set("")
+			config.PushWebPushPrivateKey.Set("")
+		}()
+
+		ntfyCalls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/push") {
+				w.WriteHeader(http.StatusGone)
+				return
+			}
+			ntfyCalls++
+		}))
+		defer server.Close()
+
+		uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+
+		s := db.NewSession()
+		defer s.Close()
+		_, err = s.Exec("delete from push_targets")
+		assert.NoError(t, err)
+		webPushTarget := &PushTarget{
+			NotifiableID: 42,
+			Type:         PushTargetTypeWebPush,
+			URL:          server.URL + "/push/expired",
+			P256dh:       base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes()),
+			Auth:         base64.RawURLEncoding.EncodeToString(make([]byte, 16)),
+		}
+		ntfyTarget := &PushTarget{
+			NotifiableID: 42,
+			Type:         PushTargetTypeNtfy,
+			URL:          server.URL,
+			Topic:        "vikunja",
+		}
+		_, err = s.Insert(webPushTarget, ntfyTarget)
+		assert.NoError(t, err)
+
+		tn := &testPushNotification{testNotification: testNotification{Test: "somethingsomething"}}
+		err = notifyPush(&testNotifiable{ShouldSendNotification: true}, tn, "en")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, ntfyCalls)
+		db.AssertMissing(t, "push_targets", map[string]interface{}{
+			"id": webPushTarget.ID,
+		})
+		db.AssertExists(t, "push_targets", map[string]interface{}{
+			"id": ntfyTarget.ID,
+		}, false)
+	})
+}
@@ -0,0 +1,252 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+// webPushChannel sends push messages to browsers using the web push protocol (RFC 8030),
+// encrypting them as defined in RFC 8291 and authenticating with VAPID (RFC 8292).
+type webPushChannel struct{}
+
+const webPushRecordSize = 4096
+
+func (c *webPushChannel) Type() string {
+	return PushTargetTypeWebPush
+}
+
+func (c *webPushChannel) Enabled() bool {
+	return config.PushWebPushEnabled.GetBool() &&
+		config.PushWebPushPublicKey.GetString() != "" &&
+		config.PushWebPushPrivateKey.GetString() != ""
+}
+
+func (c *webPushChannel) Validate(target *PushTarget) error {
+	endpoint, err := url.Parse(target.URL)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return errors.New("the endpoint of a web push subscription must be a valid url")
+	}
+	key, err := decodeWebPushKey(target.P256dh)
+	if err != nil {
+		return errors.New("the p256dh key of the web push subscription is invalid")
+	}
+	if _, err := ecdh.P256().NewPublicKey(key); err != nil {
+		return errors.New("the p256dh key of the web push subscription is invalid")
+	}
+	auth, err := decodeWebPushKey(target.Auth)
+	if err != nil || len(auth) != 16 {
+		return errors.New("the auth secret of the web push subscription is invalid")
+	}
+	return nil
+}
+
+func (c *webPushChannel) Send(target *PushTarget, message *PushMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	body, err := encryptWebPushPayload(target, payload)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := getVAPIDAuthorization(target.URL)
+	if err != nil {
+		return err
+	}
+
+	statusCode, err := postPushRequest(target.URL, body, map[string]string{
+		"Authorization":    authorization,
+		"Content-Encoding": "aes128gcm",
+		"Content-Type":     "application/octet-stream",
+		"TTL":              "86400",
+		"Urgency":          "normal",
+	})
+	if statusCode == http.StatusNotFound || statusCode == http.StatusGone {
+		return &ErrPushTargetGone{StatusCode: statusCode}
+	}
+	return err
+}
+
+// decodeWebPushKey decodes keys from browsers and the config which are base64 url encoded, with or without padding.
+func decodeWebPushKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
+
+func hkdfExpand(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	_, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out)
+	return out, err
+}
+
+// encryptWebPushPayload encrypts a payload for a subscription with the aes128gcm content coding (RFC 8291).
+func encryptWebPushPayload(target *PushTarget, payload []byte) ([]byte, error) {
+	rawUAPublic, err := decodeWebPushKey(target.P256dh)
+	if err != nil {
+		return nil, err
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(rawUAPublic)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeWebPushKey(target.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every message is encrypted with a new key pair of the application server
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), rawUAPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdfExpand(ecdhSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole payload is sent as a single record, terminated by the last record delimiter
+	if len(payload)+1+gcm.Overhead() > webPushRecordSize {
+		return nil, errors.New("web push payload is too large")
+	}
+	plaintext := append(payload, 0x02) //nolint:gocritic
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// getVAPIDPrivateKey returns the configured VAPID private key and its public key in uncompressed form.
+func getVAPIDPrivateKey() (key *ecdsa.PrivateKey, public []byte, err error) {
+	rawPrivate, err := decodeWebPushKey(config.PushWebPushPrivateKey.GetString())
+	if err != nil {
+		return nil, nil, err
+	}
+	private, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The public key is in uncompressed form: 0x04 || x || y
+	public = private.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(rawPrivate),
+	}, public, nil
+}
+
+// getVAPIDAuthorization creates the authorization header for a web push request (RFC 8292).
+func getVAPIDAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	key, public, err := getVAPIDPrivateKey()
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+	}
+	if subject := config.PushWebPushSubject.GetString(); subject != "" {
+		claims["sub"] = subject
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	return "vapid t=" + token + ", k=" + base64.RawURLEncoding.EncodeToString(public), nil
+}
+
+// GenerateVAPIDKeys creates a new key pair to use for web push. Both keys are base64 url encoded.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		nil
+}
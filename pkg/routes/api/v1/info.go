@@ -27,6 +27,8 @@ import (
 	"code.vikunja.io/api/pkg/modules/migration/todoist"
 	"code.vikunja.io/api/pkg/modules/migration/trello"
 	vikunja_file "code.vikunja.io/api/pkg/modules/migration/vikunja-file"
+	"code.vikunja.io/api/pkg/modules/realtime"
+	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/version"
 
 	"github.com/labstack/echo/v4"
@@ -37,6 +39,7 @@ type vikunjaInfos struct {
 	FrontendURL                string    `json:"frontend_url"`
 	Motd                       string    `json:"motd"`
 	LinkSharingEnabled         bool      `json:"link_sharing_enabled"`
+	PublicProjectsEnabled      bool      `json:"public_projects_enabled"`
 	MaxFileSize                string    `json:"max_file_size"`
 	RegistrationEnabled        bool      `json:"registration_enabled"`
 	AvailableMigrators         []string  `json:"available_migrators"`
@@ -50,6 +53,14 @@ type vikunjaInfos struct {
 	UserDeletionEnabled        bool      `json:"user_deletion_enabled"`
 	TaskCommentsEnabled        bool      `json:"task_comments_enabled"`
 	DemoModeEnabled            bool      `json:"demo_mode_enabled"`
+	RealtimeEnabled            bool      `json:"realtime_enabled"`
+	InboundEmailEnabled        bool      `json:"inbound_email_enabled"`
+	Push                       pushInfo  `json:"push"`
+}
+
+type pushInfo struct {
+	EnabledTypes     []string `json:"enabled_types"`
+	WebPushPublicKey string   `json:"webpush_public_key"`
 }
 
 type authInfo struct {
@@ -85,6 +96,7 @@ func Info(c echo.Context) error {
 		FrontendURL:            config.ServiceFrontendurl.GetString(),
 		Motd:                   config.ServiceMotd.GetString(),
 		LinkSharingEnabled:     config.ServiceEnableLinkSharing.GetBool(),
+		PublicProjectsEnabled:  config.ServiceEnablePublicProjects.GetBool(),
 		MaxFileSize:            config.FilesMaxSize.GetString(),
 		RegistrationEnabled:    config.ServiceEnableRegistration.GetBool(),
 		TaskAttachmentsEnabled: config.ServiceEnableTaskAttachments.GetBool(),
@@ -94,10 +106,15 @@ func Info(c echo.Context) error {
 		UserDeletionEnabled:    config.ServiceEnableUserDeletion.GetBool(),
 		TaskCommentsEnabled:    config.ServiceEnableTaskComments.GetBool(),
 		DemoModeEnabled:        config.ServiceDemoMode.GetBool(),
+		RealtimeEnabled:        realtime.Enabled(),
+		InboundEmailEnabled:    config.InboundEnabled.GetBool() && config.InboundDomain.GetString() != "",
 		AvailableMigrators: []string{
 			(&vikunja_file.FileMigrator{}).Name(),
 			(&ticktick.Migrator{}).Name(),
 		},
+		Push: pushInfo{
+			EnabledTypes: notifications.GetEnabledPushTargetTypes(),
+		},
 		Legal: legalInfo{
 			ImprintURL:       config.LegalImprintURL.GetString(),
 			PrivacyPolicyURL: config.LegalPrivacyURL.GetString(),
@@ -113,6 +130,10 @@ func Info(c echo.Context) error {
 		},
 	}
 
+	if notifications.GetPushChannel(notifications.PushTargetTypeWebPush) != nil {
+		info.Push.WebPushPublicKey = config.PushWebPushPublicKey.GetString()
+	}
+
 	providers, err := openid.GetAllProviders()
 	if err != nil {
 		log.Errorf("Error while getting openid providers for /info: %s", err)
@@ -50,6 +50,7 @@ type LinkShareAuth struct {
 // @Param share path string true "The share hash"
 // @Success 200 {object} auth.Token "The valid jwt auth token."
 // @Failure 400 {object} web.HTTPError "Invalid link share object provided."
+// @Failure 403 {object} web.HTTPError "The link share is disabled, expired or has reached its maximum number of uses."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /shares/{share}/auth [post]
 func AuthenticateLinkShare(c echo.Context) error {
@@ -62,6 +63,11 @@ func AuthenticateLinkShare(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
+	err = s.Begin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
 	share, err := models.GetLinkShareByHash(s, sh.Hash)
 	if err != nil {
 		return handler.HandleHTTPError(err, c)
@@ -74,8 +80,20 @@ func AuthenticateLinkShare(c echo.Context) error {
 		}
 	}
 
+	err = models.RecordLinkShareAccess(s, share, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
 	t, err := auth.NewLinkShareJWTAuthtoken(share)
 	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
 	}
 
@@ -0,0 +1,235 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+var publicProjectTemplate = template.Must(template.New("public_project").Funcs(template.FuncMap{
+	"formatDate": func(t time.Time) string {
+		return t.In(config.GetTimeZone()).Format("2006-01-02")
+	},
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{ .Project.Title }}</title>
+	<link rel="alternate" type="application/atom+xml" title="{{ .Project.Title }}" href="{{ .FeedURL }}">
+	<style>
+		body { font-family: sans-serif; margin: 2rem; color: #222; background: #f5f5f5; }
+		.buckets { display: flex; gap: 1rem; align-items: flex-start; overflow-x: auto; }
+		.bucket { background: #fff; border-radius: 4px; padding: 0 1rem 1rem; min-width: 16rem; }
+		.task { border-top: 1px solid #eee; padding: .5rem 0; }
+		.done .title { text-decoration: line-through; color: #888; }
+		.meta { font-size: .85rem; color: #666; }
+	</style>
+</head>
+<body>
+	<h1>{{ .Project.Title }}</h1>
+	<p><a href="{{ .FeedURL }}">Atom feed</a></p>
+	<div class="buckets">
+	{{- range .Buckets }}
+		<section class="bucket">
+			<h2>{{ if .Title }}{{ .Title }}{{ else }}Other{{ end }}</h2>
+			{{- range .Tasks }}
+			<div class="task{{ if .Done }} done{{ end }}" id="task-{{ .ID }}">
+				<div class="title">{{ .Identifier }} {{ .Title }}</div>
+				<div class="meta">
+					{{- if .Done }}Done{{ if not .DoneAt.IsZero }} on {{ formatDate .DoneAt }}{{ end }}{{ else if not .DueDate.IsZero }}Due {{ formatDate .DueDate }}{{ end }}
+					{{- if .Assignees }} &middot; {{ join .Assignees ", " }}{{ end }}
+				</div>
+			</div>
+			{{- end }}
+		</section>
+	{{- end }}
+	</div>
+</body>
+</html>
+`))
+
+type publicProjectPage struct {
+	*models.PublicProject
+	FeedURL string
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string    `xml:"title"`
+	ID        string    `xml:"id"`
+	Link      atomLink  `xml:"link"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+	Summary   string    `xml:"summary"`
+	Content   *atomText `xml:"content,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Author  atomAuthor   `xml:"author"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+func getPublicProject(c echo.Context) (*models.PublicProject, error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	project, err := models.GetPublishedProjectByHash(s, c.Param("hash"))
+	if err != nil {
+		return nil, err
+	}
+
+	return models.GetPublicProject(s, project)
+}
+
+// publicProjectURL returns the absolute url of the public page of the project, as seen by the client.
+func publicProjectURL(c echo.Context) string {
+	path := strings.TrimSuffix(c.Request().URL.Path, "/")
+	path = strings.TrimSuffix(path, "/feed")
+	return c.Scheme() + "://" + c.Request().Host + path
+}
+
+// GetPublicProjectPage renders the public page of a published project
+// @Summary Get the public page of a project
+// @Description Returns a server-rendered, read-only html page with all tasks of a published project, grouped by kanban bucket. Does not need authentication.
+// @tags project
+// @Produce html
+// @Param hash path string true "The public hash of the project"
+// @Success 200 {string} string "The html page."
+// @Failure 404 {object} web.HTTPError "The project does not exist or is not published."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /public/projects/{hash} [get]
+func GetPublicProjectPage(c echo.Context) error {
+	pp, err := getPublicProject(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	buf := &bytes.Buffer{}
+	err = publicProjectTemplate.Execute(buf, &publicProjectPage{
+		PublicProject: pp,
+		FeedURL:       publicProjectURL(c) + "/feed",
+	})
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// GetPublicProjectFeed returns an atom feed with the latest task changes of a published project
+// @Summary Get the atom feed of a project
+// @Description Returns an atom feed with the most recently changed tasks of a published project. Does not need authentication.
+// @tags project
+// @Produce xml
+// @Param hash path string true "The public hash of the project"
+// @Success 200 {string} string "The atom feed."
+// @Failure 404 {object} web.HTTPError "The project does not exist or is not published."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /public/projects/{hash}/feed [get]
+func GetPublicProjectFeed(c echo.Context) error {
+	pp, err := getPublicProject(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	pageURL := publicProjectURL(c)
+	feed := &atomFeed{
+		Title:   pp.Project.Title,
+		ID:      pageURL,
+		Updated: pp.Project.Updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: pp.Project.Title},
+		Links: []atomLink{
+			{Href: pageURL + "/feed", Rel: "self", Type: "application/atom+xml"},
+			{Href: pageURL, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: []*atomEntry{},
+	}
+
+	updated := pp.Project.Updated
+	maxEntries := config.ServiceMaxItemsPerPage.GetInt()
+	for i, t := range pp.Tasks {
+		if i >= maxEntries {
+			break
+		}
+
+		if t.Updated.After(updated) {
+			updated = t.Updated
+		}
+
+		summary := "Open"
+		if t.Done {
+			summary = "Done"
+		}
+		if !t.Done && !t.DueDate.IsZero() {
+			summary += ", due " + t.DueDate.In(config.GetTimeZone()).Format("2006-01-02")
+		}
+
+		entry := &atomEntry{
+			Title:     t.Identifier + " " + t.Title,
+			ID:        pageURL + "#task-" + strconv.FormatInt(t.ID, 10),
+			Link:      atomLink{Href: pageURL + "#task-" + strconv.FormatInt(t.ID, 10), Rel: "alternate"},
+			Published: t.Created.Format(time.RFC3339),
+			Updated:   t.Updated.Format(time.RFC3339),
+			Summary:   summary,
+		}
+		if t.Description != "" {
+			entry.Content = &atomText{Type: "html", Body: t.Description}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.Blob(http.StatusOK, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), out...))
+}
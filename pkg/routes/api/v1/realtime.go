@@ -0,0 +1,258 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	auth2 "code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/realtime"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	eventStreamHeartbeat = 30 * time.Second
+	// How long the result of a rights check is reused for events of the same project
+	eventStreamRightsCacheTTL = time.Minute
+)
+
+type cachedProjectRight struct {
+	canRead bool
+	checked time.Time
+}
+
+// eventStreamFilter decides which events are sent to a client.
+type eventStreamFilter struct {
+	auth     web.Auth
+	userID   int64
+	projects map[int64]bool
+	rights   map[int64]*cachedProjectRight
+}
+
+func (f *eventStreamFilter) allows(event *realtime.Event) (bool, error) {
+	if event.UserID != 0 {
+		return event.UserID == f.userID, nil
+	}
+
+	if len(f.projects) > 0 && !f.projects[event.ProjectID] {
+		return false, nil
+	}
+
+	cached, has := f.rights[event.ProjectID]
+	if has && time.Since(cached.checked) < eventStreamRightsCacheTTL {
+		return cached.canRead, nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	can, _, err := (&models.Project{ID: event.ProjectID}).CanRead(s, f.auth)
+	if models.IsErrProjectDoesNotExist(err) {
+		can, err = false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	f.rights[event.ProjectID] = &cachedProjectRight{canRead: can, checked: time.Now()}
+	return can, nil
+}
+
+// restrictToProjects only allows events of the given projects. The user needs to be able to read all of them.
+func (f *eventStreamFilter) restrictToProjects(projects string) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	for _, raw := range strings.Split(projects, ",") {
+		projectID, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid project id.")
+		}
+
+		can, _, err := (&models.Project{ID: projectID}).CanRead(s, f.auth)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return echo.ErrForbidden
+		}
+
+		f.projects[projectID] = true
+		f.rights[projectID] = &cachedProjectRight{canRead: true, checked: time.Now()}
+	}
+
+	return nil
+}
+
+func writeStreamEvent(c echo.Context, event *realtime.Event) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Response(), "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Name, content)
+	if err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+// GetEventStream streams changes to the client as they happen
+// @Summary Stream changes as server-sent events
+// @Description Streams changes of tasks, buckets, comments and attachments in all projects the user can read, and new notifications of the user, as server-sent events. Each event has an id which can be passed as cursor (or via the Last-Event-ID header) after reconnecting to receive all events which were missed in the meantime. If some of them are not available anymore, a `stream.reset` event is sent first and the client should reload its data.
+// @tags service
+// @Produce text/event-stream
+// @Security JWTKeyAuth
+// @Param projects query string false "A comma separated list of project ids. If provided, only events of these projects are streamed."
+// @Param cursor query int false "The id of the last event the client received."
+// @Success 200 {object} realtime.Event "The stream of events."
+// @Failure 400 {object} web.HTTPError "Invalid project id or cursor."
+// @Failure 403 {object} web.HTTPError "The user does not have access to one of the projects."
+// @Failure 404 {object} web.HTTPError "Realtime events are not enabled."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /events [get]
+func GetEventStream(c echo.Context) error {
+	if !realtime.Enabled() {
+		return echo.ErrNotFound
+	}
+
+	a, err := auth2.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	filter := &eventStreamFilter{
+		auth:     a,
+		projects: make(map[int64]bool),
+		rights:   make(map[int64]*cachedProjectRight),
+	}
+	// Notifications are only for users, link shares don't have any
+	if u, is := a.(*user.User); is {
+		filter.userID = u.ID
+	}
+
+	if projects := c.QueryParam("projects"); projects != "" {
+		if err := filter.restrictToProjects(projects); err != nil {
+			if he, is := err.(*echo.HTTPError); is {
+				return he
+			}
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
+	cursorParam := c.QueryParam("cursor")
+	if cursorParam == "" {
+		cursorParam = c.Request().Header.Get("Last-Event-ID")
+	}
+	var cursor int64
+	if cursorParam != "" {
+		cursor, err = strconv.ParseInt(cursorParam, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor.")
+		}
+	}
+
+	// Subscribe before looking at the backlog so that no event is lost in between
+	sub := realtime.Subscribe()
+	defer sub.Unsubscribe()
+
+	var missed []*realtime.Event
+	complete := true
+	if cursor > 0 {
+		missed, complete, err = realtime.Since(cursor)
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	resp.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Prevents reverse proxies like nginx from buffering the stream
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	if !complete {
+		err = writeStreamEvent(c, &realtime.Event{Name: realtime.ResetEventName, Data: json.RawMessage("{}"), Created: time.Now()})
+		if err != nil {
+			return nil
+		}
+		// The cursor might be from before a restart and therefore larger than the ids of new events
+		cursor = 0
+	}
+
+	send := func(event *realtime.Event) error {
+		if event.ID <= cursor {
+			return nil
+		}
+		cursor = event.ID
+
+		allowed, err := filter.allows(event)
+		if err != nil {
+			log.Errorf("Could not check if realtime event %d can be sent: %s", event.ID, err)
+			return nil
+		}
+		if !allowed {
+			return nil
+		}
+		return writeStreamEvent(c, event)
+	}
+
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(resp, ": ping\n\n"); err != nil {
+				return nil
+			}
+			resp.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				// The client did not keep up and should reconnect with its last cursor
+				return nil
+			}
+			if err := send(event); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -50,6 +50,20 @@ type UserSettings struct {
 	OverdueTasksRemindersEnabled bool `json:"overdue_tasks_reminders_enabled"`
 	// The time when the daily summary of overdue tasks will be sent via email.
 	OverdueTasksRemindersTime string `json:"overdue_tasks_reminders_time" valid:"time,required"`
+	// If set to `daily` or `weekly`, mail notifications about tasks and projects are not sent immediately but
+	// collected and sent as one digest mail per day or week. Leave empty to get a mail for every notification.
+	DigestMode string `json:"digest_mode" valid:"in(daily|weekly)"`
+	// The time when the digest mail will be sent, 09:00 if empty. Weekly digests are sent on the first day of the
+	// user's week.
+	DigestTime string `json:"digest_time" valid:"time"`
+	// The start of the period during which reminders and overdue task notifications are held back, in the user's
+	// time zone. They are sent once the quiet hours end. Leave empty together with quiet_hours_end to disable.
+	QuietHoursStart string `json:"quiet_hours_start" valid:"time"`
+	// The end of the quiet hours. May be before the start if the quiet hours span midnight.
+	QuietHoursEnd string `json:"quiet_hours_end" valid:"time"`
+	// Reminders relative to the due, start or end date which are added to a task once it gets that date. Projects
+	// can define their own default reminders which are used instead.
+	DefaultReminders []*user2.DefaultReminder `json:"default_reminders"`
 	// If a task is created without a specified project this value should be used. Applies
 	// to tasks made directly in API and from clients.
 	DefaultProjectID int64 `json:"default_project_id"`
@@ -200,6 +214,11 @@ func UpdateGeneralUserSettings(c echo.Context) error {
 	user.Language = us.Language
 	user.Timezone = us.Timezone
 	user.OverdueTasksRemindersTime = us.OverdueTasksRemindersTime
+	user.DigestMode = us.DigestMode
+	user.DigestTime = us.DigestTime
+	user.QuietHoursStart = us.QuietHoursStart
+	user.QuietHoursEnd = us.QuietHoursEnd
+	user.DefaultReminders = us.DefaultReminders
 	user.FrontendSettings = us.FrontendSettings
 
 	_, err = user2.UpdateUser(s, user, true)
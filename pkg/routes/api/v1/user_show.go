@@ -76,6 +76,11 @@ func UserShow(c echo.Context) error {
 			Language:                     u.Language,
 			Timezone:                     u.Timezone,
 			OverdueTasksRemindersTime:    u.OverdueTasksRemindersTime,
+			DigestMode:                   u.DigestMode,
+			DigestTime:                   u.DigestTime,
+			QuietHoursStart:              u.QuietHoursStart,
+			QuietHoursEnd:                u.QuietHoursEnd,
+			DefaultReminders:             u.DefaultReminders,
 			FrontendSettings:             u.FrontendSettings,
 		},
 		DeletionScheduledAt: u.DeletionScheduledAt,
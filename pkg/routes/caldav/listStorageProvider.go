@@ -267,6 +267,11 @@ func (vcls *VikunjaCaldavProjectStorage) CreateResource(rpath, content string) (
 		return nil, err
 	}
 
+	// Checklist items are only exported, clients must not turn them into tasks
+	if caldav.IsChecklistItemUID(vTask.UID) {
+		return nil, errs.ForbiddenError
+	}
+
 	vTask.ProjectID = vcls.project.ID
 
 	// Check the rights
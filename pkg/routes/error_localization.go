@@ -0,0 +1,97 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"errors"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/i18n"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// localizeHTTPError translates the message of an error into the language of the request. Errors without a
+// translation in that language keep their english message.
+func localizeHTTPError(err error, c echo.Context) error {
+	var herr *echo.HTTPError
+	if !errors.As(err, &herr) {
+		return err
+	}
+
+	var httpErr web.HTTPError
+	switch m := herr.Message.(type) {
+	case web.HTTPError:
+		httpErr = m
+	case *web.HTTPError:
+		httpErr = *m
+	default:
+		return err
+	}
+
+	lang := getRequestLanguage(c)
+	key := "errors." + strconv.Itoa(httpErr.Code)
+	if lang == "" || !i18n.HasTranslation(lang, key) {
+		return err
+	}
+
+	httpErr.Message = i18n.T(lang, key)
+	return &echo.HTTPError{
+		Code:     herr.Code,
+		Message:  httpErr,
+		Internal: herr.Internal,
+	}
+}
+
+// getRequestLanguage returns the language of the authenticated user or, if they did not choose one, the best
+// match of the Accept-Language header.
+func getRequestLanguage(c echo.Context) string {
+	if userID := getRequestUserID(c); userID != 0 {
+		lang, err := (&user.User{ID: userID}).Lang()
+		if err == nil && i18n.ResolveLanguage(lang) != "" {
+			return lang
+		}
+	}
+
+	return i18n.MatchAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+}
+
+func getRequestUserID(c echo.Context) int64 {
+	if apiToken, is := c.Get("api_token").(*models.APIToken); is {
+		return apiToken.OwnerID
+	}
+
+	token, is := c.Get("user").(*jwt.Token)
+	if !is {
+		return 0
+	}
+	claims, is := token.Claims.(jwt.MapClaims)
+	if !is {
+		return 0
+	}
+	typ, is := claims["type"].(float64)
+	if !is || int(typ) != auth.AuthTypeUser {
+		return 0
+	}
+	id, _ := claims["id"].(float64)
+	return int64(id)
+}
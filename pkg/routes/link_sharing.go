@@ -0,0 +1,68 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// checkLinkShareIsUsable rejects all requests authenticated with a link share jwt once the link share
+// was deleted, disabled or has expired, even if the jwt itself is still valid.
+func checkLinkShareIsUsable(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		jwtinf, is := c.Get("user").(*jwt.Token)
+		if !is {
+			return next(c)
+		}
+
+		claims, is := jwtinf.Claims.(jwt.MapClaims)
+		if !is {
+			return next(c)
+		}
+
+		typ, is := claims["type"].(float64)
+		if !is || int(typ) != auth.AuthTypeLinkShare {
+			return next(c)
+		}
+
+		share, err := models.GetLinkShareFromClaims(claims)
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err = models.GetLinkShareByID(s, share.ID)
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+
+		err = share.CheckIsUsable()
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+
+		return next(c)
+	}
+}
@@ -114,6 +114,10 @@ func NewEcho() *echo.Echo {
 	// panic recover
 	e.Use(middleware.Recover())
 
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		e.DefaultHTTPErrorHandler(localizeHTTPError(err, c), c)
+	}
+
 	if config.ServiceSentryDsn.GetString() != "" {
 		if err := sentry.Init(sentry.ClientOptions{
 			Dsn:              config.ServiceSentryDsn.GetString(),
@@ -144,7 +148,7 @@ func NewEcho() *echo.Echo {
 				}
 				log.Debugf("Error '%s' sent to sentry", err.Error())
 			}
-			e.DefaultHTTPErrorHandler(err, c)
+			e.DefaultHTTPErrorHandler(localizeHTTPError(err, c), c)
 		}
 	}
 
@@ -280,8 +284,15 @@ func registerAPIRoutes(a *echo.Group) {
 		ur.POST("/shares/:share/auth", apiv1.AuthenticateLinkShare)
 	}
 
+	// Public project pages
+	if config.ServiceEnablePublicProjects.GetBool() {
+		n.GET("/public/projects/:hash", apiv1.GetPublicProjectPage)
+		n.GET("/public/projects/:hash/feed", apiv1.GetPublicProjectFeed)
+	}
+
 	// ===== Routes with Authentication =====
 	a.Use(SetupTokenMiddleware())
+	a.Use(checkLinkShareIsUsable)
 
 	// Rate limit
 	setupRateLimit(a, config.RateLimitKind.GetString())
@@ -338,6 +349,17 @@ func registerAPIRoutes(a *echo.Group) {
 	a.PUT("/projects", projectHandler.CreateWeb)
 	a.GET("/projects/:project/projectusers", apiv1.ListUsersForProject)
 
+	if config.InboundEnabled.GetBool() {
+		projectInboundEmailHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.ProjectInboundEmail{}
+			},
+		}
+		a.GET("/projects/:project/inboundemail", projectInboundEmailHandler.ReadOneWeb)
+		a.PUT("/projects/:project/inboundemail", projectInboundEmailHandler.CreateWeb)
+		a.DELETE("/projects/:project/inboundemail", projectInboundEmailHandler.DeleteWeb)
+	}
+
 	if config.ServiceEnableLinkSharing.GetBool() {
 		projectSharingHandler := &handler.WebHandler{
 			EmptyStruct: func() handler.CObject {
@@ -347,7 +369,15 @@ func registerAPIRoutes(a *echo.Group) {
 		a.PUT("/projects/:project/shares", projectSharingHandler.CreateWeb)
 		a.GET("/projects/:project/shares", projectSharingHandler.ReadAllWeb)
 		a.GET("/projects/:project/shares/:share", projectSharingHandler.ReadOneWeb)
+		a.POST("/projects/:project/shares/:share", projectSharingHandler.UpdateWeb)
 		a.DELETE("/projects/:project/shares/:share", projectSharingHandler.DeleteWeb)
+
+		linkShareAccessHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.LinkShareAccess{}
+			},
+		}
+		a.GET("/projects/:project/shares/:share/log", linkShareAccessHandler.ReadAllWeb)
 	}
 
 	taskCollectionHandler := &handler.WebHandler{
@@ -385,6 +415,36 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/tasks/:projecttask", taskHandler.DeleteWeb)
 	a.POST("/tasks/:projecttask", taskHandler.UpdateWeb)
 
+	taskDuplicateHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskDuplicate{}
+		},
+	}
+	a.PUT("/tasks/:projecttask/duplicate", taskDuplicateHandler.CreateWeb)
+
+	taskUserHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskUser{}
+		},
+	}
+	a.GET("/tasks/:projecttask/users", taskUserHandler.ReadAllWeb)
+	a.PUT("/tasks/:projecttask/users", taskUserHandler.CreateWeb)
+	a.DELETE("/tasks/:projecttask/users/:user", taskUserHandler.DeleteWeb)
+	a.POST("/tasks/:projecttask/users/:user", taskUserHandler.UpdateWeb)
+
+	if config.ServiceEnableLinkSharing.GetBool() {
+		taskSharingHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.LinkSharing{}
+			},
+		}
+		a.PUT("/tasks/:projecttask/shares", taskSharingHandler.CreateWeb)
+		a.GET("/tasks/:projecttask/shares", taskSharingHandler.ReadAllWeb)
+		a.GET("/tasks/:projecttask/shares/:share", taskSharingHandler.ReadOneWeb)
+		a.POST("/tasks/:projecttask/shares/:share", taskSharingHandler.UpdateWeb)
+		a.DELETE("/tasks/:projecttask/shares/:share", taskSharingHandler.DeleteWeb)
+	}
+
 	bulkTaskHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.BulkTask{}
@@ -392,6 +452,13 @@ func registerAPIRoutes(a *echo.Group) {
 	}
 	a.POST("/tasks/bulk", bulkTaskHandler.UpdateWeb)
 
+	bulkTaskOperationHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.BulkTaskOperation{}
+		},
+	}
+	a.POST("/tasks/bulk/operations", bulkTaskOperationHandler.CreateWeb)
+
 	assigneeTaskHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TaskAssginee{}
@@ -424,6 +491,20 @@ func registerAPIRoutes(a *echo.Group) {
 	}
 	a.POST("/tasks/:projecttask/labels/bulk", bulkLabelTaskHandler.CreateWeb)
 
+	taskSchedulePreviewHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskSchedulePreview{}
+		},
+	}
+	a.POST("/tasks/:projecttask/schedule/preview", taskSchedulePreviewHandler.CreateWeb)
+
+	projectCriticalPathHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ProjectCriticalPath{}
+		},
+	}
+	a.GET("/projects/:project/criticalpath", projectCriticalPathHandler.ReadOneWeb)
+
 	taskRelationHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TaskRelation{}
@@ -432,6 +513,30 @@ func registerAPIRoutes(a *echo.Group) {
 	a.PUT("/tasks/:task/relations", taskRelationHandler.CreateWeb)
 	a.DELETE("/tasks/:task/relations/:relationKind/:otherTask", taskRelationHandler.DeleteWeb)
 
+	taskChecklistItemHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskChecklistItem{}
+		},
+	}
+	a.GET("/tasks/:task/checklist", taskChecklistItemHandler.ReadAllWeb)
+	a.PUT("/tasks/:task/checklist", taskChecklistItemHandler.CreateWeb)
+	a.POST("/tasks/:task/checklist/:checklistitem", taskChecklistItemHandler.UpdateWeb)
+	a.DELETE("/tasks/:task/checklist/:checklistitem", taskChecklistItemHandler.DeleteWeb)
+
+	taskChecklistOrderHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskChecklistOrder{}
+		},
+	}
+	a.POST("/tasks/:task/checklist/reorder", taskChecklistOrderHandler.CreateWeb)
+
+	taskChecklistItemConversionHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskChecklistItemConversion{}
+		},
+	}
+	a.PUT("/tasks/:task/checklist/:checklistitem/convert", taskChecklistItemConversionHandler.CreateWeb)
+
 	if config.ServiceEnableTaskAttachments.GetBool() {
 		taskAttachmentHandler := &handler.WebHandler{
 			EmptyStruct: func() handler.CObject {
@@ -498,6 +603,93 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/filters/:filter", savedFiltersHandler.DeleteWeb)
 	a.POST("/filters/:filter", savedFiltersHandler.UpdateWeb)
 
+	projectTemplateHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ProjectTemplate{}
+		},
+	}
+	a.GET("/templates/projects", projectTemplateHandler.ReadAllWeb)
+	a.PUT("/templates/projects", projectTemplateHandler.CreateWeb)
+	a.GET("/templates/projects/:template", projectTemplateHandler.ReadOneWeb)
+	a.POST("/templates/projects/:template", projectTemplateHandler.UpdateWeb)
+	a.DELETE("/templates/projects/:template", projectTemplateHandler.DeleteWeb)
+
+	projectTemplateInstantiationHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ProjectTemplateInstantiation{}
+		},
+	}
+	a.PUT("/templates/projects/:template/instantiate", projectTemplateInstantiationHandler.CreateWeb)
+
+	taskTemplateHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTemplate{}
+		},
+	}
+	a.GET("/templates/tasks", taskTemplateHandler.ReadAllWeb)
+	a.PUT("/templates/tasks", taskTemplateHandler.CreateWeb)
+	a.GET("/templates/tasks/:template", taskTemplateHandler.ReadOneWeb)
+	a.POST("/templates/tasks/:template", taskTemplateHandler.UpdateWeb)
+	a.DELETE("/templates/tasks/:template", taskTemplateHandler.DeleteWeb)
+
+	trashHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TrashedItem{}
+		},
+	}
+	a.GET("/trash", trashHandler.ReadAllWeb)
+	a.DELETE("/trash/:trashitem", trashHandler.DeleteWeb)
+
+	trashRestoreHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TrashedItemRestore{}
+		},
+	}
+	a.POST("/trash/:trashitem/restore", trashRestoreHandler.CreateWeb)
+
+	milestoneHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.Milestone{}
+		},
+	}
+	a.GET("/milestones", milestoneHandler.ReadAllWeb)
+	a.PUT("/milestones", milestoneHandler.CreateWeb)
+	a.GET("/milestones/:milestone", milestoneHandler.ReadOneWeb)
+	a.POST("/milestones/:milestone", milestoneHandler.UpdateWeb)
+	a.DELETE("/milestones/:milestone", milestoneHandler.DeleteWeb)
+
+	milestoneBurndownHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.MilestoneBurndown{}
+		},
+	}
+	a.GET("/milestones/:milestone/burndown", milestoneBurndownHandler.ReadOneWeb)
+
+	sprintHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.Sprint{}
+		},
+	}
+	a.GET("/projects/:project/sprints", sprintHandler.ReadAllWeb)
+	a.PUT("/projects/:project/sprints", sprintHandler.CreateWeb)
+	a.GET("/projects/:project/sprints/:sprint", sprintHandler.ReadOneWeb)
+	a.POST("/projects/:project/sprints/:sprint", sprintHandler.UpdateWeb)
+	a.DELETE("/projects/:project/sprints/:sprint", sprintHandler.DeleteWeb)
+
+	sprintCloseHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.SprintClose{}
+		},
+	}
+	a.POST("/projects/:project/sprints/:sprint/close", sprintCloseHandler.CreateWeb)
+
+	sprintReportHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.SprintReport{}
+		},
+	}
+	a.GET("/projects/:project/sprints/:sprint/report", sprintReportHandler.ReadOneWeb)
+
 	teamHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.Team{}
@@ -536,6 +728,34 @@ func registerAPIRoutes(a *echo.Group) {
 	a.GET("/notifications", notificationHandler.ReadAllWeb)
 	a.POST("/notifications/:notificationid", notificationHandler.UpdateWeb)
 
+	reminderSnoozeHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ReminderSnooze{}
+		},
+	}
+	a.POST("/notifications/:notificationid/snooze", reminderSnoozeHandler.CreateWeb)
+
+	notificationPreferenceHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.NotificationPreferences{}
+		},
+	}
+	a.GET("/user/settings/notifications", notificationPreferenceHandler.ReadAllWeb)
+	a.PUT("/user/settings/notifications", notificationPreferenceHandler.CreateWeb)
+	a.DELETE("/user/settings/notifications/:preference", notificationPreferenceHandler.DeleteWeb)
+
+	pushTargetHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.PushTargets{}
+		},
+	}
+	a.GET("/user/settings/notifications/push", pushTargetHandler.ReadAllWeb)
+	a.PUT("/user/settings/notifications/push", pushTargetHandler.CreateWeb)
+	a.DELETE("/user/settings/notifications/push/:pushtarget", pushTargetHandler.DeleteWeb)
+
+	// Realtime
+	a.GET("/events", apiv1.GetEventStream)
+
 	// Migrations
 	m := a.Group("/migration")
 	registerMigrations(m)
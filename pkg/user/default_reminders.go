@@ -0,0 +1,37 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+// DefaultReminder is a reminder relative to a date of a task which is added automatically once a task gets that date.
+type DefaultReminder struct {
+	// A period in seconds relative to the date. Negative values mean the reminder triggers before the date.
+	RelativePeriod int64 `json:"relative_period"`
+	// The name of the date field to which the relative period refers to. Can be due_date, start_date or end_date.
+	RelativeTo string `json:"relative_to"`
+}
+
+// ValidateDefaultReminders checks if all default reminders refer to a date of a task.
+func ValidateDefaultReminders(reminders []*DefaultReminder) error {
+	for _, r := range reminders {
+		switch r.RelativeTo {
+		case "due_date", "start_date", "end_date":
+		default:
+			return &ErrInvalidDefaultReminder{RelativeTo: r.RelativeTo}
+		}
+	}
+	return nil
+}
@@ -506,3 +506,55 @@ func (err *ErrUsernameMustNotContainSpaces) HTTPError() web.HTTPError {
 		Message:  "The username must not contain spaces.",
 	}
 }
+
+// ErrInvalidQuietHours represents a "InvalidQuietHours" kind of error.
+type ErrInvalidQuietHours struct{}
+
+// IsErrInvalidQuietHours checks if an error is a ErrInvalidQuietHours.
+func IsErrInvalidQuietHours(err error) bool {
+	_, ok := err.(*ErrInvalidQuietHours)
+	return ok
+}
+
+func (err *ErrInvalidQuietHours) Error() string {
+	return "quiet hours need a start and an end"
+}
+
+// ErrCodeInvalidQuietHours holds the unique world-error code of this error
+const ErrCodeInvalidQuietHours = 1023
+
+// HTTPError holds the http error description
+func (err *ErrInvalidQuietHours) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidQuietHours,
+		Message:  "Quiet hours need both a start and an end time.",
+	}
+}
+
+// ErrInvalidDefaultReminder represents a "InvalidDefaultReminder" kind of error.
+type ErrInvalidDefaultReminder struct {
+	RelativeTo string
+}
+
+// IsErrInvalidDefaultReminder checks if an error is a ErrInvalidDefaultReminder.
+func IsErrInvalidDefaultReminder(err error) bool {
+	_, ok := err.(*ErrInvalidDefaultReminder)
+	return ok
+}
+
+func (err *ErrInvalidDefaultReminder) Error() string {
+	return fmt.Sprintf("default reminder is not relative to a task date [RelativeTo: %s]", err.RelativeTo)
+}
+
+// ErrCodeInvalidDefaultReminder holds the unique world-error code of this error
+const ErrCodeInvalidDefaultReminder = 1024
+
+// HTTPError holds the http error description
+func (err *ErrInvalidDefaultReminder) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidDefaultReminder,
+		Message:  "A default reminder must be relative to the due, start or end date of a task.",
+	}
+}
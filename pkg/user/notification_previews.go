@@ -0,0 +1,42 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/notifications"
+)
+
+// GetNotificationPreviews returns all notifications of this package filled with sample data.
+// They are used to preview how notification mails look like with the configured templates.
+func GetNotificationPreviews() map[string]notifications.Notification {
+	u := &User{ID: 1, Username: "alice", Name: "Alice", Email: "alice@example.com", Timezone: config.GetTimeZone().String()}
+	token := &Token{UserID: u.ID, Token: "preview-token", ClearTextToken: "preview-token", Kind: TokenPasswordReset}
+
+	return map[string]notifications.Notification{
+		"user.email.confirm":                          &EmailConfirmNotification{User: u, ConfirmToken: token.Token},
+		"user.email.confirm.new":                      &EmailConfirmNotification{User: u, IsNew: true, ConfirmToken: token.Token},
+		"user.password.changed":                       &PasswordChangedNotification{User: u},
+		"user.password.reset":                         &ResetPasswordNotification{User: u, Token: token},
+		"totp.invalid":                                &InvalidTOTPNotification{User: u},
+		"password.account.locked.after.invalid.totop": &PasswordAccountLockedAfterInvalidTOTOPNotification{User: u},
+		"failed.login.attempt":                        &FailedLoginAttemptNotification{User: u},
+		"user.deletion.confirm":                       &AccountDeletionConfirmNotification{User: u, ConfirmToken: token.Token},
+		"user.deletion":                               &AccountDeletionNotification{User: u, NotificationNumber: 2},
+		"user.deleted":                                &AccountDeletedNotification{User: u},
+	}
+}
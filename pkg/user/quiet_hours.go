@@ -0,0 +1,85 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+)
+
+// HasQuietHours returns true if the user has configured a period during which they don't want to be notified
+// about reminders and overdue tasks.
+func (u *User) HasQuietHours() bool {
+	return u.QuietHoursStart != "" && u.QuietHoursEnd != "" && u.QuietHoursStart != u.QuietHoursEnd
+}
+
+// GetTimezone returns the time zone of the user or the default one of this instance if the user did not set one.
+func (u *User) GetTimezone() (*time.Location, error) {
+	if u.Timezone == "" {
+		return config.GetTimeZone(), nil
+	}
+	return time.LoadLocation(u.Timezone)
+}
+
+// GetQuietHoursEnd checks if t is in the quiet hours of the user, computed in their time zone. If it is, the
+// time when the quiet hours end is returned. Quiet hours may span midnight, for example from 22:00 to 07:00.
+func (u *User) GetQuietHoursEnd(t time.Time) (end time.Time, inQuietHours bool, err error) {
+	if !u.HasQuietHours() {
+		return t, false, nil
+	}
+
+	tz, err := u.GetTimezone()
+	if err != nil {
+		return t, false, err
+	}
+
+	start, err := time.Parse("15:04", u.QuietHoursStart)
+	if err != nil {
+		return t, false, err
+	}
+	stop, err := time.Parse("15:04", u.QuietHoursEnd)
+	if err != nil {
+		return t, false, err
+	}
+
+	local := t.In(tz)
+	startMinutes := start.Hour()*60 + start.Minute()
+	stopMinutes := stop.Hour()*60 + stop.Minute()
+	minutes := local.Hour()*60 + local.Minute()
+
+	endOn := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, stop.Hour(), stop.Minute(), 0, 0, tz)
+	}
+
+	if startMinutes < stopMinutes {
+		if minutes >= startMinutes && minutes < stopMinutes {
+			return endOn(0), true, nil
+		}
+		return t, false, nil
+	}
+
+	// The quiet hours span midnight
+	if minutes >= startMinutes {
+		return endOn(1), true, nil
+	}
+	if minutes < stopMinutes {
+		return endOn(0), true, nil
+	}
+
+	return t, false, nil
+}
@@ -0,0 +1,108 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser_GetQuietHoursEnd(t *testing.T) {
+	tz, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		start        string
+		end          string
+		now          time.Time
+		wantQuiet    bool
+		wantQuietEnd time.Time
+	}{
+		{
+			name:  "no quiet hours",
+			now:   time.Date(2023, 10, 1, 23, 0, 0, 0, tz),
+			start: "",
+			end:   "",
+		},
+		{
+			name:         "during the day",
+			start:        "12:00",
+			end:          "14:00",
+			now:          time.Date(2023, 10, 1, 13, 0, 0, 0, tz),
+			wantQuiet:    true,
+			wantQuietEnd: time.Date(2023, 10, 1, 14, 0, 0, 0, tz),
+		},
+		{
+			name:  "after quiet hours during the day",
+			start: "12:00",
+			end:   "14:00",
+			now:   time.Date(2023, 10, 1, 14, 0, 0, 0, tz),
+		},
+		{
+			name:         "over midnight before midnight",
+			start:        "22:00",
+			end:          "07:00",
+			now:          time.Date(2023, 10, 1, 23, 30, 0, 0, tz),
+			wantQuiet:    true,
+			wantQuietEnd: time.Date(2023, 10, 2, 7, 0, 0, 0, tz),
+		},
+		{
+			name:         "over midnight after midnight",
+			start:        "22:00",
+			end:          "07:00",
+			now:          time.Date(2023, 10, 2, 3, 0, 0, 0, tz),
+			wantQuiet:    true,
+			wantQuietEnd: time.Date(2023, 10, 2, 7, 0, 0, 0, tz),
+		},
+		{
+			name:  "outside of quiet hours over midnight",
+			start: "22:00",
+			end:   "07:00",
+			now:   time.Date(2023, 10, 2, 12, 0, 0, 0, tz),
+		},
+		{
+			name:         "computed in the user's time zone",
+			start:        "22:00",
+			end:          "07:00",
+			now:          time.Date(2023, 10, 1, 21, 30, 0, 0, time.UTC),
+			wantQuiet:    true,
+			wantQuietEnd: time.Date(2023, 10, 2, 7, 0, 0, 0, tz),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &User{
+				Timezone:        "Europe/Berlin",
+				QuietHoursStart: tt.start,
+				QuietHoursEnd:   tt.end,
+			}
+
+			end, quiet, err := u.GetQuietHoursEnd(tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantQuiet, quiet)
+			if tt.wantQuiet {
+				assert.True(t, tt.wantQuietEnd.Equal(end), "expected %s, got %s", tt.wantQuietEnd, end)
+			} else {
+				assert.True(t, tt.now.Equal(end))
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/modules/keyvalue"
+	"code.vikunja.io/api/pkg/notifications"
 )
 
 // InitTests handles the actual bootstrapping of the test env
@@ -35,6 +36,11 @@ func InitTests() {
 		log.Fatal(err)
 	}
 
+	err = x.Sync2(notifications.GetTables()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = db.InitTestFixtures("users", "user_tokens")
 	if err != nil {
 		log.Fatal(err)
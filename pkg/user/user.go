@@ -70,6 +70,17 @@ const (
 	StatusDisabled
 )
 
+// The digest modes a user can choose. If a user has a digest mode set, mail notifications are batched and sent
+// as one summary mail per day or week instead of one mail per notification.
+const (
+	DigestModeNone   = ""
+	DigestModeDaily  = "daily"
+	DigestModeWeekly = "weekly"
+)
+
+// DefaultDigestTime is the time digests are sent at for users who did not choose one.
+const DefaultDigestTime = "09:00"
+
 // User holds information about an user
 type User struct {
 	// The unique, numeric id of this user.
@@ -96,6 +107,10 @@ type User struct {
 	DiscoverableByEmail          bool   `xorm:"bool default false index" json:"-"`
 	OverdueTasksRemindersEnabled bool   `xorm:"bool default true index" json:"-"`
 	OverdueTasksRemindersTime    string `xorm:"varchar(5) not null default '09:00'" json:"-"`
+	DigestMode                   string `xorm:"varchar(10) null" json:"-"`
+	DigestTime                   string `xorm:"varchar(5) null" json:"-"`
+	QuietHoursStart              string `xorm:"varchar(5) null" json:"-"`
+	QuietHoursEnd                string `xorm:"varchar(5) null" json:"-"`
 	DefaultProjectID             int64  `xorm:"bigint null index" json:"-"`
 	WeekStart                    int    `xorm:"null" json:"-"`
 	Language                     string `xorm:"varchar(50) null" json:"-"`
@@ -106,6 +121,8 @@ type User struct {
 
 	FrontendSettings interface{} `xorm:"json null" json:"-"`
 
+	DefaultReminders []*DefaultReminder `xorm:"json null" json:"-"`
+
 	ExportFileID int64 `xorm:"bigint null" json:"-"`
 
 	// A timestamp when this task was created. You cannot change this value.
@@ -148,6 +165,34 @@ func (u *User) ShouldNotify() (bool, error) {
 	return user.Status != StatusDisabled, err
 }
 
+// WantsDigest returns whether mail notifications for this user should be batched into a digest mail
+func (u *User) WantsDigest() (bool, error) {
+	s := db.NewSession()
+	defer s.Close()
+	user, err := getUser(s, &User{ID: u.ID}, false)
+	if err != nil {
+		return false, err
+	}
+
+	return user.DigestMode != DigestModeNone, nil
+}
+
+// Lang returns the language the user wants to receive notifications in
+func (u *User) Lang() (string, error) {
+	if u.Language != "" || u.ID == 0 {
+		return u.Language, nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+	user, err := getUser(s, &User{ID: u.ID}, false)
+	if err != nil {
+		return "", err
+	}
+
+	return user.Language, nil
+}
+
 // GetID implements the Auth interface
 func (u *User) GetID() int64 {
 	return u.ID
@@ -498,6 +543,23 @@ func UpdateUser(s *xorm.Session, user *User, forceOverride bool) (updatedUser *U
 		return
 	}
 
+	if (user.QuietHoursStart == "") != (user.QuietHoursEnd == "") {
+		return &User{}, &ErrInvalidQuietHours{}
+	}
+
+	err = ValidateDefaultReminders(user.DefaultReminders)
+	if err != nil {
+		return &User{}, err
+	}
+
+	// Pending digest entries would never be sent once the digest is disabled
+	if user.DigestMode == DigestModeNone {
+		_, err = s.Where("notifiable_id = ?", user.ID).Delete(&notifications.DigestEntry{})
+		if err != nil {
+			return &User{}, err
+		}
+	}
+
 	frontendSettingsJSON, err := json.Marshal(user.FrontendSettings)
 	if err != nil {
 		return nil, err
@@ -523,7 +585,12 @@ func UpdateUser(s *xorm.Session, user *User, forceOverride bool) (updatedUser *U
 			"language",
 			"timezone",
 			"overdue_tasks_reminders_time",
+			"digest_mode",
+			"digest_time",
+			"quiet_hours_start",
+			"quiet_hours_end",
 			"frontend_settings",
+			"default_reminders",
 		).
 		Update(user)
 	if err != nil {